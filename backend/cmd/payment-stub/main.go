@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/payment"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/config"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// PAYMENT_PROVIDER=http で呼び出すローカルの決済API
+// 承認率・処理時間・拒否理由は APIサーバーの擬似決済と同じ PAYMENT_* 環境変数で設定する
+func main() {
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+
+	gateway := payment.NewFakeGateway(payment.FakeConfig{
+		SuccessRate:  cfg.Payment.SuccessRate,
+		LatencyMin:   cfg.Payment.LatencyMin,
+		LatencyMax:   cfg.Payment.LatencyMax,
+		DeclineCodes: cfg.Payment.DeclineCodes,
+		Retention:    cfg.Payment.Retention,
+	}, utils.NewSeededRand(cfg.Performance.RandomSeed))
+
+	port := os.Getenv("PAYMENT_STUB_PORT")
+	if port == "" {
+		port = "8090"
+	}
+	server := &http.Server{
+		Addr:        cfg.Server.Host + ":" + port,
+		Handler:     payment.NewStubHandler(gateway),
+		ReadTimeout: 15 * time.Second,
+		IdleTimeout: 60 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Starting payment stub on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start payment stub: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatalf("Payment stub forced to shutdown: %v", err)
+	}
+	log.Println("Payment stub exited")
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/notification"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/payment"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/persistence/memory"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/persistence/traced"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/config"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/slo"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+func main() {
+	// 設定読み込み
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+
+	// リポジトリ初期化（呼び出しごとに OpenTelemetry の子スパンを作成）
+	var (
+		orderStore                            = memory.NewOrderRepository()
+		cartRepo   repository.CartRepository  = traced.NewCartRepository(memory.NewCartRepository())
+		orderRepo  repository.OrderRepository = traced.NewOrderRepository(orderStore)
+	)
+
+	// テレメトリ初期化（TELEMETRY_BACKEND で送信先を選択し、Prometheus にも同じビジネスメトリクスを記録）
+	backend, err := monitoring.NewTelemetry(cfg.Telemetry)
+	if err != nil {
+		log.Fatalf("Failed to initialize telemetry: %v", err)
+	}
+	// スクレイプごとにスパンが作成されないよう、トレースしないリポジトリを参照する
+	prometheusExporter := monitoring.NewPrometheusExporter(orderStore)
+	telemetry := monitoring.NewMultiTelemetry(backend, prometheusExporter)
+
+	// 在庫数が閾値をまたいだら LowStock / OutOfStock / Restocked を通知（テレメトリと STOCK_EVENT_SINKS）
+	stockSinks := []usecase.StockEventSink{
+		usecase.StockEventSinkFunc(func(ctx context.Context, event entity.StockEvent) error {
+			monitoring.RecordStockEvent(ctx, telemetry, event)
+			return nil
+		}),
+	}
+	for _, sink := range cfg.Inventory.EventSinks {
+		switch sink {
+		case "log":
+			stockSinks = append(stockSinks, notification.NewLogSink())
+		case "webhook":
+			stockSinks = append(stockSinks, notification.NewWebhookSink(cfg.Inventory.WebhookURL))
+		}
+	}
+	var productRepo repository.ProductRepository = traced.NewProductRepository(
+		usecase.NewStockNotifier(memory.NewProductRepository(), cfg.Inventory.LowStockThreshold, stockSinks...))
+
+	// 乱数源（遅延・エラー・擬似決済の結果で共有し、RANDOM_SEED で再現可能にする）
+	random := utils.NewSeededRand(cfg.Performance.RandomSeed)
+
+	// 障害注入設定（ルート単位のプロファイル、管理APIで実行中に変更可能）
+	faultProfiles := fault.DefaultProfiles(fault.Settings{
+		ErrorRate:        cfg.Performance.ErrorRate,
+		ResponseTimeMin:  cfg.Performance.ResponseTimeMin,
+		ResponseTimeMax:  cfg.Performance.ResponseTimeMax,
+		SlowEndpointRate: cfg.Performance.SlowEndpointRate,
+	})
+	if cfg.Performance.FaultProfilesFile != "" {
+		faultProfiles, err = fault.LoadProfiles(cfg.Performance.FaultProfilesFile)
+		if err != nil {
+			log.Fatalf("Failed to load fault profiles: %v", err)
+		}
+	}
+	faults := fault.NewConfig(faultProfiles, random)
+
+	// 障害シナリオ（インシデントのタイムライン）
+	scenarios := fault.NewScenarioRunner(faults, func(transition fault.Transition) {
+		log.Printf("Fault scenario %q: step %d/%d %q applied (recovery=%t)",
+			transition.Scenario, transition.Index+1, transition.Total, transition.Step, transition.Recovery)
+		monitoring.RecordFaultScenarioTransition(context.Background(), telemetry, transition)
+	})
+	if cfg.Performance.FaultScenarioFile != "" {
+		scenario, err := fault.LoadScenario(cfg.Performance.FaultScenarioFile)
+		if err != nil {
+			log.Fatalf("Failed to load fault scenario: %v", err)
+		}
+		if err := scenarios.Start(scenario); err != nil {
+			log.Fatalf("Failed to start fault scenario: %v", err)
+		}
+		log.Printf("Fault scenario %q started", scenario.Name)
+	}
+
+	// SLI/SLO評価（プロセス内で計測し、オフラインでもエラーバジェットを確認可能にする）
+	objectives := slo.DefaultObjectives()
+	if cfg.SLO.ObjectivesFile != "" {
+		objectives, err = slo.LoadObjectives(cfg.SLO.ObjectivesFile)
+		if err != nil {
+			log.Fatalf("Failed to load service level objectives: %v", err)
+		}
+	}
+	sliRecorder := slo.NewRecorder(objectives)
+	sloEvaluator := slo.NewEvaluator(objectives, sliRecorder)
+
+	// バーンレートアラート（New Relic のアラートポリシーなしで発報状況を確認）
+	alertRules := slo.DefaultAlertRules(objectives)
+	if cfg.SLO.AlertRulesFile != "" {
+		alertRules, err = slo.LoadAlertRules(cfg.SLO.AlertRulesFile)
+		if err != nil {
+			log.Fatalf("Failed to load alert rules: %v", err)
+		}
+	}
+	if err := alertRules.Validate(objectives); err != nil {
+		log.Fatalf("Invalid alert rules: %v", err)
+	}
+	alertSinks := []slo.AlertSink{
+		slo.AlertSinkFunc(func(ctx context.Context, alert slo.Alert) error {
+			monitoring.RecordSLOAlert(ctx, telemetry, alert)
+			return nil
+		}),
+	}
+	if cfg.SLO.AlertWebhookURL != "" {
+		alertSinks = append(alertSinks, slo.NewWebhookSink(cfg.SLO.AlertWebhookURL))
+	}
+	sloAlerter := slo.NewAlerter(sloEvaluator, alertRules, alertSinks...)
+	sloAlerter.Start(cfg.SLO.AlertInterval)
+
+	// 在庫管理（INVENTORY_MODE）
+	inventory, err := usecase.NewInventory(cfg.Inventory.Mode, productRepo)
+	if err != nil {
+		log.Fatalf("Failed to initialize inventory: %v", err)
+	}
+
+	// 決済ゲートウェイ（PAYMENT_PROVIDER）
+	var paymentGateway usecase.PaymentGateway
+	switch cfg.Payment.Provider {
+	case "http":
+		paymentGateway = payment.NewHTTPGateway(cfg.Payment.GatewayURL, cfg.Payment.Timeout)
+	default:
+		paymentGateway = payment.NewFakeGateway(payment.FakeConfig{
+			SuccessRate:  cfg.Payment.SuccessRate,
+			LatencyMin:   cfg.Payment.LatencyMin,
+			LatencyMax:   cfg.Payment.LatencyMax,
+			DeclineCodes: cfg.Payment.DeclineCodes,
+			Retention:    cfg.Payment.Retention,
+		}, random)
+	}
+
+	// 決済キュー（PAYMENT_WORKERS 件ずつ決済し、ジョブごとにバックグラウンドトランザクションを記録）
+	// 一時的な障害は PAYMENT_RETRY_* に従って再試行し、再試行を使い切った注文はデッドレターに移す
+	// 決済結果を反映する注文の保存に失敗した場合も同じ PAYMENT_RETRY_* に従ってその場で再試行する
+	paymentRetry := usecase.RetryPolicy{
+		MaxAttempts:    cfg.Payment.RetryMaxAttempts,
+		InitialBackoff: cfg.Payment.RetryInitialBackoff,
+		MaxBackoff:     cfg.Payment.RetryMaxBackoff,
+		Jitter:         cfg.Payment.RetryJitter,
+	}
+	paymentQueue := usecase.NewPaymentQueue(cfg.Payment.Workers, cfg.Payment.QueueSize, paymentRetry, random, monitoring.NewPaymentQueueMonitor(telemetry, prometheusExporter))
+
+	// ユースケース初期化
+	var (
+		productUseCase = usecase.NewProductUseCase(productRepo)
+		cartUseCase    = usecase.NewCartUseCase(cartRepo, productRepo, inventory)
+		orderUseCase   = usecase.NewOrderUseCase(orderRepo, cartRepo, productRepo, inventory, paymentGateway, paymentQueue, usecase.NewRetryer(paymentRetry, random), func(ctx context.Context, outcome usecase.PaymentOutcome) {
+			monitoring.RecordPayment(ctx, telemetry, outcome.Order, outcome.Success, outcome.Duration)
+		})
+	)
+
+	paymentQueue.Start(orderUseCase.ProcessPayment)
+
+	// 期限切れカートの削除（商品が入ったカートは AbandonedCart イベントとして記録）
+	cartSweeper := usecase.NewCartSweeper(cartRepo, cfg.Cart.TTL, func(ctx context.Context, abandoned usecase.AbandonedCart) {
+		monitoring.RecordAbandonedCart(ctx, telemetry, abandoned.Cart, abandoned.Age, abandoned.Idle)
+	})
+	cartSweeper.Start(cfg.Cart.SweepInterval)
+
+	// ルーター初期化
+	router := api.NewRouter(productUseCase, cartUseCase, orderUseCase, faults, scenarios, sliRecorder, sloEvaluator, sloAlerter, prometheusExporter, telemetry, cfg.Cart.Fallback, cfg.Cart.TrustUserHeader, cfg.Idempotency.TTL)
+	ginEngine := router.SetupRoutes()
+
+	// HTTPサーバー設定
+	server := &http.Server{
+		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
+		Handler:      ginEngine,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// サーバー起動（ゴルーチンで実行）
+	go func() {
+		log.Printf("Starting server on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// グレースフルシャットダウンの設定
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	// シャットダウンシグナル待機
+	<-quit
+	log.Println("Shutting down server...")
+
+	// グレースフルシャットダウン実行
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	scenarios.Stop()
+	sloAlerter.Stop()
+	cartSweeper.Stop()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+
+	// 受け付け済みの決済を完了させる（期限を過ぎた注文は pending のまま残る）
+	if err := paymentQueue.Drain(ctx); err != nil {
+		log.Printf("Failed to drain payment queue: %v", err)
+	}
+
+	// 未送信のテレメトリを送信
+	if err := telemetry.Shutdown(ctx); err != nil {
+		log.Printf("Failed to shutdown telemetry: %v", err)
+	}
+
+	log.Println("Server exited")
+}
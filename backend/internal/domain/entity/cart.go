@@ -10,6 +10,7 @@ type CartItem struct {
 	ID        string    `json:"id"`
 	ProductID string    `json:"productId"`
 	Product   *Product  `json:"product"`
+	UnitPrice int       `json:"unitPrice"` // カートに追加した時点の単価（合計金額・注文金額に使用）
 	Quantity  int       `json:"quantity"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -40,14 +41,29 @@ func NewCartItem(productID string, product *Product, quantity int) *CartItem {
 		ID:        uuid.New().String(),
 		ProductID: productID,
 		Product:   product,
+		UnitPrice: product.Price,
 		Quantity:  quantity,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 }
 
+// Clone はカートのコピーを返す（アイテム・商品も複製し、元のカートと共有しない）
+func (c *Cart) Clone() *Cart {
+	clone := *c
+	clone.Items = make([]*CartItem, len(c.Items))
+	for i, item := range c.Items {
+		itemCopy := *item
+		if item.Product != nil {
+			itemCopy.Product = item.Product.Clone()
+		}
+		clone.Items[i] = &itemCopy
+	}
+	return &clone
+}
+
 func (c *Cart) AddItem(product *Product, quantity int) error {
-	// SLMハンズオン用に在庫チェックを無効化
+	// 在庫の確認は INVENTORY_MODE に応じてユースケース層（Inventory）で行う
 
 	// 既存のアイテムがあるかチェック
 	for _, item := range c.Items {
@@ -74,7 +90,7 @@ func (c *Cart) UpdateItemQuantity(itemID string, quantity int) error {
 			if quantity <= 0 {
 				return c.RemoveItem(itemID)
 			}
-			// SLMハンズオン用に在庫チェックを無効化
+			// 在庫の確認は INVENTORY_MODE に応じてユースケース層（Inventory）で行う
 			item.Quantity = quantity
 			item.UpdatedAt = time.Now()
 			c.calculateTotal()
@@ -111,6 +127,58 @@ func (c *Cart) GetItemCount() int {
 	return count
 }
 
+// QuantityOf はカート内の指定商品の数量を返す（カートにない場合は0）
+func (c *Cart) QuantityOf(productID string) int {
+	for _, item := range c.Items {
+		if item.ProductID == productID {
+			return item.Quantity
+		}
+	}
+	return 0
+}
+
+// PriceChange はカートに追加した時点から価格が変わった商品
+type PriceChange struct {
+	ItemID     string `json:"itemId"`
+	ProductID  string `json:"productId"`
+	OldPrice   int    `json:"oldPrice"`
+	NewPrice   int    `json:"newPrice"`
+	Difference int    `json:"difference"` // NewPrice - OldPrice
+}
+
+// PriceChanges は現在の商品情報と比較して価格が変わったカートアイテムを返す
+// products にない商品（販売終了など）は比較しない
+func (c *Cart) PriceChanges(products map[string]*Product) []PriceChange {
+	changes := make([]PriceChange, 0)
+	for _, item := range c.Items {
+		product, exists := products[item.ProductID]
+		if !exists || product.Price == item.UnitPrice {
+			continue
+		}
+		changes = append(changes, PriceChange{
+			ItemID:     item.ID,
+			ProductID:  item.ProductID,
+			OldPrice:   item.UnitPrice,
+			NewPrice:   product.Price,
+			Difference: product.Price - item.UnitPrice,
+		})
+	}
+	return changes
+}
+
+// ApplyCurrentPrices はカートアイテムの単価を現在の商品価格に更新する（価格変更の確認後に使用）
+func (c *Cart) ApplyCurrentPrices(products map[string]*Product) {
+	for _, item := range c.Items {
+		if product, exists := products[item.ProductID]; exists {
+			item.Product = product
+			item.UnitPrice = product.Price
+			item.UpdatedAt = time.Now()
+		}
+	}
+	c.calculateTotal()
+	c.UpdatedAt = time.Now()
+}
+
 func (c *Cart) IsEmpty() bool {
 	return len(c.Items) == 0
 }
@@ -118,7 +186,7 @@ func (c *Cart) IsEmpty() bool {
 func (c *Cart) calculateTotal() {
 	total := 0
 	for _, item := range c.Items {
-		total += item.Product.Price * item.Quantity
+		total += item.UnitPrice * item.Quantity
 	}
 	c.TotalAmount = total
 }
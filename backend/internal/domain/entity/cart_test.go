@@ -92,7 +92,7 @@ func TestCart_AddItem(t *testing.T) {
 			expectedItems: 1,
 			expectedTotal: 1500,
 		},
-		// SLMハンズオン用に在庫チェックを無効化したため、在庫エラーケースは削除
+		// 在庫の確認はユースケース層で行うため、在庫エラーケースはない
 	}
 
 	for _, tt := range tests {
@@ -103,7 +103,7 @@ func TestCart_AddItem(t *testing.T) {
 			time.Sleep(10 * time.Millisecond)
 			err := cart.AddItem(tt.product, tt.quantity)
 
-			// SLMハンズオン用に在庫チェックが無効化されたため、常に成功する
+			// カートでは在庫を確認しないため、常に成功する
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
@@ -198,7 +198,7 @@ func TestCart_UpdateItemQuantity(t *testing.T) {
 					t.Error("エラーが期待されましたが、エラーが発生しませんでした")
 				}
 			} else {
-				// SLMハンズオン用に在庫チェックが無効化されたため、有効なIDの場合は常に成功
+				// カートでは在庫を確認しないため、有効なIDの場合は常に成功
 				if err != nil {
 					t.Errorf("Unexpected error: %v", err)
 				}
@@ -413,3 +413,106 @@ func TestCart_IsEmpty(t *testing.T) {
 		})
 	}
 }
+
+func TestCart_QuantityOf(t *testing.T) {
+	cart := NewCart()
+	product1 := NewProduct("商品1", "説明1", 1000, "image1.jpg", 10)
+	product2 := NewProduct("商品2", "説明2", 500, "image2.jpg", 10)
+	cart.AddItem(product1, 2)
+	cart.AddItem(product1, 1)
+
+	tests := []struct {
+		name             string
+		productID        string
+		expectedQuantity int
+	}{
+		{name: "カート内の商品", productID: product1.ID, expectedQuantity: 3},
+		{name: "カートにない商品", productID: product2.ID, expectedQuantity: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if quantity := cart.QuantityOf(tt.productID); quantity != tt.expectedQuantity {
+				t.Errorf("QuantityOf() = %v, want %v", quantity, tt.expectedQuantity)
+			}
+		})
+	}
+}
+
+func TestCart_PriceChanges(t *testing.T) {
+	product1 := NewProduct("商品1", "説明1", 1000, "image1.jpg", 10)
+	product2 := NewProduct("商品2", "説明2", 500, "image2.jpg", 10)
+
+	tests := []struct {
+		name            string
+		currentPrices   map[string]int // 商品ID -> 現在の価格（含まれない商品は販売終了）
+		expectedChanges []PriceChange
+		expectedTotal   int
+	}{
+		{
+			name:            "価格が変わっていない",
+			currentPrices:   map[string]int{product1.ID: 1000, product2.ID: 500},
+			expectedChanges: []PriceChange{},
+			expectedTotal:   2500,
+		},
+		{
+			name:          "値上げされた商品",
+			currentPrices: map[string]int{product1.ID: 1200, product2.ID: 500},
+			expectedChanges: []PriceChange{
+				{ProductID: product1.ID, OldPrice: 1000, NewPrice: 1200, Difference: 200},
+			},
+			expectedTotal: 2900,
+		},
+		{
+			name:          "値下げされた商品",
+			currentPrices: map[string]int{product1.ID: 1000, product2.ID: 400},
+			expectedChanges: []PriceChange{
+				{ProductID: product2.ID, OldPrice: 500, NewPrice: 400, Difference: -100},
+			},
+			expectedTotal: 2400,
+		},
+		{
+			name:            "販売終了した商品は比較しない",
+			currentPrices:   map[string]int{product1.ID: 1000},
+			expectedChanges: []PriceChange{},
+			expectedTotal:   2500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := NewCart()
+			cart.AddItem(product1, 2)
+			cart.AddItem(product2, 1)
+
+			products := make(map[string]*Product)
+			for id, price := range tt.currentPrices {
+				products[id] = &Product{ID: id, Price: price}
+			}
+
+			changes := cart.PriceChanges(products)
+			if len(changes) != len(tt.expectedChanges) {
+				t.Fatalf("価格変更数 = %v, want %v", len(changes), len(tt.expectedChanges))
+			}
+			for i, expected := range tt.expectedChanges {
+				expected.ItemID = changes[i].ItemID
+				if changes[i] != expected {
+					t.Errorf("価格変更 = %+v, want %+v", changes[i], expected)
+				}
+			}
+
+			// 価格を確認するまでは追加時の単価で合計金額を計算する
+			if cart.TotalAmount != 2500 {
+				t.Errorf("確認前の合計金額 = %v, want 2500", cart.TotalAmount)
+			}
+
+			cart.ApplyCurrentPrices(products)
+			if cart.TotalAmount != tt.expectedTotal {
+				t.Errorf("確認後の合計金額 = %v, want %v", cart.TotalAmount, tt.expectedTotal)
+			}
+			if changes := cart.PriceChanges(products); len(changes) != 0 {
+				t.Errorf("確認後の価格変更 = %v, want なし", changes)
+			}
+		})
+	}
+}
@@ -11,11 +11,17 @@ var (
 	// Cart関連エラー
 	ErrItemNotFound = errors.New("item not found in cart")
 	ErrEmptyCart    = errors.New("cart is empty")
+	ErrPriceChanged = errors.New("product price changed since added to cart")
 
 	// Order関連エラー
 	ErrOrderNotFound      = errors.New("order not found")
 	ErrInvalidOrderStatus = errors.New("invalid order status transition")
 
+	// Payment関連エラー
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrInvalidPaymentStatus = errors.New("invalid payment status for this operation")
+	ErrPaymentUnavailable   = errors.New("payment gateway unavailable")
+
 	// 一般的なエラー
 	ErrInvalidInput = errors.New("invalid input")
 )
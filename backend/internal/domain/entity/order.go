@@ -9,10 +9,59 @@ import (
 type OrderStatus string
 
 const (
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusCompleted OrderStatus = "completed"
-	OrderStatusFailed    OrderStatus = "failed"
-	OrderStatusCanceled  OrderStatus = "canceled"
+	OrderStatusPending   OrderStatus = "pending"   // 決済待ち
+	OrderStatusPaid      OrderStatus = "paid"      // 決済完了
+	OrderStatusShipped   OrderStatus = "shipped"   // 出荷済み
+	OrderStatusDelivered OrderStatus = "delivered" // 配達済み
+	OrderStatusCompleted OrderStatus = "completed" // 取引完了（返品期間の終了）
+	OrderStatusFailed    OrderStatus = "failed"    // 決済失敗
+	OrderStatusCanceled  OrderStatus = "canceled"  // キャンセル（出荷前のみ）
+	OrderStatusRefunded  OrderStatus = "refunded"  // 返金済み
+)
+
+// orderTransitions は注文ステータスごとに遷移できるステータス（含まれない遷移は ErrInvalidOrderStatus）
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusFailed, OrderStatusCanceled},
+	OrderStatusPaid:      {OrderStatusShipped, OrderStatusCanceled, OrderStatusRefunded},
+	OrderStatusShipped:   {OrderStatusDelivered},
+	OrderStatusDelivered: {OrderStatusCompleted, OrderStatusRefunded},
+	OrderStatusCompleted: {OrderStatusRefunded},
+	OrderStatusFailed:    {},
+	OrderStatusCanceled:  {},
+	OrderStatusRefunded:  {},
+}
+
+// IsValid は遷移表に定義されたステータスか判定する
+func (s OrderStatus) IsValid() bool {
+	_, ok := orderTransitions[s]
+	return ok
+}
+
+// CanTransitionTo は遷移表に従って next に遷移できるか判定する
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, status := range orderTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange は注文ステータスの遷移1件
+type StatusChange struct {
+	From   OrderStatus `json:"from,omitempty"`
+	To     OrderStatus `json:"to"`
+	Reason string      `json:"reason,omitempty"`
+	At     time.Time   `json:"at"`
+}
+
+// StockReservation は注文に対する在庫の引き当て状態（在庫管理が有効な場合のみ）
+type StockReservation string
+
+const (
+	StockReserved  StockReservation = "reserved"  // 決済待ちで引き当て中
+	StockCommitted StockReservation = "committed" // 決済完了で在庫から減らした
+	StockReleased  StockReservation = "released"  // 決済失敗・キャンセルで引き当てを取り消した（確定後は在庫に戻した）
 )
 
 type OrderItem struct {
@@ -25,12 +74,17 @@ type OrderItem struct {
 }
 
 type Order struct {
-	ID          string       `json:"id"`
-	Items       []*OrderItem `json:"items"`
-	TotalAmount int          `json:"totalAmount"`
-	Status      OrderStatus  `json:"status"`
-	CreatedAt   time.Time    `json:"createdAt"`
-	UpdatedAt   time.Time    `json:"updatedAt"`
+	ID          string           `json:"id"`
+	Items       []*OrderItem     `json:"items"`
+	TotalAmount int              `json:"totalAmount"`
+	Status      OrderStatus      `json:"status"`
+	Reservation StockReservation `json:"reservation,omitempty"`
+	// 決済の状況（試行回数・決済ゲートウェイの取引ID・失敗理由）
+	Payment OrderPayment `json:"payment"`
+	// ステータスの遷移履歴（作成時の pending から順に記録）
+	StatusHistory []StatusChange `json:"statusHistory"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
 }
 
 func NewOrder(cart *Cart) (*Order, error) {
@@ -44,8 +98,12 @@ func NewOrder(cart *Cart) (*Order, error) {
 		Items:       make([]*OrderItem, 0, len(cart.Items)),
 		TotalAmount: cart.TotalAmount,
 		Status:      OrderStatusPending,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Payment:     OrderPayment{Status: PaymentStatusPending},
+		StatusHistory: []StatusChange{
+			{To: OrderStatusPending, Reason: "order created", At: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	// カートアイテムを注文アイテムに変換
@@ -55,7 +113,7 @@ func NewOrder(cart *Cart) (*Order, error) {
 			ProductID: cartItem.ProductID,
 			Product:   cartItem.Product,
 			Quantity:  cartItem.Quantity,
-			Price:     cartItem.Product.Price, // 注文時の価格を記録
+			Price:     cartItem.UnitPrice, // カートに追加した時点の価格で注文する
 			CreatedAt: now,
 		}
 		order.Items = append(order.Items, orderItem)
@@ -64,31 +122,77 @@ func NewOrder(cart *Cart) (*Order, error) {
 	return order, nil
 }
 
-func (o *Order) Complete() error {
-	if o.Status != OrderStatusPending {
-		return ErrInvalidOrderStatus
+// Clone は注文のコピーを返す（アイテム・商品・決済情報・遷移履歴も複製し、元の注文と共有しない）
+func (o *Order) Clone() *Order {
+	clone := *o
+	clone.Items = make([]*OrderItem, len(o.Items))
+	for i, item := range o.Items {
+		itemCopy := *item
+		if item.Product != nil {
+			itemCopy.Product = item.Product.Clone()
+		}
+		clone.Items[i] = &itemCopy
 	}
-	o.Status = OrderStatusCompleted
-	o.UpdatedAt = time.Now()
-	return nil
+	clone.Payment = o.Payment.clone()
+	clone.StatusHistory = append([]StatusChange(nil), o.StatusHistory...)
+	return &clone
 }
 
-func (o *Order) Fail() error {
-	if o.Status != OrderStatusPending {
+// TransitionTo は遷移表で許可されている場合のみステータスを変更し、遷移履歴に記録する
+func (o *Order) TransitionTo(next OrderStatus, reason string) error {
+	if !o.Status.CanTransitionTo(next) {
 		return ErrInvalidOrderStatus
 	}
-	o.Status = OrderStatusFailed
-	o.UpdatedAt = time.Now()
+
+	now := time.Now()
+	o.StatusHistory = append(o.StatusHistory, StatusChange{From: o.Status, To: next, Reason: reason, At: now})
+	o.Status = next
+	o.UpdatedAt = now
 	return nil
 }
 
-func (o *Order) Cancel() error {
-	if o.Status == OrderStatusCompleted {
-		return ErrInvalidOrderStatus
+func (o *Order) Pay() error {
+	return o.TransitionTo(OrderStatusPaid, "payment succeeded")
+}
+
+func (o *Order) Fail() error {
+	return o.TransitionTo(OrderStatusFailed, "payment failed")
+}
+
+// FailPayment は決済の失敗理由をステータス履歴に残して注文を失敗にする
+// 決済情報（OrderPayment）には RecordResult / RecordFailure で失敗理由を記録しておく
+func (o *Order) FailPayment() error {
+	return o.TransitionTo(OrderStatusFailed, o.Payment.FailureMessage())
+}
+
+func (o *Order) Ship() error {
+	return o.TransitionTo(OrderStatusShipped, "shipped")
+}
+
+func (o *Order) Deliver() error {
+	return o.TransitionTo(OrderStatusDelivered, "delivered")
+}
+
+func (o *Order) Complete() error {
+	return o.TransitionTo(OrderStatusCompleted, "completed")
+}
+
+// Cancel は出荷前の注文をキャンセルする
+func (o *Order) Cancel(reason string) error {
+	return o.TransitionTo(OrderStatusCanceled, reason)
+}
+
+func (o *Order) Refund(reason string) error {
+	return o.TransitionTo(OrderStatusRefunded, reason)
+}
+
+// StockQuantities は注文の商品ごとの数量を返す（在庫の引き当て用）
+func (o *Order) StockQuantities() []StockQuantity {
+	quantities := make([]StockQuantity, 0, len(o.Items))
+	for _, item := range o.Items {
+		quantities = append(quantities, StockQuantity{ProductID: item.ProductID, Quantity: item.Quantity})
 	}
-	o.Status = OrderStatusCanceled
-	o.UpdatedAt = time.Now()
-	return nil
+	return quantities
 }
 
 func (o *Order) GetItemCount() int {
@@ -103,6 +207,10 @@ func (o *Order) IsCompleted() bool {
 	return o.Status == OrderStatusCompleted
 }
 
+func (o *Order) IsPaid() bool {
+	return o.Status == OrderStatusPaid
+}
+
 func (o *Order) IsPending() bool {
 	return o.Status == OrderStatusPending
 }
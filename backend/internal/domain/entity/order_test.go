@@ -122,17 +122,32 @@ func TestOrder_Complete(t *testing.T) {
 		expectedStatus OrderStatus
 	}{
 		{
-			name: "Pendingから完了",
+			name: "配達済みから完了",
 			setupOrder: func() *Order {
 				cart := NewCart()
 				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
 				cart.AddItem(product, 1)
 				order, _ := NewOrder(cart)
+				order.Pay()
+				order.Ship()
+				order.Deliver()
 				return order
 			},
 			expectError:    false,
 			expectedStatus: OrderStatusCompleted,
 		},
+		{
+			name: "決済待ちから完了（エラー）",
+			setupOrder: func() *Order {
+				cart := NewCart()
+				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
+				cart.AddItem(product, 1)
+				order, _ := NewOrder(cart)
+				return order
+			},
+			expectError:    true,
+			expectedStatus: OrderStatusPending,
+		},
 		{
 			name: "既に完了済み（エラー）",
 			setupOrder: func() *Order {
@@ -140,6 +155,9 @@ func TestOrder_Complete(t *testing.T) {
 				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
 				cart.AddItem(product, 1)
 				order, _ := NewOrder(cart)
+				order.Pay()
+				order.Ship()
+				order.Deliver()
 				order.Complete()
 				return order
 			},
@@ -209,17 +227,17 @@ func TestOrder_Fail(t *testing.T) {
 			expectedStatus: OrderStatusFailed,
 		},
 		{
-			name: "完了済みから失敗（エラー）",
+			name: "決済完了から失敗（エラー）",
 			setupOrder: func() *Order {
 				cart := NewCart()
 				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
 				cart.AddItem(product, 1)
 				order, _ := NewOrder(cart)
-				order.Complete()
+				order.Pay()
 				return order
 			},
 			expectError:    true,
-			expectedStatus: OrderStatusCompleted,
+			expectedStatus: OrderStatusPaid,
 		},
 	}
 
@@ -271,30 +289,57 @@ func TestOrder_Cancel(t *testing.T) {
 			expectedStatus: OrderStatusCanceled,
 		},
 		{
-			name: "失敗状態からキャンセル",
+			name: "決済完了からキャンセル",
 			setupOrder: func() *Order {
 				cart := NewCart()
 				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
 				cart.AddItem(product, 1)
 				order, _ := NewOrder(cart)
-				order.Fail()
+				order.Pay()
 				return order
 			},
 			expectError:    false,
 			expectedStatus: OrderStatusCanceled,
 		},
 		{
-			name: "完了済みからキャンセル（エラー）",
+			name: "失敗状態からキャンセル（エラー）",
 			setupOrder: func() *Order {
 				cart := NewCart()
 				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
 				cart.AddItem(product, 1)
 				order, _ := NewOrder(cart)
-				order.Complete()
+				order.Fail()
 				return order
 			},
 			expectError:    true,
-			expectedStatus: OrderStatusCompleted,
+			expectedStatus: OrderStatusFailed,
+		},
+		{
+			name: "出荷済みからキャンセル（エラー）",
+			setupOrder: func() *Order {
+				cart := NewCart()
+				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
+				cart.AddItem(product, 1)
+				order, _ := NewOrder(cart)
+				order.Pay()
+				order.Ship()
+				return order
+			},
+			expectError:    true,
+			expectedStatus: OrderStatusShipped,
+		},
+		{
+			name: "キャンセル済みからキャンセル（エラー）",
+			setupOrder: func() *Order {
+				cart := NewCart()
+				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
+				cart.AddItem(product, 1)
+				order, _ := NewOrder(cart)
+				order.Cancel("")
+				return order
+			},
+			expectError:    true,
+			expectedStatus: OrderStatusCanceled,
 		},
 	}
 
@@ -304,7 +349,7 @@ func TestOrder_Cancel(t *testing.T) {
 			originalUpdatedAt := order.UpdatedAt
 
 			time.Sleep(10 * time.Millisecond)
-			err := order.Cancel()
+			err := order.Cancel("customer request")
 
 			if tt.expectError {
 				if err != ErrInvalidOrderStatus {
@@ -326,6 +371,73 @@ func TestOrder_Cancel(t *testing.T) {
 	}
 }
 
+func TestOrderStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     OrderStatus
+		to       OrderStatus
+		expected bool
+	}{
+		{name: "決済待ちから決済完了", from: OrderStatusPending, to: OrderStatusPaid, expected: true},
+		{name: "決済待ちから出荷（決済前）", from: OrderStatusPending, to: OrderStatusShipped, expected: false},
+		{name: "決済完了から出荷", from: OrderStatusPaid, to: OrderStatusShipped, expected: true},
+		{name: "決済完了から返金", from: OrderStatusPaid, to: OrderStatusRefunded, expected: true},
+		{name: "出荷済みから配達済み", from: OrderStatusShipped, to: OrderStatusDelivered, expected: true},
+		{name: "出荷済みから返金（配達前）", from: OrderStatusShipped, to: OrderStatusRefunded, expected: false},
+		{name: "配達済みから返金", from: OrderStatusDelivered, to: OrderStatusRefunded, expected: true},
+		{name: "完了から返金", from: OrderStatusCompleted, to: OrderStatusRefunded, expected: true},
+		{name: "返金済みは終端", from: OrderStatusRefunded, to: OrderStatusPaid, expected: false},
+		{name: "同じステータスへの遷移", from: OrderStatusPaid, to: OrderStatusPaid, expected: false},
+		{name: "未知のステータス", from: OrderStatus("unknown"), to: OrderStatusPaid, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.expected {
+				t.Errorf("%v.CanTransitionTo(%v) = %v, want %v", tt.from, tt.to, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOrder_StatusHistory(t *testing.T) {
+	cart := NewCart()
+	product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
+	cart.AddItem(product, 1)
+	order, _ := NewOrder(cart)
+
+	if err := order.Pay(); err != nil {
+		t.Fatalf("Pay() エラー: %v", err)
+	}
+	if err := order.Cancel("customer request"); err != nil {
+		t.Fatalf("Cancel() エラー: %v", err)
+	}
+	// 拒否された遷移は履歴に残らない
+	if err := order.Refund("duplicate"); err != ErrInvalidOrderStatus {
+		t.Fatalf("Refund() エラー = %v, want ErrInvalidOrderStatus", err)
+	}
+
+	expected := []StatusChange{
+		{To: OrderStatusPending, Reason: "order created"},
+		{From: OrderStatusPending, To: OrderStatusPaid, Reason: "payment succeeded"},
+		{From: OrderStatusPaid, To: OrderStatusCanceled, Reason: "customer request"},
+	}
+	if len(order.StatusHistory) != len(expected) {
+		t.Fatalf("StatusHistory の件数 = %v, want %v", len(order.StatusHistory), len(expected))
+	}
+	for i, change := range order.StatusHistory {
+		if change.From != expected[i].From || change.To != expected[i].To || change.Reason != expected[i].Reason {
+			t.Errorf("StatusHistory[%d] = %+v, want %+v", i, change, expected[i])
+		}
+		if change.At.IsZero() {
+			t.Errorf("StatusHistory[%d].At が設定されていません", i)
+		}
+	}
+	if last := order.StatusHistory[len(order.StatusHistory)-1]; !last.At.Equal(order.UpdatedAt) {
+		t.Errorf("最後の遷移時刻 = %v, want UpdatedAt %v", last.At, order.UpdatedAt)
+	}
+}
+
 func TestOrder_GetItemCount(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -399,6 +511,9 @@ func TestOrder_StatusCheckers(t *testing.T) {
 				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
 				cart.AddItem(product, 1)
 				order, _ := NewOrder(cart)
+				order.Pay()
+				order.Ship()
+				order.Deliver()
 				order.Complete()
 				return order
 			},
@@ -429,7 +544,7 @@ func TestOrder_StatusCheckers(t *testing.T) {
 				product := NewProduct("商品", "説明", 1000, "image.jpg", 10)
 				cart.AddItem(product, 1)
 				order, _ := NewOrder(cart)
-				order.Cancel()
+				order.Cancel("")
 				return order
 			},
 			isCompleted: false,
@@ -458,3 +573,26 @@ func TestOrder_StatusCheckers(t *testing.T) {
 		})
 	}
 }
+
+func TestOrder_Clone(t *testing.T) {
+	cart := NewCart()
+	cart.AddItem(NewProduct("テスト商品", "説明", 1000, "image.jpg", 10), 2)
+	order, _ := NewOrder(cart)
+	order.Payment.StartAttempt(time.Now())
+
+	clone := order.Clone()
+	clone.Pay()
+	clone.Items[0].Quantity = 5
+	clone.Items[0].Product.Stock = 0
+	*clone.Payment.LastAttemptAt = time.Time{}
+
+	if order.Status != OrderStatusPending || len(order.StatusHistory) != 1 {
+		t.Errorf("元の注文のステータス = %v (履歴 %v件), want pending (1件)", order.Status, len(order.StatusHistory))
+	}
+	if order.Items[0].Quantity != 2 || order.Items[0].Product.Stock != 10 {
+		t.Errorf("元の注文のアイテム = %+v", order.Items[0])
+	}
+	if order.Payment.LastAttemptAt.IsZero() {
+		t.Error("元の注文の決済情報が変更されました")
+	}
+}
@@ -0,0 +1,145 @@
+package entity
+
+import "time"
+
+// PaymentStatus は決済ゲートウェイ上の決済の状態
+type PaymentStatus string
+
+const (
+	PaymentStatusPending    PaymentStatus = "pending"    // 未処理・処理中（注文の決済情報のみ）
+	PaymentStatusFailed     PaymentStatus = "failed"     // 決済ゲートウェイの障害で完了しなかった（注文の決済情報のみ）
+	PaymentStatusAuthorized PaymentStatus = "authorized" // 与信済み（売上未確定）
+	PaymentStatusCaptured   PaymentStatus = "captured"   // 売上確定
+	PaymentStatusDeclined   PaymentStatus = "declined"   // 与信を拒否された
+	PaymentStatusVoided     PaymentStatus = "voided"     // 与信を取り消した
+	PaymentStatusRefunded   PaymentStatus = "refunded"   // 返金済み
+)
+
+// 決済の拒否理由（カード会社・決済代行からの応答コード）
+const (
+	DeclineInsufficientFunds = "insufficient_funds" // 残高・限度額不足
+	DeclineCardExpired       = "card_expired"       // 有効期限切れ
+	DeclineCardDeclined      = "card_declined"      // 理由を開示しない拒否
+	DeclineFraudSuspected    = "fraud_suspected"    // 不正利用の疑い
+)
+
+// 決済が完了しなかった理由（OrderPayment.FailureReason）
+const (
+	PaymentFailureDeclined    = "declined"            // 与信を拒否された（理由は DeclineCode）
+	PaymentFailureUnavailable = "gateway_unavailable" // 決済ゲートウェイの障害・タイムアウト
+	PaymentFailureError       = "gateway_error"       // その他の決済ゲートウェイのエラー
+)
+
+// PaymentCurrency は決済の通貨（商品価格は円）
+const PaymentCurrency = "JPY"
+
+// PaymentRequest は注文の与信リクエスト
+type PaymentRequest struct {
+	OrderID  string `json:"orderId"`
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewPaymentRequest は注文の合計金額で与信リクエストを作成する
+func NewPaymentRequest(order *Order) PaymentRequest {
+	return PaymentRequest{
+		OrderID:  order.ID,
+		Amount:   order.TotalAmount,
+		Currency: PaymentCurrency,
+	}
+}
+
+// PaymentResult は決済ゲートウェイの応答
+// 与信の拒否はエラーではなく Status が declined の結果として返す
+type PaymentResult struct {
+	TransactionID string        `json:"transactionId"`
+	Status        PaymentStatus `json:"status"`
+	Amount        int           `json:"amount"`
+	DeclineCode   string        `json:"declineCode,omitempty"`
+	ProcessedAt   time.Time     `json:"processedAt"`
+}
+
+func (r *PaymentResult) IsDeclined() bool {
+	return r.Status == PaymentStatusDeclined
+}
+
+// OrderPayment は注文の決済の状況（GET /api/orders/:id で決済待ち・失敗の理由を確認できる）
+type OrderPayment struct {
+	Status            PaymentStatus `json:"status"`
+	Attempts          int           `json:"attempts"`
+	ProviderReference string        `json:"providerReference,omitempty"` // 決済ゲートウェイの取引ID
+	DeclineCode       string        `json:"declineCode,omitempty"`
+	FailureReason     string        `json:"failureReason,omitempty"`
+	LastAttemptAt     *time.Time    `json:"lastAttemptAt,omitempty"`
+	AuthorizedAt      *time.Time    `json:"authorizedAt,omitempty"`
+	CapturedAt        *time.Time    `json:"capturedAt,omitempty"`
+	FailedAt          *time.Time    `json:"failedAt,omitempty"`
+	RefundedAt        *time.Time    `json:"refundedAt,omitempty"`
+}
+
+// clone は日時のポインターを複製した決済情報を返す
+func (p OrderPayment) clone() OrderPayment {
+	for _, at := range []**time.Time{&p.LastAttemptAt, &p.AuthorizedAt, &p.CapturedAt, &p.FailedAt, &p.RefundedAt} {
+		if *at != nil {
+			copied := **at
+			*at = &copied
+		}
+	}
+	return p
+}
+
+// IsCaptured は売上確定済み（返金が必要）か判定する
+func (p *OrderPayment) IsCaptured() bool {
+	return p.Status == PaymentStatusCaptured
+}
+
+// StartAttempt は決済の試行を記録する（前回の試行の失敗理由は消去する）
+func (p *OrderPayment) StartAttempt(now time.Time) {
+	p.Status = PaymentStatusPending
+	p.Attempts++
+	p.LastAttemptAt = &now
+	p.DeclineCode = ""
+	p.FailureReason = ""
+	p.FailedAt = nil
+}
+
+// RecordResult は決済ゲートウェイの応答を反映する
+func (p *OrderPayment) RecordResult(result *PaymentResult) {
+	at := result.ProcessedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	p.Status = result.Status
+	if result.TransactionID != "" {
+		p.ProviderReference = result.TransactionID
+	}
+
+	switch result.Status {
+	case PaymentStatusAuthorized:
+		p.AuthorizedAt = &at
+	case PaymentStatusCaptured:
+		p.CapturedAt = &at
+	case PaymentStatusDeclined:
+		p.DeclineCode = result.DeclineCode
+		p.FailureReason = PaymentFailureDeclined
+		p.FailedAt = &at
+	case PaymentStatusRefunded:
+		p.RefundedAt = &at
+	}
+}
+
+// RecordFailure は決済ゲートウェイの障害で決済が完了しなかったことを記録する
+func (p *OrderPayment) RecordFailure(reason string, now time.Time) {
+	p.Status = PaymentStatusFailed
+	p.FailureReason = reason
+	p.FailedAt = &now
+}
+
+// FailureMessage は失敗理由を1行で返す（ステータス履歴の理由用）
+func (p *OrderPayment) FailureMessage() string {
+	if p.DeclineCode != "" {
+		return "payment " + p.FailureReason + ": " + p.DeclineCode
+	}
+	return "payment failed: " + p.FailureReason
+}
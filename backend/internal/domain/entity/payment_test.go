@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderPayment_Record(t *testing.T) {
+	processedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                  string
+		record                func(payment *OrderPayment)
+		expectedStatus        PaymentStatus
+		expectedReference     string
+		expectedFailureReason string
+		expectedMessage       string
+		expectedCaptured      bool
+	}{
+		{
+			name: "与信と売上確定",
+			record: func(payment *OrderPayment) {
+				payment.RecordResult(&PaymentResult{TransactionID: "txn-1", Status: PaymentStatusAuthorized, ProcessedAt: processedAt})
+				payment.RecordResult(&PaymentResult{TransactionID: "txn-1", Status: PaymentStatusCaptured, ProcessedAt: processedAt})
+			},
+			expectedStatus:    PaymentStatusCaptured,
+			expectedReference: "txn-1",
+			expectedCaptured:  true,
+		},
+		{
+			name: "与信の拒否",
+			record: func(payment *OrderPayment) {
+				payment.RecordResult(&PaymentResult{TransactionID: "txn-1", Status: PaymentStatusDeclined, DeclineCode: DeclineCardExpired, ProcessedAt: processedAt})
+			},
+			expectedStatus:        PaymentStatusDeclined,
+			expectedReference:     "txn-1",
+			expectedFailureReason: PaymentFailureDeclined,
+			expectedMessage:       "payment declined: card_expired",
+		},
+		{
+			name: "決済ゲートウェイの障害",
+			record: func(payment *OrderPayment) {
+				payment.RecordFailure(PaymentFailureUnavailable, processedAt)
+			},
+			expectedStatus:        PaymentStatusFailed,
+			expectedFailureReason: PaymentFailureUnavailable,
+			expectedMessage:       "payment failed: gateway_unavailable",
+		},
+		{
+			name: "返金",
+			record: func(payment *OrderPayment) {
+				payment.RecordResult(&PaymentResult{TransactionID: "txn-1", Status: PaymentStatusCaptured, ProcessedAt: processedAt})
+				payment.RecordResult(&PaymentResult{TransactionID: "txn-1", Status: PaymentStatusRefunded, ProcessedAt: processedAt})
+			},
+			expectedStatus:    PaymentStatusRefunded,
+			expectedReference: "txn-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := OrderPayment{Status: PaymentStatusPending}
+			payment.StartAttempt(processedAt)
+			tt.record(&payment)
+
+			if payment.Status != tt.expectedStatus {
+				t.Errorf("Status = %v, want %v", payment.Status, tt.expectedStatus)
+			}
+			if payment.Attempts != 1 || payment.LastAttemptAt == nil {
+				t.Errorf("Attempts = %v, LastAttemptAt = %v", payment.Attempts, payment.LastAttemptAt)
+			}
+			if payment.ProviderReference != tt.expectedReference {
+				t.Errorf("ProviderReference = %q, want %q", payment.ProviderReference, tt.expectedReference)
+			}
+			if payment.FailureReason != tt.expectedFailureReason {
+				t.Errorf("FailureReason = %q, want %q", payment.FailureReason, tt.expectedFailureReason)
+			}
+			if tt.expectedFailureReason != "" {
+				if payment.FailedAt == nil {
+					t.Error("FailedAt が設定されていません")
+				}
+				if message := payment.FailureMessage(); message != tt.expectedMessage {
+					t.Errorf("FailureMessage() = %q, want %q", message, tt.expectedMessage)
+				}
+			}
+			if payment.IsCaptured() != tt.expectedCaptured {
+				t.Errorf("IsCaptured() = %v, want %v", payment.IsCaptured(), tt.expectedCaptured)
+			}
+		})
+	}
+}
+
+func TestOrder_FailPayment(t *testing.T) {
+	cart := NewCart()
+	cart.AddItem(NewProduct("商品", "説明", 1000, "image.jpg", 10), 1)
+	order, _ := NewOrder(cart)
+
+	if order.Payment.Status != PaymentStatusPending {
+		t.Fatalf("Payment.Status = %v, want %v", order.Payment.Status, PaymentStatusPending)
+	}
+
+	order.Payment.RecordResult(&PaymentResult{TransactionID: "txn-1", Status: PaymentStatusDeclined, DeclineCode: DeclineInsufficientFunds})
+	if err := order.FailPayment(); err != nil {
+		t.Fatalf("FailPayment() エラー: %v", err)
+	}
+
+	if order.Status != OrderStatusFailed {
+		t.Errorf("Status = %v, want %v", order.Status, OrderStatusFailed)
+	}
+	// 失敗理由はステータス履歴にも残る
+	if last := order.StatusHistory[len(order.StatusHistory)-1]; last.Reason != "payment declined: insufficient_funds" {
+		t.Errorf("Reason = %q, want %q", last.Reason, "payment declined: insufficient_funds")
+	}
+}
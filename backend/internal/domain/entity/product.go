@@ -7,14 +7,17 @@ import (
 )
 
 type Product struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       int       `json:"price"` // 価格は円単位で整数で管理
-	ImageURL    string    `json:"imageUrl"`
-	Stock       int       `json:"stock"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       int    `json:"price"` // 価格は円単位で整数で管理
+	ImageURL    string `json:"imageUrl"`
+	Stock       int    `json:"stock"`
+	Reserved    int    `json:"reserved"` // 決済待ちの注文で引き当て済みの数量
+	// 在庫数がこの値以下になると在庫僅少として通知する（0 の場合は LOW_STOCK_THRESHOLD を使う）
+	LowStockThreshold int       `json:"lowStockThreshold"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
 }
 
 func NewProduct(name, description string, price int, imageURL string, stock int) *Product {
@@ -31,14 +34,22 @@ func NewProduct(name, description string, price int, imageURL string, stock int)
 	}
 }
 
+// Clone は商品のコピーを返す
+func (p *Product) Clone() *Product {
+	clone := *p
+	return &clone
+}
+
 func (p *Product) UpdateStock(newStock int) {
 	p.Stock = newStock
 	p.UpdatedAt = time.Now()
 }
 
 func (p *Product) DecreaseStock(quantity int) error {
-	// SLMハンズオン用に在庫減少を無効化
-	// 在庫は減らさず、常に成功を返す
+	if p.Stock < quantity {
+		return ErrInsufficientStock
+	}
+	p.Stock -= quantity
 	p.UpdatedAt = time.Now()
 	return nil
 }
@@ -52,7 +63,95 @@ func (p *Product) IsInStock() bool {
 	return p.Stock > 0
 }
 
+// AvailableStock は引き当て済みの数量を除いた販売可能な在庫数を返す
+func (p *Product) AvailableStock() int {
+	return p.Stock - p.Reserved
+}
+
 func (p *Product) IsAvailable(quantity int) bool {
-	// SLMハンズオン用に在庫チェックを無効化
-	return true
+	return p.AvailableStock() >= quantity
+}
+
+// Reserve は注文の決済が終わるまで在庫を引き当てる
+func (p *Product) Reserve(quantity int) error {
+	if !p.IsAvailable(quantity) {
+		return ErrInsufficientStock
+	}
+	p.Reserved += quantity
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// CommitReservation は引き当て済みの在庫を出荷分として在庫から減らす
+func (p *Product) CommitReservation(quantity int) {
+	p.Reserved -= quantity
+	p.Stock -= quantity
+	if p.Reserved < 0 {
+		p.Reserved = 0
+	}
+	if p.Stock < 0 {
+		p.Stock = 0
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// ReleaseReservation は引き当てを取り消して在庫を販売可能に戻す
+func (p *Product) ReleaseReservation(quantity int) {
+	p.Reserved -= quantity
+	if p.Reserved < 0 {
+		p.Reserved = 0
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// CanAdjustStock は在庫数を delta だけ増減しても引き当て済みの数量を下回らないか確認する
+func (p *Product) CanAdjustStock(delta int) bool {
+	return p.Stock+delta >= p.Reserved
+}
+
+// AdjustStock は在庫数を delta だけ増減する（負の値で減算）
+func (p *Product) AdjustStock(delta int) error {
+	if !p.CanAdjustStock(delta) {
+		return ErrInsufficientStock
+	}
+	p.Stock += delta
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// StockSettings は在庫数・在庫僅少の閾値の設定（nil の項目は変更しない）
+type StockSettings struct {
+	Stock             *int
+	LowStockThreshold *int
+}
+
+// IsValid は少なくとも一方を指定し、どちらも0以上か確認する
+func (s StockSettings) IsValid() bool {
+	if s.Stock == nil && s.LowStockThreshold == nil {
+		return false
+	}
+	return (s.Stock == nil || *s.Stock >= 0) && (s.LowStockThreshold == nil || *s.LowStockThreshold >= 0)
+}
+
+// ApplyStockSettings は指定された在庫数・在庫僅少の閾値をまとめて設定する
+func (p *Product) ApplyStockSettings(settings StockSettings) {
+	if settings.Stock != nil {
+		p.Stock = *settings.Stock
+	}
+	if settings.LowStockThreshold != nil {
+		p.LowStockThreshold = *settings.LowStockThreshold
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// StockQuantity は在庫操作の対象商品と数量
+type StockQuantity struct {
+	ProductID string
+	Quantity  int
+}
+
+// StockChange は在庫数の増減（Delta が負の場合は減算）
+type StockChange struct {
+	ProductID string
+	Delta     int
 }
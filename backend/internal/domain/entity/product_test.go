@@ -84,43 +84,50 @@ func TestProduct_UpdateStock(t *testing.T) {
 }
 
 func TestProduct_DecreaseStock(t *testing.T) {
-	// SLMハンズオン用に在庫減少が無効化されたため、常に成功することを確認
 	tests := []struct {
-		name         string
-		initialStock int
-		decreaseBy   int
+		name          string
+		initialStock  int
+		decreaseBy    int
+		expectedStock int
+		expectedErr   error
 	}{
 		{
-			name:         "在庫管理無効化確認1",
-			initialStock: 10,
-			decreaseBy:   3,
+			name:          "在庫を減らす",
+			initialStock:  10,
+			decreaseBy:    3,
+			expectedStock: 7,
+		},
+		{
+			name:          "在庫をすべて減らす",
+			initialStock:  5,
+			decreaseBy:    5,
+			expectedStock: 0,
 		},
 		{
-			name:         "在庫管理無効化確認2",
-			initialStock: 0,
-			decreaseBy:   5,
+			name:          "在庫不足",
+			initialStock:  0,
+			decreaseBy:    5,
+			expectedStock: 0,
+			expectedErr:   ErrInsufficientStock,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			product := NewProduct("テスト商品", "説明", 1000, "image.jpg", tt.initialStock)
-			originalStock := product.Stock
 			originalUpdatedAt := product.UpdatedAt
 
 			time.Sleep(10 * time.Millisecond)
 			err := product.DecreaseStock(tt.decreaseBy)
 
-			// SLMハンズオン用に在庫減少は無効化されているため、常に成功する
-			if err != nil {
-				t.Errorf("Unexpected error: %v", err)
+			if err != tt.expectedErr {
+				t.Errorf("DecreaseStock() error = %v, want %v", err, tt.expectedErr)
 			}
-			// 在庫は変更されないことを確認
-			if product.Stock != originalStock {
-				t.Errorf("Stock should not change: got %v, want %v", product.Stock, originalStock)
+			if product.Stock != tt.expectedStock {
+				t.Errorf("Stock = %v, want %v", product.Stock, tt.expectedStock)
 			}
-			// UpdatedAtは更新されることを確認
-			if !product.UpdatedAt.After(originalUpdatedAt) {
+			// 成功した場合のみ更新日時が更新される
+			if tt.expectedErr == nil && !product.UpdatedAt.After(originalUpdatedAt) {
 				t.Error("更新日時が更新されていません")
 			}
 		})
@@ -176,35 +183,115 @@ func TestProduct_IsInStock(t *testing.T) {
 }
 
 func TestProduct_IsAvailable(t *testing.T) {
-	// SLMハンズオン用に在庫チェックが無効化されたため、常にtrueを返すことを確認
 	tests := []struct {
 		name         string
 		stock        int
+		reserved     int
 		requestedQty int
+		expected     bool
 	}{
 		{
-			name:         "在庫チェック無効化確認1",
+			name:         "在庫あり",
 			stock:        10,
 			requestedQty: 5,
+			expected:     true,
 		},
 		{
-			name:         "在庫チェック無効化確認2",
+			name:         "在庫なし",
 			stock:        0,
 			requestedQty: 1,
+			expected:     false,
 		},
 		{
-			name:         "在庫チェック無効化確認3",
+			name:         "在庫より多い数量",
 			stock:        3,
 			requestedQty: 100,
+			expected:     false,
+		},
+		{
+			name:         "引き当て済みの数量は販売できない",
+			stock:        5,
+			reserved:     3,
+			requestedQty: 3,
+			expected:     false,
+		},
+		{
+			name:         "引き当て済みを除いた在庫ちょうど",
+			stock:        5,
+			reserved:     3,
+			requestedQty: 2,
+			expected:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			product := NewProduct("テスト商品", "説明", 1000, "image.jpg", tt.stock)
-			// SLMハンズオン用に在庫チェックが無効化されているため、常にtrueを返す
-			if result := product.IsAvailable(tt.requestedQty); result != true {
-				t.Errorf("IsAvailable(%d) = %v, want %v", tt.requestedQty, result, true)
+			product.Reserved = tt.reserved
+			if result := product.IsAvailable(tt.requestedQty); result != tt.expected {
+				t.Errorf("IsAvailable(%d) = %v, want %v", tt.requestedQty, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProduct_Reservation(t *testing.T) {
+	product := NewProduct("テスト商品", "説明", 1000, "image.jpg", 5)
+
+	if err := product.Reserve(3); err != nil {
+		t.Fatalf("Reserve(3) error = %v", err)
+	}
+	if err := product.Reserve(3); err != ErrInsufficientStock {
+		t.Errorf("Reserve(3) error = %v, want %v", err, ErrInsufficientStock)
+	}
+	if product.AvailableStock() != 2 {
+		t.Errorf("AvailableStock() = %v, want 2", product.AvailableStock())
+	}
+
+	// 確定すると在庫と引き当て済みの数量が減る
+	product.CommitReservation(2)
+	if product.Stock != 3 || product.Reserved != 1 {
+		t.Errorf("確定後 Stock = %v, Reserved = %v, want 3, 1", product.Stock, product.Reserved)
+	}
+
+	// 解放すると在庫は変わらず販売可能に戻る
+	product.ReleaseReservation(1)
+	if product.Stock != 3 || product.Reserved != 0 {
+		t.Errorf("解放後 Stock = %v, Reserved = %v, want 3, 0", product.Stock, product.Reserved)
+	}
+
+	// 引き当て済みの数量を超えて解放しても負にならない
+	product.ReleaseReservation(5)
+	if product.Reserved != 0 {
+		t.Errorf("Reserved = %v, want 0", product.Reserved)
+	}
+}
+
+func TestProduct_AdjustStock(t *testing.T) {
+	tests := []struct {
+		name          string
+		stock         int
+		reserved      int
+		delta         int
+		expectedStock int
+		expectedErr   error
+	}{
+		{name: "入荷で増やす", stock: 5, delta: 10, expectedStock: 15},
+		{name: "減らす", stock: 5, delta: -5, expectedStock: 0},
+		{name: "在庫数を下回る", stock: 5, delta: -6, expectedStock: 5, expectedErr: ErrInsufficientStock},
+		{name: "引き当て済みの数量を下回る", stock: 5, reserved: 3, delta: -3, expectedStock: 5, expectedErr: ErrInsufficientStock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := NewProduct("テスト商品", "説明", 1000, "image.jpg", tt.stock)
+			product.Reserved = tt.reserved
+
+			if err := product.AdjustStock(tt.delta); err != tt.expectedErr {
+				t.Errorf("AdjustStock(%d) error = %v, want %v", tt.delta, err, tt.expectedErr)
+			}
+			if product.Stock != tt.expectedStock {
+				t.Errorf("Stock = %v, want %v", product.Stock, tt.expectedStock)
 			}
 		})
 	}
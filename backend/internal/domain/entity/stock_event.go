@@ -0,0 +1,83 @@
+package entity
+
+import "time"
+
+// StockLevel は閾値に対する在庫の状態（値が大きいほど在庫が多い）
+type StockLevel int
+
+const (
+	StockLevelOut StockLevel = iota // 在庫切れ
+	StockLevelLow                   // 在庫僅少（閾値以下）
+	StockLevelIn                    // 在庫あり
+)
+
+// StockLevelOf は在庫数と在庫僅少の閾値から在庫の状態を決定する
+func StockLevelOf(stock, threshold int) StockLevel {
+	switch {
+	case stock <= 0:
+		return StockLevelOut
+	case stock <= threshold:
+		return StockLevelLow
+	default:
+		return StockLevelIn
+	}
+}
+
+func (l StockLevel) String() string {
+	switch l {
+	case StockLevelOut:
+		return "out_of_stock"
+	case StockLevelLow:
+		return "low_stock"
+	default:
+		return "in_stock"
+	}
+}
+
+// 在庫イベントの種類
+const (
+	StockEventLowStock   = "LowStock"   // 閾値以下になった
+	StockEventOutOfStock = "OutOfStock" // 在庫切れになった
+	StockEventRestocked  = "Restocked"  // 在庫切れ・在庫僅少から回復した
+)
+
+// StockEvent は販売可能な在庫数（引き当て済みの数量を除く）が閾値をまたいで変化したことの通知
+type StockEvent struct {
+	Type          string    `json:"type"`
+	ProductID     string    `json:"productId"`
+	ProductName   string    `json:"productName"`
+	PreviousStock int       `json:"previousStock"` // 変更前の販売可能な在庫数
+	Stock         int       `json:"stock"`         // 変更後の販売可能な在庫数
+	Threshold     int       `json:"threshold"`
+	Level         string    `json:"level"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
+// NewStockEvent は販売可能な在庫数の変化が閾値をまたいだ場合にイベントを返す（またがない場合は false）
+func NewStockEvent(product *Product, previousStock, previousThreshold, threshold int, now time.Time) (StockEvent, bool) {
+	previous := StockLevelOf(previousStock, previousThreshold)
+	current := StockLevelOf(product.AvailableStock(), threshold)
+
+	var eventType string
+	switch {
+	case current == previous:
+		return StockEvent{}, false
+	case current == StockLevelOut:
+		eventType = StockEventOutOfStock
+	case current < previous:
+		eventType = StockEventLowStock
+	default:
+		eventType = StockEventRestocked
+	}
+
+	return StockEvent{
+		Type:          eventType,
+		ProductID:     product.ID,
+		ProductName:   product.Name,
+		PreviousStock: previousStock,
+		Stock:         product.AvailableStock(),
+		Threshold:     threshold,
+		Level:         current.String(),
+		OccurredAt:    now,
+	}, true
+}
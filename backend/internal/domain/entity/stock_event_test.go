@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStockLevelOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		stock     int
+		threshold int
+		expected  StockLevel
+	}{
+		{name: "在庫切れ", stock: 0, threshold: 10, expected: StockLevelOut},
+		{name: "閾値ちょうどは在庫僅少", stock: 10, threshold: 10, expected: StockLevelLow},
+		{name: "閾値より多い", stock: 11, threshold: 10, expected: StockLevelIn},
+		{name: "閾値0は在庫切れのみ", stock: 1, threshold: 0, expected: StockLevelIn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if level := StockLevelOf(tt.stock, tt.threshold); level != tt.expected {
+				t.Errorf("StockLevelOf(%d, %d) = %v, want %v", tt.stock, tt.threshold, level, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewStockEvent(t *testing.T) {
+	tests := []struct {
+		name              string
+		previousStock     int
+		stock             int
+		reserved          int
+		previousThreshold int
+		threshold         int
+		expectedType      string
+	}{
+		{name: "閾値以下になった", previousStock: 11, stock: 10, previousThreshold: 10, threshold: 10, expectedType: StockEventLowStock},
+		{name: "在庫ありから在庫切れ", previousStock: 50, stock: 0, previousThreshold: 10, threshold: 10, expectedType: StockEventOutOfStock},
+		{name: "在庫僅少から在庫切れ", previousStock: 3, stock: 0, previousThreshold: 10, threshold: 10, expectedType: StockEventOutOfStock},
+		{name: "在庫切れから入荷", previousStock: 0, stock: 5, previousThreshold: 10, threshold: 10, expectedType: StockEventRestocked},
+		{name: "在庫僅少から回復", previousStock: 5, stock: 20, previousThreshold: 10, threshold: 10, expectedType: StockEventRestocked},
+		{name: "閾値をまたがない減少", previousStock: 50, stock: 40, previousThreshold: 10, threshold: 10},
+		{name: "在庫僅少のままの減少", previousStock: 5, stock: 4, previousThreshold: 10, threshold: 10},
+		{name: "引き当てで販売可能な在庫が閾値以下になった", previousStock: 20, stock: 20, reserved: 12, previousThreshold: 10, threshold: 10, expectedType: StockEventLowStock},
+		{name: "全て引き当て済みは在庫切れ", previousStock: 5, stock: 5, reserved: 5, previousThreshold: 10, threshold: 10, expectedType: StockEventOutOfStock},
+	}
+
+	now := time.Date(2025, 7, 30, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := NewProduct("テスト商品", "説明", 1000, "image.jpg", tt.stock)
+			product.Reserved = tt.reserved
+
+			event, crossed := NewStockEvent(product, tt.previousStock, tt.previousThreshold, tt.threshold, now)
+
+			if crossed != (tt.expectedType != "") {
+				t.Fatalf("crossed = %v, want %v", crossed, tt.expectedType != "")
+			}
+			if !crossed {
+				return
+			}
+			if event.Type != tt.expectedType {
+				t.Errorf("Type = %v, want %v", event.Type, tt.expectedType)
+			}
+			if event.ProductID != product.ID || event.PreviousStock != tt.previousStock || event.Stock != tt.stock-tt.reserved || !event.OccurredAt.Equal(now) {
+				t.Errorf("event = %+v", event)
+			}
+		})
+	}
+}
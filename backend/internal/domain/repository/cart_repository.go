@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 )
@@ -12,4 +13,6 @@ type CartRepository interface {
 	Save(ctx context.Context, cart *entity.Cart) error
 	Delete(ctx context.Context, id string) error
 	Clear(ctx context.Context, id string) error
+	// DeleteExpired は最終更新が expiredBefore より前のカートを削除し、削除したカートを返す
+	DeleteExpired(ctx context.Context, expiredBefore time.Time) ([]*entity.Cart, error)
 }
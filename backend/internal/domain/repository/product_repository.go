@@ -13,6 +13,18 @@ type ProductRepository interface {
 	Update(ctx context.Context, product *entity.Product) error
 	Delete(ctx context.Context, id string) error
 	UpdateStock(ctx context.Context, id string, newStock int) error
+	// UpdateStockSettings は在庫数・在庫僅少の閾値をまとめて設定する（nil の項目は変更しない）
+	UpdateStockSettings(ctx context.Context, id string, settings entity.StockSettings) error
 	DecreaseStock(ctx context.Context, id string, quantity int) error
 	IncreaseStock(ctx context.Context, id string, quantity int) error
+	// ReserveStock は全ての商品の在庫を引き当てる。1件でも在庫が不足する場合は何も引き当てずに ErrInsufficientStock を返す
+	ReserveStock(ctx context.Context, items []entity.StockQuantity) error
+	// CommitStock は引き当て済みの在庫を在庫数から減らす
+	CommitStock(ctx context.Context, items []entity.StockQuantity) error
+	// ReleaseStock は引き当てを取り消す
+	ReleaseStock(ctx context.Context, items []entity.StockQuantity) error
+	// AdjustStock は複数商品の在庫数をまとめて増減する。1件でも適用できない場合は何も変更せずにエラーを返す
+	// （存在しない商品は ErrProductNotFound、引き当て済みの数量を下回る場合は ErrInsufficientStock）
+	// 永続化するバックエンドでは1つのトランザクションで適用する
+	AdjustStock(ctx context.Context, changes []entity.StockChange) error
 }
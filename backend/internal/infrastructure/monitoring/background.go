@@ -0,0 +1,79 @@
+package monitoring
+
+import (
+	"context"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// StartBackgroundTransaction は New Relic のバックグラウンドトランザクションを開始してコンテキストに設定する
+// リクエスト外の処理（決済キューのジョブ等）の NoticeError・AddAttributes はこのトランザクションに記録される
+// New Relic が無効の場合はコンテキストをそのまま返す
+func StartBackgroundTransaction(ctx context.Context, t Telemetry, name string) (context.Context, func()) {
+	app := NewRelicApplication(t)
+	if app == nil {
+		return ctx, func() {}
+	}
+
+	txn := app.StartTransaction(name)
+	return newrelic.NewContext(ctx, txn), txn.End
+}
+
+// PaymentQueueMonitor は決済キューの滞留数とジョブの実行をテレメトリと Prometheus に記録する
+type PaymentQueueMonitor struct {
+	telemetry Telemetry
+	exporter  *PrometheusExporter
+}
+
+func NewPaymentQueueMonitor(telemetry Telemetry, exporter *PrometheusExporter) *PaymentQueueMonitor {
+	return &PaymentQueueMonitor{
+		telemetry: telemetry,
+		exporter:  exporter,
+	}
+}
+
+// StartJob はジョブごとのバックグラウンドトランザクション（PaymentJob）を開始する
+func (m *PaymentQueueMonitor) StartJob(ctx context.Context, orderID string, wait time.Duration) (context.Context, func()) {
+	ctx, end := StartBackgroundTransaction(ctx, m.telemetry, "PaymentJob")
+	m.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"order.id":          orderID,
+		"paymentQueue.wait": wait.Milliseconds(),
+	})
+	m.telemetry.RecordMetric(ctx, "Custom/PaymentQueue/WaitTime", wait.Seconds())
+	if m.exporter != nil {
+		m.exporter.ObservePaymentQueueWait(wait)
+	}
+	return ctx, end
+}
+
+// ObserveDepth はキューで待機中のジョブ数・実行中のワーカー数・デッドレターの件数を記録する
+func (m *PaymentQueueMonitor) ObserveDepth(ctx context.Context, depth int, busy int, deadLetters int) {
+	m.telemetry.RecordMetric(ctx, "Custom/PaymentQueue/Depth", float64(depth))
+	m.telemetry.RecordMetric(ctx, "Custom/PaymentQueue/BusyWorkers", float64(busy))
+	m.telemetry.RecordMetric(ctx, "Custom/PaymentQueue/DeadLetters", float64(deadLetters))
+	if m.exporter != nil {
+		m.exporter.ObservePaymentQueue(depth, busy, deadLetters)
+	}
+}
+
+// ObserveRetry は一時的な障害で失敗した決済ジョブの再試行を記録する
+func (m *PaymentQueueMonitor) ObserveRetry(ctx context.Context, orderID string, attempt int, backoff time.Duration, err error) {
+	m.telemetry.RecordMetric(ctx, "Custom/PaymentQueue/Retry", 1)
+	if m.exporter != nil {
+		m.exporter.ObservePaymentRetry()
+	}
+}
+
+// ObserveDeadLetter は再試行を使い切った決済ジョブを PaymentDeadLetter イベントとして記録する
+func (m *PaymentQueueMonitor) ObserveDeadLetter(ctx context.Context, orderID string, attempts int, err error) {
+	m.telemetry.RecordEvent(ctx, EventPaymentDeadLetter, map[string]interface{}{
+		"orderId":    orderID,
+		"attempts":   attempts,
+		"error":      err.Error(),
+		"errorClass": ClassifyError(err).Class,
+	})
+	if m.exporter != nil {
+		m.exporter.ObservePaymentDeadLetter()
+	}
+}
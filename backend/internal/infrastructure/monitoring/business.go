@@ -0,0 +1,153 @@
+package monitoring
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/slo"
+)
+
+// ビジネスイベントの種類
+const (
+	EventProductView             = "ProductView"
+	EventAddToCart               = "AddToCart"
+	EventPurchase                = "Purchase"
+	EventPayment                 = "Payment"
+	EventPaymentDeadLetter       = "PaymentDeadLetter"
+	EventAbandonedCart           = "AbandonedCart"
+	EventLowStock                = entity.StockEventLowStock
+	EventOutOfStock              = entity.StockEventOutOfStock
+	EventRestocked               = entity.StockEventRestocked
+	EventApplicationError        = "ApplicationError"
+	EventFaultScenarioTransition = "FaultScenarioTransition"
+	EventSLOBurnRateAlert        = "SLOBurnRateAlert"
+)
+
+// ビジネスメトリクス記録用のヘルパー関数
+func RecordProductView(ctx context.Context, t Telemetry, productID string, userID string) {
+	t.RecordEvent(ctx, EventProductView, map[string]interface{}{
+		"productId": productID,
+		"userId":    userID,
+	})
+}
+
+func RecordAddToCart(ctx context.Context, t Telemetry, productID string, quantity int, userID string) {
+	t.RecordEvent(ctx, EventAddToCart, map[string]interface{}{
+		"productId": productID,
+		"quantity":  quantity,
+		"userId":    userID,
+	})
+}
+
+func RecordPurchase(ctx context.Context, t Telemetry, orderID string, amount float64, itemCount int, userID string) {
+	t.RecordEvent(ctx, EventPurchase, map[string]interface{}{
+		"orderId":   orderID,
+		"amount":    amount,
+		"itemCount": itemCount,
+		"userId":    userID,
+	})
+
+	// 売上メトリクスも記録
+	t.RecordMetric(ctx, "Custom/Revenue", amount)
+	t.RecordMetric(ctx, "Custom/OrderCount", 1)
+}
+
+// 注文に反映した決済の結果を記録（決済成功率SLI用）
+func RecordPayment(ctx context.Context, t Telemetry, order *entity.Order, success bool, duration time.Duration) {
+	t.RecordEvent(ctx, EventPayment, map[string]interface{}{
+		"orderId":           order.ID,
+		"success":           success,
+		"status":            string(order.Payment.Status),
+		"providerReference": order.Payment.ProviderReference,
+		"declineCode":       order.Payment.DeclineCode,
+		"failureReason":     order.Payment.FailureReason,
+		"attempts":          order.Payment.Attempts,
+		"amount":            float64(order.TotalAmount),
+		"durationMs":        duration.Milliseconds(),
+	})
+
+	if success {
+		t.RecordMetric(ctx, "Custom/Payment/Success", 1)
+	} else {
+		t.RecordMetric(ctx, "Custom/Payment/Failure", 1)
+	}
+	t.RecordMetric(ctx, "Custom/Payment/Duration", duration.Seconds())
+}
+
+// 商品が入ったまま期限切れになったカートを記録（カートから購入への転換率SLI用）
+func RecordAbandonedCart(ctx context.Context, t Telemetry, cart *entity.Cart, age, idle time.Duration) {
+	t.RecordEvent(ctx, EventAbandonedCart, map[string]interface{}{
+		"cartId":      cart.ID,
+		"itemCount":   cart.GetItemCount(),
+		"uniqueItems": len(cart.Items),
+		"value":       float64(cart.TotalAmount),
+		"ageSeconds":  age.Seconds(),
+		"idleSeconds": idle.Seconds(),
+	})
+
+	t.RecordMetric(ctx, "Custom/AbandonedCartValue", float64(cart.TotalAmount))
+	t.RecordMetric(ctx, "Custom/AbandonedCartCount", 1)
+}
+
+// 在庫数が閾値をまたいだことを記録（LowStock / OutOfStock / Restocked、カタログの健全性SLI用）
+func RecordStockEvent(ctx context.Context, t Telemetry, event entity.StockEvent) {
+	t.RecordEvent(ctx, event.Type, map[string]interface{}{
+		"productId":     event.ProductID,
+		"productName":   event.ProductName,
+		"previousStock": event.PreviousStock,
+		"stock":         event.Stock,
+		"threshold":     event.Threshold,
+		"level":         event.Level,
+	})
+
+	t.RecordMetric(ctx, "Custom/Stock/"+event.Type, 1)
+}
+
+func RecordError(ctx context.Context, t Telemetry, errorType string, message string, context map[string]interface{}) {
+	t.RecordEvent(ctx, EventApplicationError, map[string]interface{}{
+		"errorType": errorType,
+		"message":   message,
+		"context":   context,
+	})
+}
+
+// 障害シナリオのステップ遷移を記録（SLOの変化とインシデントの対応付け用）
+func RecordFaultScenarioTransition(ctx context.Context, t Telemetry, transition fault.Transition) {
+	routes := make([]string, 0, len(transition.Profiles))
+	for route := range transition.Profiles {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	for _, route := range routes {
+		profile := transition.Profiles[route]
+		t.RecordEvent(ctx, EventFaultScenarioTransition, map[string]interface{}{
+			"scenario":   transition.Scenario,
+			"step":       transition.Step,
+			"stepIndex":  transition.Index,
+			"stepCount":  transition.Total,
+			"recovery":   transition.Recovery,
+			"route":      route,
+			"errorRate":  profile.ErrorRate,
+			"statusCode": profile.StatusCode,
+		})
+	}
+}
+
+// バーンレートアラートの発報・解消を記録（New Relic のアラートポリシーとの比較用）
+func RecordSLOAlert(ctx context.Context, t Telemetry, alert slo.Alert) {
+	t.RecordEvent(ctx, EventSLOBurnRateAlert, map[string]interface{}{
+		"rule":          alert.Rule.Name,
+		"objective":     alert.Rule.Objective,
+		"severity":      alert.Rule.Severity,
+		"state":         alert.State,
+		"threshold":     alert.Rule.BurnRate,
+		"longWindow":    alert.Rule.LongWindow.String(),
+		"shortWindow":   alert.Rule.ShortWindow.String(),
+		"longBurnRate":  alert.LongBurnRate,
+		"shortBurnRate": alert.ShortBurnRate,
+	})
+}
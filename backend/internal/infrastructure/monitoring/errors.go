@@ -0,0 +1,81 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+)
+
+// エラークラス（ドメインエラーに該当しない場合）
+const (
+	ErrorClassPanic    = "Panic"
+	ErrorClassInternal = "InternalError"
+)
+
+// ErrorClassification はエラーの集計単位と、想定内（クライアント起因）のエラーかどうか
+// 想定内のエラーはエラー率SLIに含めないよう、New Relic では expected error として報告する
+type ErrorClassification struct {
+	Class    string
+	Expected bool
+}
+
+// ドメインエラーとエラークラスの対応
+var domainErrors = []struct {
+	err            error
+	classification ErrorClassification
+}{
+	{entity.ErrProductNotFound, ErrorClassification{Class: "ProductNotFound", Expected: true}},
+	{entity.ErrInsufficientStock, ErrorClassification{Class: "InsufficientStock", Expected: true}},
+	{entity.ErrItemNotFound, ErrorClassification{Class: "ItemNotFound", Expected: true}},
+	{entity.ErrEmptyCart, ErrorClassification{Class: "EmptyCart", Expected: true}},
+	{entity.ErrPriceChanged, ErrorClassification{Class: "PriceChanged", Expected: true}},
+	{entity.ErrOrderNotFound, ErrorClassification{Class: "OrderNotFound", Expected: true}},
+	{entity.ErrInvalidOrderStatus, ErrorClassification{Class: "InvalidOrderStatus", Expected: true}},
+	{entity.ErrInvalidInput, ErrorClassification{Class: "InvalidInput", Expected: true}},
+	// 決済ゲートウェイの障害は外部依存の障害としてエラー率に含める
+	{entity.ErrPaymentUnavailable, ErrorClassification{Class: "PaymentUnavailable"}},
+}
+
+// ClassifyError はラップされたエラーも含めてドメインエラーからエラークラスを決定する
+func ClassifyError(err error) ErrorClassification {
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		return ErrorClassification{Class: ErrorClassPanic}
+	}
+
+	for _, domain := range domainErrors {
+		if errors.Is(err, domain.err) {
+			return domain.classification
+		}
+	}
+	return ErrorClassification{Class: ErrorClassInternal}
+}
+
+// PanicError はリカバリーしたパニックの値とスタックトレース
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// NewPanicError はパニックの値を任意の型のまま保持し、呼び出し時点のスタックトレースを記録する
+// recover した deferred 関数内で呼び出すとパニック発生箇所のスタックが含まれる
+func NewPanicError(value interface{}) *PanicError {
+	return &PanicError{
+		Value: value,
+		Stack: debug.Stack(),
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap はパニックの値が error の場合にそのエラーを返す
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
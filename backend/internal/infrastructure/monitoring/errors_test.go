@@ -0,0 +1,51 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorClassification
+	}{
+		{name: "空のカートは想定内", err: entity.ErrEmptyCart, expected: ErrorClassification{Class: "EmptyCart", Expected: true}},
+		{name: "ラップされたドメインエラー", err: fmt.Errorf("failed to get product: %w", entity.ErrProductNotFound), expected: ErrorClassification{Class: "ProductNotFound", Expected: true}},
+		{name: "決済ゲートウェイの障害は想定外", err: fmt.Errorf("failed to authorize payment: %w", entity.ErrPaymentUnavailable), expected: ErrorClassification{Class: "PaymentUnavailable"}},
+		{name: "ドメインエラー以外は想定外", err: errors.New("connection refused"), expected: ErrorClassification{Class: ErrorClassInternal}},
+		{name: "パニックは想定外", err: NewPanicError(42), expected: ErrorClassification{Class: ErrorClassPanic}},
+		{name: "ドメインエラーのパニックもパニックとして分類", err: NewPanicError(entity.ErrOrderNotFound), expected: ErrorClassification{Class: ErrorClassPanic}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.expected {
+				t.Errorf("ClassifyError() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	cause := errors.New("nil map")
+	panicErr := NewPanicError(cause)
+
+	if panicErr.Error() != "panic: nil map" {
+		t.Errorf("Error() = %v, want panic: nil map", panicErr.Error())
+	}
+	if !errors.Is(panicErr, cause) {
+		t.Error("パニックの値のエラーを Unwrap できない")
+	}
+	if !strings.Contains(string(panicErr.Stack), "TestPanicError") {
+		t.Errorf("スタックトレースに呼び出し元が含まれない: %s", panicErr.Stack)
+	}
+	if NewPanicError("boom").Unwrap() != nil {
+		t.Error("エラー以外のパニックの Unwrap は nil")
+	}
+}
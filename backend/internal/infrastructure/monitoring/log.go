@@ -0,0 +1,41 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// LogTelemetry は全ての記録をログに出力する（外部サービスなしでの確認用）
+type LogTelemetry struct{}
+
+func NewLogTelemetry() *LogTelemetry {
+	log.Println("Telemetry backend: log")
+	return &LogTelemetry{}
+}
+
+func (l *LogTelemetry) RecordEvent(ctx context.Context, eventType string, attributes map[string]interface{}) {
+	log.Printf("telemetry event: %s %v", eventType, attributes)
+}
+
+func (l *LogTelemetry) RecordMetric(ctx context.Context, name string, value float64) {
+	log.Printf("telemetry metric: %s=%v", name, value)
+}
+
+func (l *LogTelemetry) NoticeError(ctx context.Context, err error) {
+	classification := ClassifyError(err)
+	log.Printf("telemetry error: [%s] expected=%t %v", classification.Class, classification.Expected, err)
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		log.Printf("telemetry panic stack:\n%s", panicErr.Stack)
+	}
+}
+
+func (l *LogTelemetry) AddAttributes(ctx context.Context, attributes map[string]interface{}) {
+	log.Printf("telemetry attributes: %v", attributes)
+}
+
+func (l *LogTelemetry) Shutdown(ctx context.Context) error {
+	return nil
+}
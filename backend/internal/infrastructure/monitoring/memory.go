@@ -0,0 +1,115 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+)
+
+// RecordedEvent は MemoryTelemetry に記録されたイベント
+type RecordedEvent struct {
+	Type       string
+	Attributes map[string]interface{}
+}
+
+// RecordedMetric は MemoryTelemetry に記録されたメトリクス
+type RecordedMetric struct {
+	Name  string
+	Value float64
+}
+
+// MemoryTelemetry は記録内容をメモリに保持する（テストでのアサーション用、並行アクセス安全）
+type MemoryTelemetry struct {
+	events     []RecordedEvent
+	metrics    []RecordedMetric
+	errors     []error
+	attributes map[string]interface{}
+	mutex      sync.RWMutex
+}
+
+func NewMemoryTelemetry() *MemoryTelemetry {
+	return &MemoryTelemetry{
+		attributes: make(map[string]interface{}),
+	}
+}
+
+func (m *MemoryTelemetry) RecordEvent(ctx context.Context, eventType string, attributes map[string]interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.events = append(m.events, RecordedEvent{Type: eventType, Attributes: attributes})
+}
+
+func (m *MemoryTelemetry) RecordMetric(ctx context.Context, name string, value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.metrics = append(m.metrics, RecordedMetric{Name: name, Value: value})
+}
+
+func (m *MemoryTelemetry) NoticeError(ctx context.Context, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.errors = append(m.errors, err)
+}
+
+func (m *MemoryTelemetry) AddAttributes(ctx context.Context, attributes map[string]interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for key, value := range attributes {
+		m.attributes[key] = value
+	}
+}
+
+func (m *MemoryTelemetry) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Events は指定した種類のイベントを記録順に返す（空の場合は全て）
+func (m *MemoryTelemetry) Events(eventType string) []RecordedEvent {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	events := []RecordedEvent{}
+	for _, event := range m.events {
+		if eventType == "" || event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Metrics は指定した名前のメトリクスを記録順に返す（空の場合は全て）
+func (m *MemoryTelemetry) Metrics(name string) []RecordedMetric {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	metrics := []RecordedMetric{}
+	for _, metric := range m.metrics {
+		if name == "" || metric.Name == name {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+func (m *MemoryTelemetry) Errors() []error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return append([]error(nil), m.errors...)
+}
+
+// Attribute は追加された属性の最新の値を返す
+func (m *MemoryTelemetry) Attribute(key string) (interface{}, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	value, exists := m.attributes[key]
+	return value, exists
+}
+
+// Reset は記録内容を消去する
+func (m *MemoryTelemetry) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.events = nil
+	m.metrics = nil
+	m.errors = nil
+	m.attributes = make(map[string]interface{})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/newrelic/go-agent/v3/newrelic"
 )
@@ -56,68 +57,69 @@ func (nr *NewRelicClient) StartWebTransaction(name string) *newrelic.Transaction
 	return nr.app.StartTransaction(name)
 }
 
-func (nr *NewRelicClient) RecordCustomEvent(eventType string, params map[string]interface{}) {
+func (nr *NewRelicClient) RecordEvent(ctx context.Context, eventType string, attributes map[string]interface{}) {
 	if nr.app == nil {
 		return
 	}
-	nr.app.RecordCustomEvent(eventType, params)
+	nr.app.RecordCustomEvent(eventType, attributes)
 }
 
-func (nr *NewRelicClient) RecordCustomMetric(name string, value float64) {
+func (nr *NewRelicClient) RecordMetric(ctx context.Context, name string, value float64) {
 	if nr.app == nil {
 		return
 	}
 	nr.app.RecordCustomMetric(name, value)
 }
 
-func (nr *NewRelicClient) NoticeError(err error) {
-	if nr.app == nil {
+// NoticeError はリクエストのトランザクションにエラーを報告する
+// 想定内のエラーは expected error としてエラー率に含めない
+func (nr *NewRelicClient) NoticeError(ctx context.Context, err error) {
+	txn := newrelic.FromContext(ctx)
+	if txn == nil {
+		// トランザクション外（バックグラウンド処理等）のエラーはログのみ
+		if nr.app != nil {
+			log.Printf("New Relic Error (no transaction): %v", err)
+		}
 		return
 	}
-	// New Relic v3では、エラーはトランザクション経由で報告される
-	// ここでは単純にログ出力のみ行う
-	log.Printf("New Relic Error: %v", err)
-}
 
-func (nr *NewRelicClient) GetApplication() *newrelic.Application {
-	return nr.app
-}
+	classification := ClassifyError(err)
+	nrErr := newrelic.Error{
+		Message: err.Error(),
+		Class:   classification.Class,
+		Attributes: map[string]interface{}{
+			"error.expected": classification.Expected,
+		},
+		// パニックの場合も recover した deferred 関数内で呼ばれるため発生箇所のスタックが含まれる
+		Stack: newrelic.NewStackTrace(),
+	}
 
-// ビジネスメトリクス記録用のヘルパー関数
-func (nr *NewRelicClient) RecordProductView(productID string, userID string) {
-	nr.RecordCustomEvent("ProductView", map[string]interface{}{
-		"productId": productID,
-		"userId":    userID,
-	})
+	if classification.Expected {
+		txn.NoticeExpectedError(nrErr)
+		return
+	}
+	txn.NoticeError(nrErr)
 }
 
-func (nr *NewRelicClient) RecordAddToCart(productID string, quantity int, userID string) {
-	nr.RecordCustomEvent("AddToCart", map[string]interface{}{
-		"productId": productID,
-		"quantity":  quantity,
-		"userId":    userID,
-	})
+func (nr *NewRelicClient) AddAttributes(ctx context.Context, attributes map[string]interface{}) {
+	AddCustomAttributes(newrelic.FromContext(ctx), attributes)
 }
 
-func (nr *NewRelicClient) RecordPurchase(orderID string, amount float64, itemCount int, userID string) {
-	nr.RecordCustomEvent("Purchase", map[string]interface{}{
-		"orderId":   orderID,
-		"amount":    amount,
-		"itemCount": itemCount,
-		"userId":    userID,
-	})
-
-	// 売上メトリクスも記録
-	nr.RecordCustomMetric("Custom/Revenue", amount)
-	nr.RecordCustomMetric("Custom/OrderCount", 1)
+func (nr *NewRelicClient) Shutdown(ctx context.Context) error {
+	if nr.app == nil {
+		return nil
+	}
+
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	nr.app.Shutdown(timeout)
+	return nil
 }
 
-func (nr *NewRelicClient) RecordError(errorType string, message string, context map[string]interface{}) {
-	nr.RecordCustomEvent("ApplicationError", map[string]interface{}{
-		"errorType": errorType,
-		"message":   message,
-		"context":   context,
-	})
+func (nr *NewRelicClient) GetApplication() *newrelic.Application {
+	return nr.app
 }
 
 // トランザクションにカスタム属性を追加するヘルパー
@@ -0,0 +1,184 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/NRUG-SRE/slm-handson/backend"
+
+// OTelTelemetry は OpenTelemetry API に記録する
+// イベント・エラー・属性はリクエストのスパンに、メトリクスはグローバルの MeterProvider に記録する
+type OTelTelemetry struct {
+	meter      metric.Meter
+	events     metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+	shutdowns  []func(context.Context) error
+	mutex      sync.Mutex
+}
+
+// SetupOTel は OTLP/HTTP でコレクターへ送信する TracerProvider・MeterProvider を作成し、
+// W3C Trace Context の伝播とともにグローバルに設定する
+func SetupOTel(ctx context.Context, endpoint, serviceName string) (*OTelTelemetry, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otel resource: %w", err)
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp trace exporter: %w", err)
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp metric exporter: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	log.Printf("OpenTelemetry initialized for service %s (OTLP endpoint: %s)", serviceName, endpoint)
+
+	telemetry := NewOTelTelemetry()
+	telemetry.shutdowns = []func(context.Context) error{tracerProvider.Shutdown, meterProvider.Shutdown}
+	return telemetry, nil
+}
+
+// NewOTelTelemetry はグローバルに設定済みの Provider に記録する OTelTelemetry を作成する
+func NewOTelTelemetry() *OTelTelemetry {
+	meter := otel.Meter(instrumentationName)
+
+	events, err := meter.Int64Counter("slm.events",
+		metric.WithDescription("Number of business events by type."))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &OTelTelemetry{
+		meter:      meter,
+		events:     events,
+		histograms: make(map[string]metric.Float64Histogram),
+	}
+}
+
+func (o *OTelTelemetry) RecordEvent(ctx context.Context, eventType string, attributes map[string]interface{}) {
+	trace.SpanFromContext(ctx).AddEvent(eventType, trace.WithAttributes(toAttributes(attributes)...))
+
+	if o.events != nil {
+		o.events.Add(ctx, 1, metric.WithAttributes(attribute.String("event.type", eventType)))
+	}
+}
+
+func (o *OTelTelemetry) RecordMetric(ctx context.Context, name string, value float64) {
+	histogram := o.histogram(name)
+	if histogram != nil {
+		histogram.Record(ctx, value)
+	}
+}
+
+// NoticeError はリクエストのスパンに例外イベントを記録する
+// 想定内のエラーはスパンのステータスをエラーにしない
+func (o *OTelTelemetry) NoticeError(ctx context.Context, err error) {
+	classification := ClassifyError(err)
+	attributes := []attribute.KeyValue{
+		attribute.String("error.type", classification.Class),
+		attribute.Bool("error.expected", classification.Expected),
+	}
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		attributes = append(attributes, attribute.String("exception.stacktrace", string(panicErr.Stack)))
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err, trace.WithAttributes(attributes...))
+	if !classification.Expected {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func (o *OTelTelemetry) AddAttributes(ctx context.Context, attributes map[string]interface{}) {
+	trace.SpanFromContext(ctx).SetAttributes(toAttributes(attributes)...)
+}
+
+// Shutdown はバッファ中のスパン・メトリクスをコレクターへ送信して終了する
+func (o *OTelTelemetry) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, shutdown := range o.shutdowns {
+		if err := shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// histogram はメトリクス名ごとの Histogram を作成済みのものから返す
+func (o *OTelTelemetry) histogram(name string) metric.Float64Histogram {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if histogram, exists := o.histograms[name]; exists {
+		return histogram
+	}
+
+	histogram, err := o.meter.Float64Histogram(name)
+	if err != nil {
+		otel.Handle(err)
+		return nil
+	}
+	o.histograms[name] = histogram
+	return histogram
+}
+
+func toAttributes(attributes map[string]interface{}) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attributes))
+	for key, value := range attributes {
+		switch v := value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(key, v))
+		default:
+			kvs = append(kvs, attribute.String(key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
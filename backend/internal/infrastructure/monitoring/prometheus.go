@@ -0,0 +1,304 @@
+package monitoring
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
+)
+
+const metricsNamespace = "slm"
+
+// PrometheusExporter は /metrics で公開するメトリクス（New Relic なしでもローカルの Prometheus/Grafana で観測可能）
+// Telemetry として他のバックエンドと併用し、ビジネスイベントをカウンターとして記録する
+type PrometheusExporter struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	productViews   prometheus.Counter
+	addToCart      prometheus.Counter
+	addToCartItems prometheus.Counter
+	purchases      prometheus.Counter
+	purchaseItems  prometheus.Counter
+	revenue        prometheus.Counter
+
+	abandonedCarts     prometheus.Counter
+	abandonedCartValue prometheus.Counter
+
+	stockEvents *prometheus.CounterVec
+
+	payments *prometheus.CounterVec
+
+	paymentQueueDepth    prometheus.Gauge
+	paymentWorkersBusy   prometheus.Gauge
+	paymentQueueWait     prometheus.Histogram
+	paymentRetries       prometheus.Counter
+	paymentDeadLetters   prometheus.Gauge
+	deadLetteredPayments prometheus.Counter
+}
+
+func NewPrometheusExporter(orderRepo repository.OrderRepository) *PrometheusExporter {
+	registry := prometheus.NewRegistry()
+
+	p := &PrometheusExporter{
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and status code.",
+			Buckets:   []float64{0.05, 0.1, 0.2, 0.3, 0.5, 0.75, 1, 1.5, 2, 3, 5, 10},
+		}, []string{"method", "route", "status"}),
+		productViews: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "product_views_total",
+			Help:      "Total number of product detail views.",
+		}),
+		addToCart: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "add_to_cart_total",
+			Help:      "Total number of add-to-cart operations.",
+		}),
+		addToCartItems: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "add_to_cart_items_total",
+			Help:      "Total quantity of items added to carts.",
+		}),
+		purchases: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "purchases_total",
+			Help:      "Total number of orders placed.",
+		}),
+		purchaseItems: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "purchase_items_total",
+			Help:      "Total quantity of items ordered.",
+		}),
+		revenue: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "revenue_yen_total",
+			Help:      "Total amount of orders placed in yen.",
+		}),
+		abandonedCarts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "abandoned_carts_total",
+			Help:      "Total number of carts that expired with items in them.",
+		}),
+		abandonedCartValue: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "abandoned_cart_value_yen_total",
+			Help:      "Total amount of items left in expired carts in yen.",
+		}),
+		stockEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "stock_events_total",
+			Help:      "Total number of stock threshold crossings by event type.",
+		}, []string{"type"}),
+		payments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "payments_total",
+			Help:      "Total number of payment results applied to orders by result and failure reason.",
+		}, []string{"result", "reason"}),
+		paymentQueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "payment_queue_depth",
+			Help:      "Number of payment jobs waiting in the queue.",
+		}),
+		paymentWorkersBusy: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "payment_workers_busy",
+			Help:      "Number of payment workers processing a job.",
+		}),
+		paymentQueueWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "payment_queue_wait_seconds",
+			Help:      "Time payment jobs waited in the queue before a worker picked them up.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+		}),
+		paymentRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "payment_job_retries_total",
+			Help:      "Total number of payment jobs retried after a transient failure.",
+		}),
+		paymentDeadLetters: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "payment_dead_letters",
+			Help:      "Number of payment jobs in the dead-letter list.",
+		}),
+		deadLetteredPayments: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "payment_dead_letters_total",
+			Help:      "Total number of payment jobs moved to the dead-letter list.",
+		}),
+	}
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		p.requests,
+		p.requestDuration,
+		p.productViews,
+		p.addToCart,
+		p.addToCartItems,
+		p.purchases,
+		p.purchaseItems,
+		p.revenue,
+		p.abandonedCarts,
+		p.abandonedCartValue,
+		p.stockEvents,
+		p.payments,
+		p.paymentQueueDepth,
+		p.paymentWorkersBusy,
+		p.paymentQueueWait,
+		p.paymentRetries,
+		p.paymentDeadLetters,
+		p.deadLetteredPayments,
+		newOrderStatusCollector(orderRepo),
+	)
+
+	return p
+}
+
+// Handler は /metrics のHTTPハンドラーを返す
+func (p *PrometheusExporter) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{Registry: p.registry})
+}
+
+func (p *PrometheusExporter) ObserveRequest(method, route string, statusCode int, duration time.Duration) {
+	status := strconv.Itoa(statusCode)
+	p.requests.WithLabelValues(method, route, status).Inc()
+	p.requestDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+// ObservePaymentQueue は決済キューの滞留数・実行中のワーカー数・デッドレターの件数を記録する
+func (p *PrometheusExporter) ObservePaymentQueue(depth int, busy int, deadLetters int) {
+	p.paymentQueueDepth.Set(float64(depth))
+	p.paymentWorkersBusy.Set(float64(busy))
+	p.paymentDeadLetters.Set(float64(deadLetters))
+}
+
+// ObservePaymentRetry は決済ジョブの再試行を記録する
+func (p *PrometheusExporter) ObservePaymentRetry() {
+	p.paymentRetries.Inc()
+}
+
+// ObservePaymentDeadLetter は決済ジョブがデッドレターに移ったことを記録する
+func (p *PrometheusExporter) ObservePaymentDeadLetter() {
+	p.deadLetteredPayments.Inc()
+}
+
+// ObservePaymentQueueWait は決済ジョブがキューで待機した時間を記録する
+func (p *PrometheusExporter) ObservePaymentQueueWait(wait time.Duration) {
+	p.paymentQueueWait.Observe(wait.Seconds())
+}
+
+// RecordEvent はビジネスイベントをカウンターに反映する（その他のイベントは記録しない）
+func (p *PrometheusExporter) RecordEvent(ctx context.Context, eventType string, attributes map[string]interface{}) {
+	switch eventType {
+	case EventProductView:
+		p.productViews.Inc()
+	case EventAddToCart:
+		p.addToCart.Inc()
+		p.addToCartItems.Add(numberAttribute(attributes, "quantity"))
+	case EventPurchase:
+		p.purchases.Inc()
+		p.purchaseItems.Add(numberAttribute(attributes, "itemCount"))
+		p.revenue.Add(numberAttribute(attributes, "amount"))
+	case EventAbandonedCart:
+		p.abandonedCarts.Inc()
+		p.abandonedCartValue.Add(numberAttribute(attributes, "value"))
+	case EventLowStock, EventOutOfStock, EventRestocked:
+		p.stockEvents.WithLabelValues(eventType).Inc()
+	case EventPayment:
+		result := "failure"
+		if success, _ := attributes["success"].(bool); success {
+			result = "success"
+		}
+		reason, _ := attributes["failureReason"].(string)
+		p.payments.WithLabelValues(result, reason).Inc()
+	}
+}
+
+func (p *PrometheusExporter) RecordMetric(ctx context.Context, name string, value float64) {}
+
+func (p *PrometheusExporter) NoticeError(ctx context.Context, err error) {}
+
+func (p *PrometheusExporter) AddAttributes(ctx context.Context, attributes map[string]interface{}) {}
+
+func (p *PrometheusExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func numberAttribute(attributes map[string]interface{}, key string) float64 {
+	switch v := attributes[key].(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
+
+// orderStatusCollector はスクレイプ時に注文をステータスごとに数える
+type orderStatusCollector struct {
+	orderRepo repository.OrderRepository
+	desc      *prometheus.Desc
+}
+
+func newOrderStatusCollector(orderRepo repository.OrderRepository) *orderStatusCollector {
+	return &orderStatusCollector{
+		orderRepo: orderRepo,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "orders"),
+			"Current number of orders by status.",
+			[]string{"status"}, nil,
+		),
+	}
+}
+
+func (c *orderStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *orderStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	orders, err := c.orderRepo.GetAll(context.Background())
+	if err != nil {
+		log.Printf("Failed to collect order status metrics: %v", err)
+		return
+	}
+
+	counts := map[entity.OrderStatus]int{
+		entity.OrderStatusPending:   0,
+		entity.OrderStatusPaid:      0,
+		entity.OrderStatusShipped:   0,
+		entity.OrderStatusDelivered: 0,
+		entity.OrderStatusCompleted: 0,
+		entity.OrderStatusFailed:    0,
+		entity.OrderStatusCanceled:  0,
+		entity.OrderStatusRefunded:  0,
+	}
+	for _, order := range orders {
+		counts[order.Status]++
+	}
+
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), string(status))
+	}
+}
@@ -0,0 +1,91 @@
+package monitoring
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase/mocks"
+)
+
+func TestPrometheusExporter_Handler(t *testing.T) {
+	orderRepo := &mocks.MockOrderRepository{
+		GetAllFunc: func(ctx context.Context) ([]*entity.Order, error) {
+			return []*entity.Order{
+				{ID: "order-1", Status: entity.OrderStatusPending},
+				{ID: "order-2", Status: entity.OrderStatusCompleted},
+				{ID: "order-3", Status: entity.OrderStatusCompleted},
+			}, nil
+		},
+	}
+
+	exporter := NewPrometheusExporter(orderRepo)
+
+	// New Relic が無効でもビジネスメトリクスは Prometheus に記録される
+	ctx := context.Background()
+	telemetry := NewMultiTelemetry(&NewRelicClient{}, exporter)
+	RecordProductView(ctx, telemetry, "product-1", "anonymous")
+	RecordAddToCart(ctx, telemetry, "product-1", 3, "anonymous")
+	RecordPurchase(ctx, telemetry, "order-1", 12000, 3, "anonymous")
+	RecordAbandonedCart(ctx, telemetry, &entity.Cart{ID: "cart-1", TotalAmount: 5000}, time.Hour, 30*time.Minute)
+	RecordStockEvent(ctx, telemetry, entity.StockEvent{Type: entity.StockEventOutOfStock, ProductID: "product-1"})
+	RecordPayment(ctx, telemetry, &entity.Order{ID: "order-1", Payment: entity.OrderPayment{Status: entity.PaymentStatusCaptured}}, true, 3*time.Second)
+	RecordPayment(ctx, telemetry, &entity.Order{ID: "order-2", Payment: entity.OrderPayment{Status: entity.PaymentStatusDeclined, FailureReason: entity.PaymentFailureDeclined}}, false, 2*time.Second)
+
+	exporter.ObserveRequest("GET", "/api/products", 200, 150*time.Millisecond)
+	exporter.ObserveRequest("POST", "/api/orders", 500, 2*time.Second)
+
+	monitor := NewPaymentQueueMonitor(telemetry, exporter)
+	_, end := monitor.StartJob(ctx, "order-1", 300*time.Millisecond)
+	monitor.ObserveDepth(ctx, 7, 4, 1)
+	monitor.ObserveRetry(ctx, "order-1", 1, time.Second, entity.ErrPaymentUnavailable)
+	monitor.ObserveDeadLetter(ctx, "order-1", 5, entity.ErrPaymentUnavailable)
+	end()
+
+	server := httptest.NewServer(exporter.Handler())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("スクレイプエラー: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	metrics := string(body)
+
+	expected := []string{
+		`slm_http_requests_total{method="GET",route="/api/products",status="200"} 1`,
+		`slm_http_requests_total{method="POST",route="/api/orders",status="500"} 1`,
+		`slm_http_request_duration_seconds_bucket{method="GET",route="/api/products",status="200",le="0.2"} 1`,
+		`slm_http_request_duration_seconds_bucket{method="POST",route="/api/orders",status="500",le="1.5"} 0`,
+		`slm_product_views_total 1`,
+		`slm_add_to_cart_total 1`,
+		`slm_add_to_cart_items_total 3`,
+		`slm_purchases_total 1`,
+		`slm_revenue_yen_total 12000`,
+		`slm_abandoned_carts_total 1`,
+		`slm_abandoned_cart_value_yen_total 5000`,
+		`slm_stock_events_total{type="OutOfStock"} 1`,
+		`slm_payments_total{reason="",result="success"} 1`,
+		`slm_payments_total{reason="declined",result="failure"} 1`,
+		`slm_payment_queue_depth 7`,
+		`slm_payment_workers_busy 4`,
+		`slm_payment_dead_letters 1`,
+		`slm_payment_job_retries_total 1`,
+		`slm_payment_dead_letters_total 1`,
+		`slm_payment_queue_wait_seconds_bucket{le="0.5"} 1`,
+		`slm_orders{status="pending"} 1`,
+		`slm_orders{status="completed"} 2`,
+		`slm_orders{status="failed"} 0`,
+		`slm_orders{status="refunded"} 0`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(metrics, line) {
+			t.Errorf("メトリクスに %q が含まれていない", line)
+		}
+	}
+}
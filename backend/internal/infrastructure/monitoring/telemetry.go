@@ -0,0 +1,113 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/config"
+)
+
+// テレメトリのバックエンド
+const (
+	BackendNewRelic = "newrelic"
+	BackendOTel     = "otel"
+	BackendLog      = "log"
+	BackendMemory   = "memory"
+)
+
+var ErrUnknownBackend = errors.New("unknown telemetry backend")
+
+// Telemetry はイベント・メトリクス・エラー・スパン属性の記録先
+// ハンドラーやミドルウェアは特定のベンダーに依存せずこのインターフェースを利用する
+type Telemetry interface {
+	// RecordEvent はビジネスイベントを記録する（New Relic のカスタムイベント等）
+	RecordEvent(ctx context.Context, eventType string, attributes map[string]interface{})
+
+	// RecordMetric は数値メトリクスを1件記録する
+	RecordMetric(ctx context.Context, name string, value float64)
+
+	// NoticeError はリクエストのトランザクション（スパン）にエラーを記録する
+	NoticeError(ctx context.Context, err error)
+
+	// AddAttributes はリクエストのトランザクション（スパン）に属性を追加する
+	AddAttributes(ctx context.Context, attributes map[string]interface{})
+
+	// Shutdown は未送信のデータを送信して終了する
+	Shutdown(ctx context.Context) error
+}
+
+// NewTelemetry は設定されたバックエンドの Telemetry を作成する
+func NewTelemetry(cfg config.TelemetryConfig) (Telemetry, error) {
+	switch cfg.Backend {
+	case "", BackendNewRelic:
+		return NewNewRelicClient()
+	case BackendOTel:
+		return SetupOTel(context.Background(), cfg.OTLPEndpoint, cfg.ServiceName)
+	case BackendLog:
+		return NewLogTelemetry(), nil
+	case BackendMemory:
+		return NewMemoryTelemetry(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, cfg.Backend)
+	}
+}
+
+// multiTelemetry は複数の Telemetry に同じ内容を記録する
+type multiTelemetry []Telemetry
+
+// NewMultiTelemetry は全ての Telemetry に記録する Telemetry を作成する
+func NewMultiTelemetry(telemetries ...Telemetry) Telemetry {
+	return multiTelemetry(telemetries)
+}
+
+func (m multiTelemetry) RecordEvent(ctx context.Context, eventType string, attributes map[string]interface{}) {
+	for _, t := range m {
+		t.RecordEvent(ctx, eventType, attributes)
+	}
+}
+
+func (m multiTelemetry) RecordMetric(ctx context.Context, name string, value float64) {
+	for _, t := range m {
+		t.RecordMetric(ctx, name, value)
+	}
+}
+
+func (m multiTelemetry) NoticeError(ctx context.Context, err error) {
+	for _, t := range m {
+		t.NoticeError(ctx, err)
+	}
+}
+
+func (m multiTelemetry) AddAttributes(ctx context.Context, attributes map[string]interface{}) {
+	for _, t := range m {
+		t.AddAttributes(ctx, attributes)
+	}
+}
+
+func (m multiTelemetry) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, t := range m {
+		if err := t.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewRelicApplication は Telemetry が New Relic を利用している場合にアプリケーションを返す（nrgin ミドルウェア用）
+func NewRelicApplication(t Telemetry) *newrelic.Application {
+	switch telemetry := t.(type) {
+	case *NewRelicClient:
+		return telemetry.GetApplication()
+	case multiTelemetry:
+		for _, child := range telemetry {
+			if app := NewRelicApplication(child); app != nil {
+				return app
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/config"
+)
+
+func TestNewTelemetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		backend     string
+		expectedErr error
+	}{
+		{name: "未指定はNew Relic", backend: ""},
+		{name: "New Relic", backend: BackendNewRelic},
+		{name: "OpenTelemetry", backend: BackendOTel},
+		{name: "ログ出力", backend: BackendLog},
+		{name: "メモリ", backend: BackendMemory},
+		{name: "未知のバックエンドはエラー", backend: "datadog", expectedErr: ErrUnknownBackend},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telemetry, err := NewTelemetry(config.TelemetryConfig{Backend: tt.backend, OTLPEndpoint: "http://localhost:4318", ServiceName: "slm-handson-api-test"})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("エラー = %v, want %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr == nil && telemetry == nil {
+				t.Fatal("Telemetry が nil")
+			}
+		})
+	}
+}
+
+func TestMultiTelemetry(t *testing.T) {
+	ctx := context.Background()
+	first := NewMemoryTelemetry()
+	second := NewMemoryTelemetry()
+	telemetry := NewMultiTelemetry(first, second)
+
+	RecordPurchase(ctx, telemetry, "order-1", 12000, 3, "user-1")
+	telemetry.AddAttributes(ctx, map[string]interface{}{"handler": "CreateOrder"})
+	telemetry.NoticeError(ctx, errors.New("payment failed"))
+
+	for _, memory := range []*MemoryTelemetry{first, second} {
+		purchases := memory.Events(EventPurchase)
+		if len(purchases) != 1 {
+			t.Fatalf("Purchase イベント数 = %v, want 1", len(purchases))
+		}
+		if purchases[0].Attributes["orderId"] != "order-1" {
+			t.Errorf("orderId = %v, want order-1", purchases[0].Attributes["orderId"])
+		}
+		if revenue := memory.Metrics("Custom/Revenue"); len(revenue) != 1 || revenue[0].Value != 12000 {
+			t.Errorf("Custom/Revenue = %v, want 12000", revenue)
+		}
+		if value, _ := memory.Attribute("handler"); value != "CreateOrder" {
+			t.Errorf("handler 属性 = %v, want CreateOrder", value)
+		}
+		if len(memory.Errors()) != 1 {
+			t.Errorf("エラー数 = %v, want 1", len(memory.Errors()))
+		}
+	}
+
+	// New Relic が有効な場合のみアプリケーションを返す
+	if app := NewRelicApplication(NewMultiTelemetry(first, &NewRelicClient{})); app != nil {
+		t.Errorf("NewRelicApplication = %v, want nil", app)
+	}
+
+	if err := telemetry.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown エラー: %v", err)
+	}
+}
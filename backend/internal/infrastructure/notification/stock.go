@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"context"
+	"log"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/webhook"
+)
+
+// LogSink は在庫イベントを標準ログに出力する
+type LogSink struct{}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+func (s *LogSink) Notify(ctx context.Context, event entity.StockEvent) error {
+	log.Printf("Stock event %s: product=%s (%s) stock %d -> %d (threshold=%d)",
+		event.Type, event.ProductID, event.ProductName, event.PreviousStock, event.Stock, event.Threshold)
+	return nil
+}
+
+// WebhookSink は在庫イベントをJSONでPOSTする
+type WebhookSink struct {
+	poster *webhook.Poster
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		poster: webhook.NewPoster(url),
+	}
+}
+
+func (s *WebhookSink) Notify(ctx context.Context, event entity.StockEvent) error {
+	return s.poster.Post(ctx, event)
+}
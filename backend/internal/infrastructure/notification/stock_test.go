@@ -0,0 +1,43 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+)
+
+func TestWebhookSink_Notify(t *testing.T) {
+	var received entity.StockEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %v, want application/json", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("JSONパースエラー: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	event := entity.StockEvent{Type: entity.StockEventOutOfStock, ProductID: "product-1", PreviousStock: 3, Stock: 0, Threshold: 10}
+	if err := NewWebhookSink(server.URL).Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if received.Type != entity.StockEventOutOfStock || received.ProductID != "product-1" || received.PreviousStock != 3 {
+		t.Errorf("received = %+v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	if err := NewWebhookSink(failing.URL).Notify(context.Background(), event); err == nil {
+		t.Error("エラーが期待されましたが、エラーが発生しませんでした")
+	}
+}
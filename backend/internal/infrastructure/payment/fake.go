@@ -0,0 +1,189 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// FakeConfig は擬似決済の振る舞い
+type FakeConfig struct {
+	// 与信が承認される確率（0.0-1.0）
+	SuccessRate float64
+
+	// 与信の処理時間（最小値〜最大値の一様分布）
+	LatencyMin time.Duration
+	LatencyMax time.Duration
+
+	// 与信を拒否するときの理由（ランダムに1つ選ぶ。空の場合は card_declined）
+	DeclineCodes []string
+
+	// 最後の操作から決済を保持する期間（期限切れの決済は売上確定・返金できない。0 の場合は24時間）
+	Retention time.Duration
+}
+
+const (
+	defaultFakePaymentRetention = 24 * time.Hour
+	fakePaymentSweepInterval    = time.Minute
+)
+
+// FakeGateway はプロセス内で決済を模擬する決済ゲートウェイ
+// 与信のみ処理時間がかかり、売上確定・返金・取り消しは即時に応答する
+type FakeGateway struct {
+	config FakeConfig
+	random utils.Random
+	now    func() time.Time
+
+	mutex     sync.Mutex
+	payments  map[string]*fakePayment
+	lastSweep time.Time
+}
+
+// fakePayment は売上確定・返金・取り消しの判定に必要な決済の状態のみを保持する
+type fakePayment struct {
+	status    entity.PaymentStatus
+	amount    int
+	expiresAt time.Time
+}
+
+// NewFakeGateway は擬似決済ゲートウェイを作成する。random は並行アクセス安全である必要がある（utils.NewSeededRand）
+func NewFakeGateway(config FakeConfig, random utils.Random) *FakeGateway {
+	if config.Retention <= 0 {
+		config.Retention = defaultFakePaymentRetention
+	}
+	return &FakeGateway{
+		config:   config,
+		random:   random,
+		now:      time.Now,
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error) {
+	if req.Amount <= 0 {
+		return nil, entity.ErrInvalidInput
+	}
+
+	// 乱数は処理時間を待つ前に決定する（同じシードなら同じ結果の系列になる）
+	latency := g.latency()
+	approved := utils.RandomBool(g.random, g.config.SuccessRate)
+	declineCode := g.declineCode()
+
+	select {
+	case <-time.After(latency):
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", entity.ErrPaymentUnavailable, ctx.Err())
+	}
+
+	payment := &entity.PaymentResult{
+		TransactionID: "pay_" + uuid.New().String(),
+		Status:        entity.PaymentStatusAuthorized,
+		Amount:        req.Amount,
+		ProcessedAt:   g.now(),
+	}
+	if !approved {
+		payment.Status = entity.PaymentStatusDeclined
+		payment.DeclineCode = declineCode
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.sweep(payment.ProcessedAt)
+	g.payments[payment.TransactionID] = &fakePayment{
+		status:    payment.Status,
+		amount:    payment.Amount,
+		expiresAt: payment.ProcessedAt.Add(g.config.Retention),
+	}
+	return payment, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+	return g.transition(transactionID, amount, entity.PaymentStatusAuthorized, entity.PaymentStatusCaptured)
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+	return g.transition(transactionID, amount, entity.PaymentStatusCaptured, entity.PaymentStatusRefunded)
+}
+
+func (g *FakeGateway) Void(ctx context.Context, transactionID string) (*entity.PaymentResult, error) {
+	return g.transition(transactionID, 0, entity.PaymentStatusAuthorized, entity.PaymentStatusVoided)
+}
+
+func (g *FakeGateway) Get(ctx context.Context, transactionID string) (*entity.PaymentResult, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := g.now()
+	g.sweep(now)
+
+	payment, exists := g.payments[transactionID]
+	if !exists || !now.Before(payment.expiresAt) {
+		return nil, entity.ErrPaymentNotFound
+	}
+	return &entity.PaymentResult{
+		TransactionID: transactionID,
+		Status:        payment.status,
+		Amount:        payment.amount,
+		ProcessedAt:   now,
+	}, nil
+}
+
+// transition は from 状態の決済を to 状態にする（amount が 0 の場合は金額を確認しない）
+func (g *FakeGateway) transition(transactionID string, amount int, from, to entity.PaymentStatus) (*entity.PaymentResult, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := g.now()
+	g.sweep(now)
+
+	payment, exists := g.payments[transactionID]
+	if !exists || !now.Before(payment.expiresAt) {
+		return nil, entity.ErrPaymentNotFound
+	}
+	if payment.status != from {
+		return nil, entity.ErrInvalidPaymentStatus
+	}
+	if amount < 0 || amount > payment.amount {
+		return nil, entity.ErrInvalidInput
+	}
+
+	payment.status = to
+	payment.expiresAt = now.Add(g.config.Retention)
+	return &entity.PaymentResult{
+		TransactionID: transactionID,
+		Status:        to,
+		Amount:        payment.amount,
+		ProcessedAt:   now,
+	}, nil
+}
+
+// sweep は保持期間を過ぎた決済を削除する（mutex を保持した状態で呼び出す）
+func (g *FakeGateway) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < fakePaymentSweepInterval {
+		return
+	}
+	g.lastSweep = now
+
+	for transactionID, payment := range g.payments {
+		if !now.Before(payment.expiresAt) {
+			delete(g.payments, transactionID)
+		}
+	}
+}
+
+func (g *FakeGateway) latency() time.Duration {
+	return time.Duration(utils.Uniform(g.random, float64(g.config.LatencyMin), float64(g.config.LatencyMax)))
+}
+
+func (g *FakeGateway) declineCode() string {
+	if len(g.config.DeclineCodes) == 0 {
+		return entity.DeclineCardDeclined
+	}
+	return g.config.DeclineCodes[g.random.Intn(len(g.config.DeclineCodes))]
+}
@@ -0,0 +1,175 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// fixedRandom は常に同じ値を返すテスト用の乱数源
+type fixedRandom struct {
+	float float64
+	intn  int
+}
+
+func (r fixedRandom) Float64() float64     { return r.float }
+func (r fixedRandom) Intn(n int) int       { return r.intn % n }
+func (r fixedRandom) NormFloat64() float64 { return 0 }
+
+func TestFakeGateway_Authorize(t *testing.T) {
+	tests := []struct {
+		name                string
+		random              utils.Random
+		declineCodes        []string
+		expectedStatus      entity.PaymentStatus
+		expectedDeclineCode string
+	}{
+		{
+			name:           "承認率を下回る値で承認",
+			random:         fixedRandom{float: 0.5},
+			expectedStatus: entity.PaymentStatusAuthorized,
+		},
+		{
+			name:                "承認率以上の値で拒否",
+			random:              fixedRandom{float: 0.9, intn: 1},
+			declineCodes:        []string{entity.DeclineInsufficientFunds, entity.DeclineCardExpired},
+			expectedStatus:      entity.PaymentStatusDeclined,
+			expectedDeclineCode: entity.DeclineCardExpired,
+		},
+		{
+			name:                "拒否理由が未設定の場合は card_declined",
+			random:              fixedRandom{float: 0.95},
+			expectedStatus:      entity.PaymentStatusDeclined,
+			expectedDeclineCode: entity.DeclineCardDeclined,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := NewFakeGateway(FakeConfig{SuccessRate: 0.9, DeclineCodes: tt.declineCodes}, tt.random)
+
+			result, err := gateway.Authorize(context.Background(), entity.PaymentRequest{OrderID: "order-1", Amount: 5000, Currency: entity.PaymentCurrency})
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+			if result.Status != tt.expectedStatus {
+				t.Errorf("Status = %v, want %v", result.Status, tt.expectedStatus)
+			}
+			if result.DeclineCode != tt.expectedDeclineCode {
+				t.Errorf("DeclineCode = %q, want %q", result.DeclineCode, tt.expectedDeclineCode)
+			}
+			if result.TransactionID == "" || result.Amount != 5000 {
+				t.Errorf("結果 = %+v", result)
+			}
+		})
+	}
+}
+
+func TestFakeGateway_Latency(t *testing.T) {
+	gateway := NewFakeGateway(FakeConfig{SuccessRate: 1, LatencyMin: 20 * time.Millisecond, LatencyMax: 20 * time.Millisecond}, utils.NewSeededRand(1))
+
+	start := time.Now()
+	if _, err := gateway.Authorize(context.Background(), entity.PaymentRequest{OrderID: "order-1", Amount: 100}); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("処理時間 = %v, want >= 20ms", elapsed)
+	}
+
+	// 処理中にキャンセルされた場合はゲートウェイの障害として扱う
+	slow := NewFakeGateway(FakeConfig{SuccessRate: 1, LatencyMin: time.Minute, LatencyMax: time.Minute}, utils.NewSeededRand(1))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := slow.Authorize(ctx, entity.PaymentRequest{OrderID: "order-1", Amount: 100}); !errors.Is(err, entity.ErrPaymentUnavailable) {
+		t.Errorf("Authorize() error = %v, want %v", err, entity.ErrPaymentUnavailable)
+	}
+}
+
+func TestFakeGateway_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway(FakeConfig{SuccessRate: 1}, utils.NewSeededRand(1))
+
+	authorize := func() string {
+		result, err := gateway.Authorize(ctx, entity.PaymentRequest{OrderID: "order-1", Amount: 3000})
+		if err != nil {
+			t.Fatalf("Authorize() error = %v", err)
+		}
+		return result.TransactionID
+	}
+
+	// 与信 → 売上確定 → 返金
+	captured := authorize()
+	if _, err := gateway.Capture(ctx, captured, 5000); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("与信額を超える売上確定 error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+	if result, err := gateway.Capture(ctx, captured, 3000); err != nil || result.Status != entity.PaymentStatusCaptured {
+		t.Fatalf("Capture() = %+v, %v", result, err)
+	}
+	if result, err := gateway.Get(ctx, captured); err != nil || result.Status != entity.PaymentStatusCaptured || result.Amount != 3000 {
+		t.Errorf("Get() = %+v, %v", result, err)
+	}
+	if _, err := gateway.Void(ctx, captured); !errors.Is(err, entity.ErrInvalidPaymentStatus) {
+		t.Errorf("売上確定後の取り消し error = %v, want %v", err, entity.ErrInvalidPaymentStatus)
+	}
+	if result, err := gateway.Refund(ctx, captured, 3000); err != nil || result.Status != entity.PaymentStatusRefunded {
+		t.Fatalf("Refund() = %+v, %v", result, err)
+	}
+	if _, err := gateway.Refund(ctx, captured, 3000); !errors.Is(err, entity.ErrInvalidPaymentStatus) {
+		t.Errorf("二重返金 error = %v, want %v", err, entity.ErrInvalidPaymentStatus)
+	}
+
+	// 与信 → 取り消し
+	voided := authorize()
+	if result, err := gateway.Void(ctx, voided); err != nil || result.Status != entity.PaymentStatusVoided {
+		t.Fatalf("Void() = %+v, %v", result, err)
+	}
+	if _, err := gateway.Capture(ctx, voided, 3000); !errors.Is(err, entity.ErrInvalidPaymentStatus) {
+		t.Errorf("取り消し後の売上確定 error = %v, want %v", err, entity.ErrInvalidPaymentStatus)
+	}
+
+	if _, err := gateway.Capture(ctx, "pay_unknown", 100); !errors.Is(err, entity.ErrPaymentNotFound) {
+		t.Errorf("存在しない決済 error = %v, want %v", err, entity.ErrPaymentNotFound)
+	}
+	if _, err := gateway.Get(ctx, "pay_unknown"); !errors.Is(err, entity.ErrPaymentNotFound) {
+		t.Errorf("存在しない決済の取得 error = %v, want %v", err, entity.ErrPaymentNotFound)
+	}
+}
+
+func TestFakeGateway_Retention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	gateway := NewFakeGateway(FakeConfig{SuccessRate: 1, Retention: time.Hour}, utils.NewSeededRand(1))
+	gateway.now = func() time.Time { return now }
+
+	capture := func() string {
+		authorized, err := gateway.Authorize(ctx, entity.PaymentRequest{OrderID: "order-1", Amount: 3000})
+		if err != nil {
+			t.Fatalf("Authorize() error = %v", err)
+		}
+		if _, err := gateway.Capture(ctx, authorized.TransactionID, 3000); err != nil {
+			t.Fatalf("Capture() error = %v", err)
+		}
+		return authorized.TransactionID
+	}
+
+	// 保持期間内は返金できる
+	recent := capture()
+	now = now.Add(59 * time.Minute)
+	if _, err := gateway.Refund(ctx, recent, 3000); err != nil {
+		t.Errorf("保持期間内の返金 error = %v", err)
+	}
+
+	// 最後の操作から保持期間を過ぎた決済は削除する
+	expired := capture()
+	now = now.Add(time.Hour)
+	if _, err := gateway.Refund(ctx, expired, 3000); !errors.Is(err, entity.ErrPaymentNotFound) {
+		t.Errorf("保持期間を過ぎた返金 error = %v, want %v", err, entity.ErrPaymentNotFound)
+	}
+	if len(gateway.payments) != 0 {
+		t.Errorf("保持している決済 = %v件, want 0", len(gateway.payments))
+	}
+}
@@ -0,0 +1,163 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+)
+
+var tracer = otel.Tracer("github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/payment")
+
+// HTTPGateway は決済APIをHTTPで呼び出す決済ゲートウェイ（ローカルでは cmd/payment-stub を利用）
+// 呼び出しは New Relic の外部サービスセグメント、OpenTelemetry のクライアントスパンとして記録する
+type HTTPGateway struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPGateway(baseURL string, timeout time.Duration) *HTTPGateway {
+	return &HTTPGateway{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// amountRequest は売上確定・返金のリクエストボディ
+type amountRequest struct {
+	Amount int `json:"amount"`
+}
+
+// errorResponse は決済APIのエラーレスポンス
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (g *HTTPGateway) Authorize(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error) {
+	return g.call(ctx, "Authorize", http.MethodPost, "/v1/payments/authorize", req)
+}
+
+// Capture はタイムアウトなど応答を受け取れなかった場合も ErrPaymentUnavailable を返す
+// （売上確定されたかは不明のため、呼び出し元は再試行の前に Get で状態を確認する）
+func (g *HTTPGateway) Capture(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+	return g.call(ctx, "Capture", http.MethodPost, "/v1/payments/"+transactionID+"/capture", amountRequest{Amount: amount})
+}
+
+func (g *HTTPGateway) Refund(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+	return g.call(ctx, "Refund", http.MethodPost, "/v1/payments/"+transactionID+"/refund", amountRequest{Amount: amount})
+}
+
+func (g *HTTPGateway) Void(ctx context.Context, transactionID string) (*entity.PaymentResult, error) {
+	return g.call(ctx, "Void", http.MethodPost, "/v1/payments/"+transactionID+"/void", nil)
+}
+
+func (g *HTTPGateway) Get(ctx context.Context, transactionID string) (*entity.PaymentResult, error) {
+	return g.call(ctx, "Get", http.MethodGet, "/v1/payments/"+transactionID, nil)
+}
+
+func (g *HTTPGateway) call(ctx context.Context, operation, method, path string, body interface{}) (*entity.PaymentResult, error) {
+	url := g.baseURL + path
+	ctx, span := tracer.Start(ctx, "PaymentGateway."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("url.full", url),
+			attribute.String("peer.service", "payment-gateway"),
+		),
+	)
+	defer span.End()
+
+	result, err := g.do(ctx, method, url, body)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("payment.status", string(result.Status)))
+	return result, nil
+}
+
+func (g *HTTPGateway) do(ctx context.Context, method, url string, body interface{}) (*entity.PaymentResult, error) {
+	var payload io.Reader
+	if method != http.MethodGet {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode payment request: %w", err)
+		}
+		payload = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// 決済API側でトレースを引き継げるよう W3C Trace Context を付与する
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	// New Relic の外部サービスセグメント（トランザクションがない場合は記録しない）
+	segment := monitoring.StartExternalSegment(monitoring.GetTransactionFromContext(ctx), url)
+	resp, err := g.client.Do(req)
+	if segment != nil {
+		segment.Response = resp
+		segment.End()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrPaymentUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, fmt.Errorf("%w: %s", errorForStatus(resp.StatusCode), errResp.Error)
+	}
+
+	var result entity.PaymentResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: invalid response: %v", entity.ErrPaymentUnavailable, err)
+	}
+	return &result, nil
+}
+
+// 決済APIのステータスコードとドメインエラーの対応（StubHandler と対になる）
+var statusErrors = []struct {
+	status int
+	err    error
+}{
+	{http.StatusBadRequest, entity.ErrInvalidInput},
+	{http.StatusNotFound, entity.ErrPaymentNotFound},
+	{http.StatusConflict, entity.ErrInvalidPaymentStatus},
+}
+
+func errorForStatus(status int) error {
+	for _, mapping := range statusErrors {
+		if mapping.status == status {
+			return mapping.err
+		}
+	}
+	return entity.ErrPaymentUnavailable
+}
+
+func statusForError(err error) int {
+	for _, mapping := range statusErrors {
+		if errors.Is(err, mapping.err) {
+			return mapping.status
+		}
+	}
+	return http.StatusServiceUnavailable
+}
@@ -0,0 +1,91 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+)
+
+func TestHTTPGateway_StubServer(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(NewStubHandler(NewFakeGateway(FakeConfig{SuccessRate: 0.9, DeclineCodes: []string{entity.DeclineInsufficientFunds}}, fixedRandom{float: 0.5})))
+	defer server.Close()
+	gateway := NewHTTPGateway(server.URL+"/", 5*time.Second)
+
+	authorized, err := gateway.Authorize(ctx, entity.PaymentRequest{OrderID: "order-1", Amount: 12000, Currency: entity.PaymentCurrency})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if authorized.Status != entity.PaymentStatusAuthorized || authorized.Amount != 12000 || authorized.TransactionID == "" {
+		t.Fatalf("Authorize() = %+v", authorized)
+	}
+
+	captured, err := gateway.Capture(ctx, authorized.TransactionID, 12000)
+	if err != nil || captured.Status != entity.PaymentStatusCaptured {
+		t.Fatalf("Capture() = %+v, %v", captured, err)
+	}
+	if current, err := gateway.Get(ctx, authorized.TransactionID); err != nil || current.Status != entity.PaymentStatusCaptured {
+		t.Fatalf("Get() = %+v, %v", current, err)
+	}
+	refunded, err := gateway.Refund(ctx, authorized.TransactionID, 12000)
+	if err != nil || refunded.Status != entity.PaymentStatusRefunded {
+		t.Fatalf("Refund() = %+v, %v", refunded, err)
+	}
+
+	// 決済APIのエラーはドメインエラーとして返す
+	if _, err := gateway.Void(ctx, authorized.TransactionID); !errors.Is(err, entity.ErrInvalidPaymentStatus) {
+		t.Errorf("返金後の取り消し error = %v, want %v", err, entity.ErrInvalidPaymentStatus)
+	}
+	if _, err := gateway.Capture(ctx, "pay_unknown", 100); !errors.Is(err, entity.ErrPaymentNotFound) {
+		t.Errorf("存在しない決済 error = %v, want %v", err, entity.ErrPaymentNotFound)
+	}
+	if _, err := gateway.Get(ctx, "pay_unknown"); !errors.Is(err, entity.ErrPaymentNotFound) {
+		t.Errorf("存在しない決済の取得 error = %v, want %v", err, entity.ErrPaymentNotFound)
+	}
+
+	// 与信の拒否はエラーではなく結果として返す
+	declining := httptest.NewServer(NewStubHandler(NewFakeGateway(FakeConfig{SuccessRate: 0.9, DeclineCodes: []string{entity.DeclineInsufficientFunds}}, fixedRandom{float: 0.95})))
+	defer declining.Close()
+	declined, err := NewHTTPGateway(declining.URL, 5*time.Second).Authorize(ctx, entity.PaymentRequest{OrderID: "order-2", Amount: 500})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if !declined.IsDeclined() || declined.DeclineCode != entity.DeclineInsufficientFunds {
+		t.Errorf("Authorize() = %+v, want declined insufficient_funds", declined)
+	}
+}
+
+func TestHTTPGateway_Unavailable(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	tests := []struct {
+		name    string
+		gateway *HTTPGateway
+	}{
+		{name: "5xxエラー", gateway: NewHTTPGateway(failing.URL, 5*time.Second)},
+		{name: "タイムアウト", gateway: NewHTTPGateway(slow.URL, 10*time.Millisecond)},
+		{name: "接続できない", gateway: NewHTTPGateway("http://127.0.0.1:1", time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.gateway.Authorize(context.Background(), entity.PaymentRequest{OrderID: "order-1", Amount: 100})
+			if !errors.Is(err, entity.ErrPaymentUnavailable) {
+				t.Errorf("Authorize() error = %v, want %v", err, entity.ErrPaymentUnavailable)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+)
+
+// Gateway は StubHandler が委譲する決済ゲートウェイ（usecase.PaymentGateway と同じメソッド）
+type Gateway interface {
+	Authorize(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error)
+	Capture(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error)
+	Refund(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error)
+	Void(ctx context.Context, transactionID string) (*entity.PaymentResult, error)
+	Get(ctx context.Context, transactionID string) (*entity.PaymentResult, error)
+}
+
+// StubHandler は HTTPGateway の呼び出し先となるローカルの決済API
+//
+//	POST /v1/payments/authorize       与信（拒否も 200 で status: declined を返す）
+//	POST /v1/payments/{id}/capture    売上確定
+//	POST /v1/payments/{id}/refund     返金
+//	POST /v1/payments/{id}/void       与信の取り消し
+//	GET  /v1/payments/{id}            決済の現在の状態
+type StubHandler struct {
+	gateway Gateway
+}
+
+func NewStubHandler(gateway Gateway) *StubHandler {
+	return &StubHandler{gateway: gateway}
+}
+
+func (h *StubHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/health" {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/payments/")
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+	if r.Method == http.MethodGet && path != "" && !strings.Contains(path, "/") {
+		result, err := h.gateway.Get(r.Context(), path)
+		if err != nil {
+			writeJSON(w, statusForError(err), errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	var (
+		result *entity.PaymentResult
+		err    error
+	)
+	if path == "authorize" {
+		var req entity.PaymentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+			return
+		}
+		result, err = h.gateway.Authorize(r.Context(), req)
+	} else {
+		transactionID, action, _ := strings.Cut(path, "/")
+		var req amountRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+				return
+			}
+		}
+
+		switch action {
+		case "capture":
+			result, err = h.gateway.Capture(r.Context(), transactionID, req.Amount)
+		case "refund":
+			result, err = h.gateway.Refund(r.Context(), transactionID, req.Amount)
+		case "void":
+			result, err = h.gateway.Void(r.Context(), transactionID)
+		default:
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+			return
+		}
+	}
+
+	if err != nil {
+		writeJSON(w, statusForError(err), errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
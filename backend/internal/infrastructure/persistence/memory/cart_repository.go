@@ -3,11 +3,13 @@ package memory
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
 )
 
+// cartRepository はカートのコピーを保存・返却する（呼び出し元での変更は Save するまで反映されない）
 type cartRepository struct {
 	carts map[string]*entity.Cart
 	mutex sync.RWMutex
@@ -29,7 +31,7 @@ func (r *cartRepository) GetByID(ctx context.Context, id string) (*entity.Cart,
 		return nil, entity.ErrItemNotFound
 	}
 
-	return cart, nil
+	return cart.Clone(), nil
 }
 
 func (r *cartRepository) GetOrCreate(ctx context.Context, id string) (*entity.Cart, error) {
@@ -43,14 +45,14 @@ func (r *cartRepository) GetOrCreate(ctx context.Context, id string) (*entity.Ca
 		r.carts[id] = cart
 	}
 
-	return cart, nil
+	return cart.Clone(), nil
 }
 
 func (r *cartRepository) Save(ctx context.Context, cart *entity.Cart) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.carts[cart.ID] = cart
+	r.carts[cart.ID] = cart.Clone()
 	return nil
 }
 
@@ -78,3 +80,19 @@ func (r *cartRepository) Clear(ctx context.Context, id string) error {
 	cart.Clear()
 	return nil
 }
+
+func (r *cartRepository) DeleteExpired(ctx context.Context, expiredBefore time.Time) ([]*entity.Cart, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// 削除したカートは他から参照されないため、コピーせずに返す
+	expired := make([]*entity.Cart, 0)
+	for id, cart := range r.carts {
+		if cart.UpdatedAt.Before(expiredBefore) {
+			expired = append(expired, cart)
+			delete(r.carts, id)
+		}
+	}
+
+	return expired, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 )
@@ -49,9 +50,9 @@ func TestCartRepository_GetOrCreate(t *testing.T) {
 					t.Error("新しいカートは空であるべきです")
 				}
 			} else {
-				// 2回目の呼び出し - 同じカートインスタンスが返される
-				if cart != firstCart {
-					t.Error("同じカートインスタンスが返されるべきです")
+				// 2回目の呼び出し - 既存のカートのコピーが返される（呼び出し元と共有しない）
+				if cart == firstCart || cart.CreatedAt != firstCart.CreatedAt {
+					t.Error("既存のカートのコピーが返されるべきです")
 				}
 			}
 		})
@@ -82,8 +83,8 @@ func TestCartRepository_GetByID(t *testing.T) {
 				if cart.ID != "cart-test" {
 					t.Errorf("ID = %v, want cart-test", cart.ID)
 				}
-				if cart != testCart {
-					t.Error("同じカートインスタンスが返されるべきです")
+				if cart == testCart || cart.CreatedAt != testCart.CreatedAt {
+					t.Error("保存済みのカートのコピーが返されるべきです")
 				}
 			},
 		},
@@ -155,6 +156,29 @@ func TestCartRepository_Save(t *testing.T) {
 	}
 }
 
+// TestCartRepository_Copies は保存したカートを呼び出し元と共有しないことを確認する
+func TestCartRepository_Copies(t *testing.T) {
+	repo := NewCartRepository()
+	ctx := context.Background()
+
+	cart, _ := repo.GetOrCreate(ctx, "cart-copy")
+	product := entity.NewProduct("コピーテスト商品", "説明", 1000, "image.jpg", 10)
+
+	// 取得したカートの変更は Save するまで反映されない
+	cart.AddItem(product, 1)
+	if fetched, _ := repo.GetByID(ctx, "cart-copy"); !fetched.IsEmpty() {
+		t.Errorf("Save前のアイテム数 = %v, want 0", len(fetched.Items))
+	}
+
+	repo.Save(ctx, cart)
+	// 保存後の呼び出し元での変更も保存済みのカートに影響しない
+	cart.AddItem(product, 2)
+	fetched, _ := repo.GetByID(ctx, "cart-copy")
+	if len(fetched.Items) != 1 || fetched.Items[0].Quantity != 1 {
+		t.Errorf("Save後のアイテム = %+v, want 数量1のアイテム1件", fetched.Items)
+	}
+}
+
 func TestCartRepository_Delete(t *testing.T) {
 	repo := NewCartRepository()
 	ctx := context.Background()
@@ -401,3 +425,44 @@ func TestCartRepository_MemoryManagement(t *testing.T) {
 		}
 	}
 }
+
+func TestCartRepository_DeleteExpired(t *testing.T) {
+	repo := NewCartRepository()
+	ctx := context.Background()
+	now := time.Now()
+
+	updatedAt := map[string]time.Time{
+		"cart-idle":   now.Add(-time.Hour),
+		"cart-active": now.Add(-time.Minute),
+	}
+	for id, updated := range updatedAt {
+		cart, _ := repo.GetOrCreate(ctx, id)
+		cart.UpdatedAt = updated
+		repo.Save(ctx, cart)
+	}
+
+	expired, err := repo.DeleteExpired(ctx, now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != "cart-idle" {
+		t.Fatalf("削除されたカート = %v, want [cart-idle]", expired)
+	}
+
+	tests := []struct {
+		name        string
+		cartID      string
+		expectedErr error
+	}{
+		{name: "期限切れのカートは削除される", cartID: "cart-idle", expectedErr: entity.ErrItemNotFound},
+		{name: "更新されたカートは残る", cartID: "cart-active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := repo.GetByID(ctx, tt.cartID); err != tt.expectedErr {
+				t.Errorf("エラー = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
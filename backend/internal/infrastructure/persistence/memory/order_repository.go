@@ -8,6 +8,7 @@ import (
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
 )
 
+// orderRepository は注文のコピーを保存・返却する（呼び出し元での変更は Update するまで反映されない）
 type orderRepository struct {
 	orders map[string]*entity.Order
 	mutex  sync.RWMutex
@@ -26,7 +27,7 @@ func (r *orderRepository) GetAll(ctx context.Context) ([]*entity.Order, error) {
 
 	orders := make([]*entity.Order, 0, len(r.orders))
 	for _, order := range r.orders {
-		orders = append(orders, order)
+		orders = append(orders, order.Clone())
 	}
 
 	return orders, nil
@@ -41,14 +42,14 @@ func (r *orderRepository) GetByID(ctx context.Context, id string) (*entity.Order
 		return nil, entity.ErrOrderNotFound
 	}
 
-	return order, nil
+	return order.Clone(), nil
 }
 
 func (r *orderRepository) Create(ctx context.Context, order *entity.Order) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.orders[order.ID] = order
+	r.orders[order.ID] = order.Clone()
 	return nil
 }
 
@@ -60,7 +61,7 @@ func (r *orderRepository) Update(ctx context.Context, order *entity.Order) error
 		return entity.ErrOrderNotFound
 	}
 
-	r.orders[order.ID] = order
+	r.orders[order.ID] = order.Clone()
 	return nil
 }
 
@@ -176,7 +176,7 @@ func TestOrderRepository_Update(t *testing.T) {
 			name: "存在する注文を更新",
 			order: func() *entity.Order {
 				o := testOrder
-				o.Complete() // ステータスを変更
+				o.Pay() // ステータスを変更
 				return o
 			}(),
 			expectError: false,
@@ -218,6 +218,33 @@ func TestOrderRepository_Update(t *testing.T) {
 	}
 }
 
+// TestOrderRepository_Copies は保存した注文を呼び出し元と共有しないことを確認する
+func TestOrderRepository_Copies(t *testing.T) {
+	repo := NewOrderRepository()
+	ctx := context.Background()
+
+	order := createTestOrder()
+	repo.Create(ctx, order)
+
+	// 保存後の呼び出し元での変更は Update するまで反映されない
+	order.Pay()
+	fetched, _ := repo.GetByID(ctx, order.ID)
+	if fetched.Status != entity.OrderStatusPending {
+		t.Errorf("Update前のステータス = %v, want %v", fetched.Status, entity.OrderStatusPending)
+	}
+
+	// 取得した注文の変更も保存済みの注文に影響しない
+	fetched.Cancel("test")
+	if again, _ := repo.GetByID(ctx, order.ID); again.Status != entity.OrderStatusPending {
+		t.Errorf("取得した注文の変更後のステータス = %v, want %v", again.Status, entity.OrderStatusPending)
+	}
+
+	repo.Update(ctx, order)
+	if updated, _ := repo.GetByID(ctx, order.ID); updated.Status != entity.OrderStatusPaid {
+		t.Errorf("Update後のステータス = %v, want %v", updated.Status, entity.OrderStatusPaid)
+	}
+}
+
 func TestOrderRepository_Delete(t *testing.T) {
 	repo := NewOrderRepository()
 	ctx := context.Background()
@@ -322,7 +349,7 @@ func TestOrderRepository_ConcurrentAccess(t *testing.T) {
 				repo.Create(ctx, order)
 
 				// その後更新
-				order.Complete()
+				order.Pay()
 				err := repo.Update(ctx, order)
 				if err != nil {
 					t.Errorf("並行Updateでエラー: %v", err)
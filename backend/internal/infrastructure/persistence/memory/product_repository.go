@@ -8,6 +8,7 @@ import (
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
 )
 
+// productRepository は商品のコピーを保存・返却する（在庫数は ReserveStock などのロックを保持した操作でのみ変更する）
 type productRepository struct {
 	products map[string]*entity.Product
 	mutex    sync.RWMutex
@@ -82,7 +83,7 @@ func (r *productRepository) GetAll(ctx context.Context) ([]*entity.Product, erro
 
 	products := make([]*entity.Product, 0, len(r.products))
 	for _, product := range r.products {
-		products = append(products, product)
+		products = append(products, product.Clone())
 	}
 
 	return products, nil
@@ -97,14 +98,14 @@ func (r *productRepository) GetByID(ctx context.Context, id string) (*entity.Pro
 		return nil, entity.ErrProductNotFound
 	}
 
-	return product, nil
+	return product.Clone(), nil
 }
 
 func (r *productRepository) Create(ctx context.Context, product *entity.Product) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.products[product.ID] = product
+	r.products[product.ID] = product.Clone()
 	return nil
 }
 
@@ -116,7 +117,7 @@ func (r *productRepository) Update(ctx context.Context, product *entity.Product)
 		return entity.ErrProductNotFound
 	}
 
-	r.products[product.ID] = product
+	r.products[product.ID] = product.Clone()
 	return nil
 }
 
@@ -145,6 +146,19 @@ func (r *productRepository) UpdateStock(ctx context.Context, id string, newStock
 	return nil
 }
 
+func (r *productRepository) UpdateStockSettings(ctx context.Context, id string, settings entity.StockSettings) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	product, exists := r.products[id]
+	if !exists {
+		return entity.ErrProductNotFound
+	}
+
+	product.ApplyStockSettings(settings)
+	return nil
+}
+
 func (r *productRepository) DecreaseStock(ctx context.Context, id string, quantity int) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -169,3 +183,75 @@ func (r *productRepository) IncreaseStock(ctx context.Context, id string, quanti
 	product.IncreaseStock(quantity)
 	return nil
 }
+
+func (r *productRepository) ReserveStock(ctx context.Context, items []entity.StockQuantity) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// 同じ商品が複数行ある場合も合算し、ロックを保持したまま在庫を確認してから引き当てる
+	required := make(map[string]int, len(items))
+	for _, item := range items {
+		if _, exists := r.products[item.ProductID]; !exists {
+			return entity.ErrProductNotFound
+		}
+		required[item.ProductID] += item.Quantity
+	}
+	for id, quantity := range required {
+		if !r.products[id].IsAvailable(quantity) {
+			return entity.ErrInsufficientStock
+		}
+	}
+
+	for id, quantity := range required {
+		r.products[id].Reserve(quantity)
+	}
+	return nil
+}
+
+func (r *productRepository) CommitStock(ctx context.Context, items []entity.StockQuantity) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, item := range items {
+		if product, exists := r.products[item.ProductID]; exists {
+			product.CommitReservation(item.Quantity)
+		}
+	}
+	return nil
+}
+
+func (r *productRepository) ReleaseStock(ctx context.Context, items []entity.StockQuantity) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, item := range items {
+		if product, exists := r.products[item.ProductID]; exists {
+			product.ReleaseReservation(item.Quantity)
+		}
+	}
+	return nil
+}
+
+func (r *productRepository) AdjustStock(ctx context.Context, changes []entity.StockChange) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// 同じ商品が複数行ある場合も合算して確認してから適用する
+	deltas := make(map[string]int, len(changes))
+	for _, change := range changes {
+		if _, exists := r.products[change.ProductID]; !exists {
+			return entity.ErrProductNotFound
+		}
+		deltas[change.ProductID] += change.Delta
+	}
+	for id, delta := range deltas {
+		if !r.products[id].CanAdjustStock(delta) {
+			return entity.ErrInsufficientStock
+		}
+	}
+
+	for id, delta := range deltas {
+		r.products[id].AdjustStock(delta)
+	}
+	return nil
+}
@@ -203,6 +203,31 @@ func TestProductRepository_Update(t *testing.T) {
 	}
 }
 
+// TestProductRepository_Copies は保存した商品を呼び出し元と共有しないことを確認する
+func TestProductRepository_Copies(t *testing.T) {
+	repo := NewProductRepository()
+	ctx := context.Background()
+
+	product := entity.NewProduct("コピーテスト商品", "説明", 1000, "image.jpg", 10)
+	repo.Create(ctx, product)
+
+	// 保存後の呼び出し元での変更は Update するまで反映されない
+	product.UpdateStock(5)
+	fetched, _ := repo.GetByID(ctx, product.ID)
+	if fetched.Stock != 10 {
+		t.Errorf("Update前の在庫数 = %v, want 10", fetched.Stock)
+	}
+
+	// 在庫の引き当ては取得済みの商品に影響しない
+	repo.ReserveStock(ctx, []entity.StockQuantity{{ProductID: product.ID, Quantity: 3}})
+	if fetched.Reserved != 0 {
+		t.Errorf("取得済みの商品の引き当て数 = %v, want 0", fetched.Reserved)
+	}
+	if again, _ := repo.GetByID(ctx, product.ID); again.Reserved != 3 {
+		t.Errorf("引き当て後の引き当て数 = %v, want 3", again.Reserved)
+	}
+}
+
 func TestProductRepository_Delete(t *testing.T) {
 	repo := NewProductRepository()
 	ctx := context.Background()
@@ -327,6 +352,35 @@ func TestProductRepository_UpdateStock(t *testing.T) {
 	}
 }
 
+func TestProductRepository_UpdateStockSettings(t *testing.T) {
+	repo := NewProductRepository()
+	ctx := context.Background()
+	product := entity.NewProduct("設定テスト商品", "説明", 1000, "image.jpg", 10)
+	product.LowStockThreshold = 5
+	repo.Create(ctx, product)
+
+	stock, threshold := 3, 8
+	if err := repo.UpdateStockSettings(ctx, product.ID, entity.StockSettings{Stock: &stock, LowStockThreshold: &threshold}); err != nil {
+		t.Fatalf("UpdateStockSettings() error = %v", err)
+	}
+	if updated, _ := repo.GetByID(ctx, product.ID); updated.Stock != 3 || updated.LowStockThreshold != 8 {
+		t.Errorf("Stock = %v, LowStockThreshold = %v, want 3, 8", updated.Stock, updated.LowStockThreshold)
+	}
+
+	// 指定しなかった項目は変更しない
+	stock = 7
+	if err := repo.UpdateStockSettings(ctx, product.ID, entity.StockSettings{Stock: &stock}); err != nil {
+		t.Fatalf("UpdateStockSettings() error = %v", err)
+	}
+	if updated, _ := repo.GetByID(ctx, product.ID); updated.Stock != 7 || updated.LowStockThreshold != 8 {
+		t.Errorf("Stock = %v, LowStockThreshold = %v, want 7, 8", updated.Stock, updated.LowStockThreshold)
+	}
+
+	if err := repo.UpdateStockSettings(ctx, "nonexistent-id", entity.StockSettings{Stock: &stock}); err != entity.ErrProductNotFound {
+		t.Errorf("存在しない商品: error = %v, want %v", err, entity.ErrProductNotFound)
+	}
+}
+
 func TestProductRepository_DecreaseStock(t *testing.T) {
 	repo := NewProductRepository()
 	ctx := context.Background()
@@ -344,18 +398,18 @@ func TestProductRepository_DecreaseStock(t *testing.T) {
 		expectedStock int
 	}{
 		{
-			name:          "在庫減少処理無効化確認1",
+			name:          "在庫を減らす",
 			productID:     testProduct.ID,
 			quantity:      3,
 			expectError:   false,
-			expectedStock: originalStock, // SLMハンズオン用に在庫は変更されない
+			expectedStock: originalStock - 3,
 		},
 		{
-			name:          "在庫減少処理無効化確認2",
+			name:          "在庫不足",
 			productID:     testProduct.ID,
-			quantity:      100, // 大きな数量でも成功する
-			expectError:   false,
-			expectedStock: originalStock, // SLMハンズオン用に在庫は変更されない
+			quantity:      100,
+			expectError:   true,
+			expectedStock: originalStock - 3,
 		},
 		{
 			name:          "存在しない商品",
@@ -379,13 +433,12 @@ func TestProductRepository_DecreaseStock(t *testing.T) {
 					t.Errorf("予期しないエラー: %v", err)
 				}
 
-				// SLMハンズオン用: 在庫は変更されないことを確認
 				product, err := repo.GetByID(ctx, tt.productID)
 				if err != nil {
 					t.Errorf("商品の取得でエラー: %v", err)
 				}
 				if product.Stock != tt.expectedStock {
-					t.Errorf("Stock = %v, want %v (在庫管理無効化のため変更されない)", product.Stock, tt.expectedStock)
+					t.Errorf("Stock = %v, want %v", product.Stock, tt.expectedStock)
 				}
 			}
 		})
@@ -508,3 +561,229 @@ func TestProductRepository_ConcurrentAccess(t *testing.T) {
 		t.Errorf("在庫が負の値になっています: %v", finalProduct.Stock)
 	}
 }
+
+func TestProductRepository_ReserveStock(t *testing.T) {
+	tests := []struct {
+		name             string
+		items            func(first, second *entity.Product) []entity.StockQuantity
+		expectedErr      error
+		expectedReserved [2]int
+	}{
+		{
+			name: "全商品を引き当てる",
+			items: func(first, second *entity.Product) []entity.StockQuantity {
+				return []entity.StockQuantity{{ProductID: first.ID, Quantity: 2}, {ProductID: second.ID, Quantity: 1}}
+			},
+			expectedReserved: [2]int{2, 1},
+		},
+		{
+			name: "1商品でも不足する場合は何も引き当てない",
+			items: func(first, second *entity.Product) []entity.StockQuantity {
+				return []entity.StockQuantity{{ProductID: first.ID, Quantity: 2}, {ProductID: second.ID, Quantity: 4}}
+			},
+			expectedErr: entity.ErrInsufficientStock,
+		},
+		{
+			name: "同じ商品の行は合算して確認する",
+			items: func(first, second *entity.Product) []entity.StockQuantity {
+				return []entity.StockQuantity{{ProductID: first.ID, Quantity: 3}, {ProductID: first.ID, Quantity: 3}}
+			},
+			expectedErr: entity.ErrInsufficientStock,
+		},
+		{
+			name: "存在しない商品",
+			items: func(first, second *entity.Product) []entity.StockQuantity {
+				return []entity.StockQuantity{{ProductID: first.ID, Quantity: 1}, {ProductID: "nonexistent-id", Quantity: 1}}
+			},
+			expectedErr: entity.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewProductRepository()
+			ctx := context.Background()
+			first := entity.NewProduct("引き当てテスト商品1", "説明", 1000, "image.jpg", 5)
+			second := entity.NewProduct("引き当てテスト商品2", "説明", 2000, "image.jpg", 3)
+			repo.Create(ctx, first)
+			repo.Create(ctx, second)
+
+			if err := repo.ReserveStock(ctx, tt.items(first, second)); err != tt.expectedErr {
+				t.Fatalf("ReserveStock() error = %v, want %v", err, tt.expectedErr)
+			}
+			first, _ = repo.GetByID(ctx, first.ID)
+			second, _ = repo.GetByID(ctx, second.ID)
+			if first.Reserved != tt.expectedReserved[0] || second.Reserved != tt.expectedReserved[1] {
+				t.Errorf("Reserved = [%v %v], want %v", first.Reserved, second.Reserved, tt.expectedReserved)
+			}
+		})
+	}
+}
+
+func TestProductRepository_CommitAndReleaseStock(t *testing.T) {
+	repo := NewProductRepository()
+	ctx := context.Background()
+	product := entity.NewProduct("確定テスト商品", "説明", 1000, "image.jpg", 10)
+	repo.Create(ctx, product)
+
+	items := []entity.StockQuantity{{ProductID: product.ID, Quantity: 3}}
+	if err := repo.ReserveStock(ctx, items); err != nil {
+		t.Fatalf("ReserveStock() error = %v", err)
+	}
+	if err := repo.ReserveStock(ctx, items); err != nil {
+		t.Fatalf("ReserveStock() error = %v", err)
+	}
+
+	// 1件目は決済完了で確定、2件目は決済失敗で解放
+	if err := repo.CommitStock(ctx, items); err != nil {
+		t.Fatalf("CommitStock() error = %v", err)
+	}
+	if err := repo.ReleaseStock(ctx, items); err != nil {
+		t.Fatalf("ReleaseStock() error = %v", err)
+	}
+
+	product, _ = repo.GetByID(ctx, product.ID)
+	if product.Stock != 7 || product.Reserved != 0 {
+		t.Errorf("Stock = %v, Reserved = %v, want 7, 0", product.Stock, product.Reserved)
+	}
+}
+
+func TestProductRepository_ConcurrentReserveStock(t *testing.T) {
+	repo := NewProductRepository()
+	ctx := context.Background()
+
+	const stock = 5
+	const numGoroutines = 50
+
+	product := entity.NewProduct("同時購入テスト商品", "説明", 1000, "image.jpg", stock)
+	repo.Create(ctx, product)
+
+	var (
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		succeeded int
+		failed    int
+	)
+	wg.Add(numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		go func() {
+			defer wg.Done()
+			err := repo.ReserveStock(ctx, []entity.StockQuantity{{ProductID: product.ID, Quantity: 1}})
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch err {
+			case nil:
+				succeeded++
+			case entity.ErrInsufficientStock:
+				failed++
+			default:
+				t.Errorf("予期しないエラー: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 在庫数を超えて引き当てない
+	if succeeded != stock {
+		t.Errorf("引き当て成功数 = %v, want %v", succeeded, stock)
+	}
+	if failed != numGoroutines-stock {
+		t.Errorf("在庫不足数 = %v, want %v", failed, numGoroutines-stock)
+	}
+	product, _ = repo.GetByID(ctx, product.ID)
+	if product.AvailableStock() != 0 {
+		t.Errorf("AvailableStock() = %v, want 0", product.AvailableStock())
+	}
+}
+
+func TestProductRepository_AdjustStock(t *testing.T) {
+	tests := []struct {
+		name          string
+		changes       func(first, second *entity.Product) []entity.StockChange
+		expectedErr   error
+		expectedStock [2]int
+	}{
+		{
+			name: "複数商品の在庫をまとめて増減",
+			changes: func(first, second *entity.Product) []entity.StockChange {
+				return []entity.StockChange{{ProductID: first.ID, Delta: -2}, {ProductID: second.ID, Delta: 10}}
+			},
+			expectedStock: [2]int{3, 13},
+		},
+		{
+			name: "1商品でも不足する場合は何も変更しない",
+			changes: func(first, second *entity.Product) []entity.StockChange {
+				return []entity.StockChange{{ProductID: first.ID, Delta: -2}, {ProductID: second.ID, Delta: -4}}
+			},
+			expectedErr:   entity.ErrInsufficientStock,
+			expectedStock: [2]int{5, 3},
+		},
+		{
+			name: "同じ商品の行は合算して確認する",
+			changes: func(first, second *entity.Product) []entity.StockChange {
+				return []entity.StockChange{{ProductID: first.ID, Delta: -4}, {ProductID: first.ID, Delta: 3}, {ProductID: first.ID, Delta: -5}}
+			},
+			expectedErr:   entity.ErrInsufficientStock,
+			expectedStock: [2]int{5, 3},
+		},
+		{
+			name: "存在しない商品がある場合は何も変更しない",
+			changes: func(first, second *entity.Product) []entity.StockChange {
+				return []entity.StockChange{{ProductID: first.ID, Delta: 1}, {ProductID: "nonexistent-id", Delta: 1}}
+			},
+			expectedErr:   entity.ErrProductNotFound,
+			expectedStock: [2]int{5, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewProductRepository()
+			ctx := context.Background()
+			first := entity.NewProduct("在庫調整テスト商品1", "説明", 1000, "image.jpg", 5)
+			second := entity.NewProduct("在庫調整テスト商品2", "説明", 2000, "image.jpg", 3)
+			repo.Create(ctx, first)
+			repo.Create(ctx, second)
+
+			if err := repo.AdjustStock(ctx, tt.changes(first, second)); err != tt.expectedErr {
+				t.Fatalf("AdjustStock() error = %v, want %v", err, tt.expectedErr)
+			}
+			first, _ = repo.GetByID(ctx, first.ID)
+			second, _ = repo.GetByID(ctx, second.ID)
+			if first.Stock != tt.expectedStock[0] || second.Stock != tt.expectedStock[1] {
+				t.Errorf("Stock = [%v %v], want %v", first.Stock, second.Stock, tt.expectedStock)
+			}
+		})
+	}
+}
+
+func TestProductRepository_ConcurrentAdjustStock(t *testing.T) {
+	repo := NewProductRepository()
+	ctx := context.Background()
+
+	const stock = 10
+	const numGoroutines = 30
+
+	first := entity.NewProduct("同時在庫調整テスト商品1", "説明", 1000, "image.jpg", stock)
+	second := entity.NewProduct("同時在庫調整テスト商品2", "説明", 1000, "image.jpg", stock)
+	repo.Create(ctx, first)
+	repo.Create(ctx, second)
+
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		go func() {
+			defer wg.Done()
+			repo.AdjustStock(ctx, []entity.StockChange{{ProductID: first.ID, Delta: -1}, {ProductID: second.ID, Delta: -1}})
+		}()
+	}
+	wg.Wait()
+
+	// 2商品は常に同じだけ減り、負にならない
+	first, _ = repo.GetByID(ctx, first.ID)
+	second, _ = repo.GetByID(ctx, second.ID)
+	if first.Stock != 0 || second.Stock != 0 {
+		t.Errorf("Stock = [%v %v], want [0 0]", first.Stock, second.Stock)
+	}
+}
@@ -0,0 +1,62 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
+)
+
+type cartRepository struct {
+	repo repository.CartRepository
+}
+
+func NewCartRepository(repo repository.CartRepository) repository.CartRepository {
+	return &cartRepository{repo: repo}
+}
+
+func (r *cartRepository) GetByID(ctx context.Context, id string) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartRepository.GetByID", attribute.String("cart.id", id))
+	result, err := r.repo.GetByID(ctx, id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (r *cartRepository) GetOrCreate(ctx context.Context, id string) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartRepository.GetOrCreate", attribute.String("cart.id", id))
+	result, err := r.repo.GetOrCreate(ctx, id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (r *cartRepository) Save(ctx context.Context, cart *entity.Cart) error {
+	ctx, span := startSpan(ctx, "CartRepository.Save", attribute.String("cart.id", cart.ID))
+	err := r.repo.Save(ctx, cart)
+	endSpan(span, err)
+	return err
+}
+
+func (r *cartRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "CartRepository.Delete", attribute.String("cart.id", id))
+	err := r.repo.Delete(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (r *cartRepository) Clear(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "CartRepository.Clear", attribute.String("cart.id", id))
+	err := r.repo.Clear(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (r *cartRepository) DeleteExpired(ctx context.Context, expiredBefore time.Time) ([]*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartRepository.DeleteExpired", attribute.String("cart.expired_before", expiredBefore.Format(time.RFC3339)))
+	result, err := r.repo.DeleteExpired(ctx, expiredBefore)
+	span.SetAttributes(attribute.Int("cart.expired_count", len(result)))
+	endSpan(span, err)
+	return result, err
+}
@@ -0,0 +1,53 @@
+package traced
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
+)
+
+type orderRepository struct {
+	repo repository.OrderRepository
+}
+
+func NewOrderRepository(repo repository.OrderRepository) repository.OrderRepository {
+	return &orderRepository{repo: repo}
+}
+
+func (r *orderRepository) GetAll(ctx context.Context) ([]*entity.Order, error) {
+	ctx, span := startSpan(ctx, "OrderRepository.GetAll")
+	result, err := r.repo.GetAll(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+func (r *orderRepository) GetByID(ctx context.Context, id string) (*entity.Order, error) {
+	ctx, span := startSpan(ctx, "OrderRepository.GetByID", attribute.String("order.id", id))
+	result, err := r.repo.GetByID(ctx, id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (r *orderRepository) Create(ctx context.Context, order *entity.Order) error {
+	ctx, span := startSpan(ctx, "OrderRepository.Create", attribute.String("order.id", order.ID))
+	err := r.repo.Create(ctx, order)
+	endSpan(span, err)
+	return err
+}
+
+func (r *orderRepository) Update(ctx context.Context, order *entity.Order) error {
+	ctx, span := startSpan(ctx, "OrderRepository.Update", attribute.String("order.id", order.ID))
+	err := r.repo.Update(ctx, order)
+	endSpan(span, err)
+	return err
+}
+
+func (r *orderRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "OrderRepository.Delete", attribute.String("order.id", id))
+	err := r.repo.Delete(ctx, id)
+	endSpan(span, err)
+	return err
+}
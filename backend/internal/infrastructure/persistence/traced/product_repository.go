@@ -0,0 +1,109 @@
+package traced
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
+)
+
+type productRepository struct {
+	repo repository.ProductRepository
+}
+
+func NewProductRepository(repo repository.ProductRepository) repository.ProductRepository {
+	return &productRepository{repo: repo}
+}
+
+func (r *productRepository) GetAll(ctx context.Context) ([]*entity.Product, error) {
+	ctx, span := startSpan(ctx, "ProductRepository.GetAll")
+	result, err := r.repo.GetAll(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+func (r *productRepository) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	ctx, span := startSpan(ctx, "ProductRepository.GetByID", attribute.String("product.id", id))
+	result, err := r.repo.GetByID(ctx, id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (r *productRepository) Create(ctx context.Context, product *entity.Product) error {
+	ctx, span := startSpan(ctx, "ProductRepository.Create", attribute.String("product.id", product.ID))
+	err := r.repo.Create(ctx, product)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) Update(ctx context.Context, product *entity.Product) error {
+	ctx, span := startSpan(ctx, "ProductRepository.Update", attribute.String("product.id", product.ID))
+	err := r.repo.Update(ctx, product)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "ProductRepository.Delete", attribute.String("product.id", id))
+	err := r.repo.Delete(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) UpdateStock(ctx context.Context, id string, newStock int) error {
+	ctx, span := startSpan(ctx, "ProductRepository.UpdateStock", attribute.String("product.id", id))
+	err := r.repo.UpdateStock(ctx, id, newStock)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) UpdateStockSettings(ctx context.Context, id string, settings entity.StockSettings) error {
+	ctx, span := startSpan(ctx, "ProductRepository.UpdateStockSettings", attribute.String("product.id", id))
+	err := r.repo.UpdateStockSettings(ctx, id, settings)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) DecreaseStock(ctx context.Context, id string, quantity int) error {
+	ctx, span := startSpan(ctx, "ProductRepository.DecreaseStock", attribute.String("product.id", id))
+	err := r.repo.DecreaseStock(ctx, id, quantity)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) IncreaseStock(ctx context.Context, id string, quantity int) error {
+	ctx, span := startSpan(ctx, "ProductRepository.IncreaseStock", attribute.String("product.id", id))
+	err := r.repo.IncreaseStock(ctx, id, quantity)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) ReserveStock(ctx context.Context, items []entity.StockQuantity) error {
+	ctx, span := startSpan(ctx, "ProductRepository.ReserveStock", attribute.Int("stock.items", len(items)))
+	err := r.repo.ReserveStock(ctx, items)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) CommitStock(ctx context.Context, items []entity.StockQuantity) error {
+	ctx, span := startSpan(ctx, "ProductRepository.CommitStock", attribute.Int("stock.items", len(items)))
+	err := r.repo.CommitStock(ctx, items)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) ReleaseStock(ctx context.Context, items []entity.StockQuantity) error {
+	ctx, span := startSpan(ctx, "ProductRepository.ReleaseStock", attribute.Int("stock.items", len(items)))
+	err := r.repo.ReleaseStock(ctx, items)
+	endSpan(span, err)
+	return err
+}
+
+func (r *productRepository) AdjustStock(ctx context.Context, changes []entity.StockChange) error {
+	ctx, span := startSpan(ctx, "ProductRepository.AdjustStock", attribute.Int("stock.changes", len(changes)))
+	err := r.repo.AdjustStock(ctx, changes)
+	endSpan(span, err)
+	return err
+}
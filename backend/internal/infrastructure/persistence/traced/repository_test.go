@@ -0,0 +1,70 @@
+package traced
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase/mocks"
+)
+
+func TestProductRepository_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	repo := NewProductRepository(&mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*entity.Product, error) {
+			if id == "missing" {
+				return nil, entity.ErrProductNotFound
+			}
+			return &entity.Product{ID: id}, nil
+		},
+	})
+
+	// 親スパンの子として記録される
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "GET /api/products/:id")
+	if _, err := repo.GetByID(ctx, "product-1"); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, "missing"); err != entity.ErrProductNotFound {
+		t.Fatalf("エラー = %v, want %v", err, entity.ErrProductNotFound)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("スパン数 = %v, want 3", len(spans))
+	}
+
+	tests := []struct {
+		name         string
+		index        int
+		expectedCode codes.Code
+	}{
+		{name: "成功", index: 0, expectedCode: codes.Unset},
+		{name: "エラーを記録", index: 1, expectedCode: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := spans[tt.index]
+			if span.Name() != "ProductRepository.GetByID" {
+				t.Errorf("スパン名 = %v, want ProductRepository.GetByID", span.Name())
+			}
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("親SpanID = %v, want %v", span.Parent().SpanID(), parent.SpanContext().SpanID())
+			}
+			if span.Status().Code != tt.expectedCode {
+				t.Errorf("ステータス = %v, want %v", span.Status().Code, tt.expectedCode)
+			}
+		})
+	}
+}
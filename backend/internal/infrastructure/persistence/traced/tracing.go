@@ -0,0 +1,30 @@
+// Package traced はリポジトリの呼び出しごとに OpenTelemetry の子スパンを作成するデコレーター
+package traced
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/persistence")
+
+// startSpan はリポジトリ操作のクライアントスパンを開始する
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attributes, attribute.String("db.system", "memory"))...),
+	)
+}
+
+// endSpan はエラーをスパンに記録して終了する
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/presenter"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+)
+
+type AdminHandler struct {
+	faults    *fault.Config
+	scenarios *fault.ScenarioRunner
+	telemetry monitoring.Telemetry
+}
+
+type UpdateFaultsRequest struct {
+	Profiles fault.Profiles `json:"profiles" binding:"required"`
+}
+
+type FaultsResponse struct {
+	Profiles  fault.Profiles `json:"profiles"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+func NewAdminHandler(faults *fault.Config, scenarios *fault.ScenarioRunner, telemetry monitoring.Telemetry) *AdminHandler {
+	return &AdminHandler{
+		faults:    faults,
+		scenarios: scenarios,
+		telemetry: telemetry,
+	}
+}
+
+func (h *AdminHandler) GetFaults(c *gin.Context) {
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+		"handler": "GetFaults",
+	})
+
+	presenter.SuccessResponse(c, http.StatusOK, h.faultsResponse())
+}
+
+// ReplaceFaults は全ルートのプロファイルを置き換える（指定のないルートは障害注入なし）
+func (h *AdminHandler) ReplaceFaults(c *gin.Context) {
+	h.updateFaults(c, "ReplaceFaults", h.faults.Replace)
+}
+
+// MergeFaults は指定されたルートのプロファイルのみ更新する
+func (h *AdminHandler) MergeFaults(c *gin.Context) {
+	h.updateFaults(c, "MergeFaults", h.faults.Merge)
+}
+
+func (h *AdminHandler) updateFaults(c *gin.Context, handlerName string, apply func(fault.Profiles) (previous, current fault.Profiles, err error)) {
+	var req UpdateFaultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		presenter.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+		"handler":          handlerName,
+		"fault.routeCount": len(req.Profiles),
+	})
+
+	// 変更前後のプロファイルは適用と同じロック内で取得したものを使う（同時に行われた別の変更を監査ログに含めない）
+	previous, current, err := apply(req.Profiles)
+	if err != nil {
+		presenter.BadRequestResponse(c, "Invalid fault profiles")
+		return
+	}
+
+	h.auditChanges(c, previous, current)
+
+	presenter.SuccessResponse(c, http.StatusOK, h.faultsResponse())
+}
+
+// GetScenario は実行中シナリオの現在・次のステップを返す
+func (h *AdminHandler) GetScenario(c *gin.Context) {
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+		"handler": "GetScenario",
+	})
+
+	status, err := h.scenarios.Status()
+	if err != nil {
+		if errors.Is(err, fault.ErrScenarioNotActive) {
+			presenter.NotFoundResponse(c, "No fault scenario has been started")
+			return
+		}
+
+		h.telemetry.NoticeError(c.Request.Context(), err)
+		presenter.InternalServerErrorResponse(c, "Failed to get fault scenario")
+		return
+	}
+
+	presenter.SuccessResponse(c, http.StatusOK, status)
+}
+
+// StartScenario はリクエストボディのシナリオを開始する（実行中のシナリオは置き換え）
+func (h *AdminHandler) StartScenario(c *gin.Context) {
+	var scenario fault.Scenario
+	if err := c.ShouldBindJSON(&scenario); err != nil {
+		presenter.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+		"handler":       "StartScenario",
+		"scenario.name": scenario.Name,
+	})
+
+	if err := h.scenarios.Start(&scenario); err != nil {
+		presenter.BadRequestResponse(c, "Invalid fault scenario")
+		return
+	}
+
+	log.Printf("Fault scenario %q started by %s (%d steps)", scenario.Name, c.ClientIP(), len(scenario.Steps))
+
+	status, _ := h.scenarios.Status()
+	presenter.SuccessResponse(c, http.StatusAccepted, status)
+}
+
+// StopScenario は実行中のシナリオを停止する（適用済みのプロファイルは維持）
+func (h *AdminHandler) StopScenario(c *gin.Context) {
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+		"handler": "StopScenario",
+	})
+
+	h.scenarios.Stop()
+
+	status, err := h.scenarios.Status()
+	if err != nil {
+		presenter.NotFoundResponse(c, "No fault scenario has been started")
+		return
+	}
+
+	log.Printf("Fault scenario %q stopped by %s", status.Scenario, c.ClientIP())
+	presenter.SuccessResponse(c, http.StatusOK, status)
+}
+
+// 変更されたルートごとに監査ログとNew Relicカスタムイベントを記録
+func (h *AdminHandler) auditChanges(c *gin.Context, previous, current fault.Profiles) {
+	for route, profile := range current {
+		before, existed := previous[route]
+		if existed && before == profile {
+			continue
+		}
+
+		log.Printf("Fault profile changed by %s: %s %+v -> %+v", c.ClientIP(), route, before, profile)
+		h.telemetry.RecordEvent(c.Request.Context(), "FaultConfigChange", map[string]interface{}{
+			"route":              route,
+			"action":             "set",
+			"errorRate":          profile.ErrorRate,
+			"statusCode":         profile.StatusCode,
+			"latencyMinMs":       profile.Latency.MinMs,
+			"latencyMaxMs":       profile.Latency.MaxMs,
+			"slowRate":           profile.Latency.SlowRate,
+			"previous.errorRate": before.ErrorRate,
+			"clientIp":           c.ClientIP(),
+		})
+	}
+
+	for route, before := range previous {
+		if _, exists := current[route]; exists {
+			continue
+		}
+
+		log.Printf("Fault profile removed by %s: %s %+v", c.ClientIP(), route, before)
+		h.telemetry.RecordEvent(c.Request.Context(), "FaultConfigChange", map[string]interface{}{
+			"route":              route,
+			"action":             "removed",
+			"previous.errorRate": before.ErrorRate,
+			"clientIp":           c.ClientIP(),
+		})
+	}
+}
+
+func (h *AdminHandler) faultsResponse() FaultsResponse {
+	return FaultsResponse{
+		Profiles:  h.faults.Get(),
+		UpdatedAt: h.faults.UpdatedAt(),
+	}
+}
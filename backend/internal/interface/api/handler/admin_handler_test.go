@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+func setupAdminRouter(faults *fault.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	adminHandler := NewAdminHandler(faults, fault.NewScenarioRunner(faults, nil), monitoring.NewMemoryTelemetry())
+	router.GET("/api/admin/faults", adminHandler.GetFaults)
+	router.PUT("/api/admin/faults", adminHandler.ReplaceFaults)
+	router.PATCH("/api/admin/faults", adminHandler.MergeFaults)
+	router.GET("/api/admin/scenario", adminHandler.GetScenario)
+	router.POST("/api/admin/scenario", adminHandler.StartScenario)
+	router.DELETE("/api/admin/scenario", adminHandler.StopScenario)
+	return router
+}
+
+func TestAdminHandler_GetFaults(t *testing.T) {
+	faults := fault.NewConfig(fault.Profiles{
+		"POST /api/orders": {ErrorRate: 0.1, Latency: fault.Latency{MinMs: 50, MaxMs: 500}},
+	}, utils.NewSeededRand(1))
+	router := setupAdminRouter(faults)
+
+	req, _ := http.NewRequest("GET", "/api/admin/faults", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ステータスコード = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Success bool           `json:"success"`
+		Data    FaultsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("JSONパースエラー: %v", err)
+	}
+	profile := response.Data.Profiles["POST /api/orders"]
+	if profile.ErrorRate != 0.1 {
+		t.Errorf("errorRate = %v, want 0.1", profile.ErrorRate)
+	}
+	if profile.Latency.MaxMs != 500 {
+		t.Errorf("latency.maxMs = %v, want 500", profile.Latency.MaxMs)
+	}
+}
+
+func TestAdminHandler_UpdateFaults(t *testing.T) {
+	initial := fault.Profiles{
+		"GET /api/products": {ErrorRate: 0.1},
+		"POST /api/orders":  {ErrorRate: 0.2},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expected       fault.Profiles
+	}{
+		{
+			name:           "PUTで全体を置き換え",
+			method:         "PUT",
+			body:           `{"profiles": {"GET /api/cart": {"errorRate": 0.5, "statusCode": 503}}}`,
+			expectedStatus: http.StatusOK,
+			expected:       fault.Profiles{"GET /api/cart": {ErrorRate: 0.5, StatusCode: 503}},
+		},
+		{
+			name:           "PATCHで指定ルートのみ更新",
+			method:         "PATCH",
+			body:           `{"profiles": {"POST /api/orders": {"errorRate": 0.3}}}`,
+			expectedStatus: http.StatusOK,
+			expected: fault.Profiles{
+				"GET /api/products": {ErrorRate: 0.1},
+				"POST /api/orders":  {ErrorRate: 0.3},
+			},
+		},
+		{
+			name:           "範囲外のエラー率",
+			method:         "PUT",
+			body:           `{"profiles": {"POST /api/orders": {"errorRate": 1.5}}}`,
+			expectedStatus: http.StatusBadRequest,
+			expected:       initial,
+		},
+		{
+			name:           "profilesがない",
+			method:         "PUT",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expected:       initial,
+		},
+		{
+			name:           "不正なJSON",
+			method:         "PATCH",
+			body:           `{invalid}`,
+			expectedStatus: http.StatusBadRequest,
+			expected:       initial,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faults := fault.NewConfig(initial, utils.NewSeededRand(1))
+			router := setupAdminRouter(faults)
+
+			req, _ := http.NewRequest(tt.method, "/api/admin/faults", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("ステータスコード = %v, want %v", w.Code, tt.expectedStatus)
+			}
+
+			current := faults.Get()
+			if len(current) != len(tt.expected) {
+				t.Fatalf("プロファイル数 = %v, want %v", len(current), len(tt.expected))
+			}
+			for route, profile := range tt.expected {
+				if current[route] != profile {
+					t.Errorf("%s = %+v, want %+v", route, current[route], profile)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminHandler_Scenario(t *testing.T) {
+	faults := fault.NewConfig(fault.Profiles{}, utils.NewSeededRand(1))
+	router := setupAdminRouter(faults)
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "開始前の状態取得",
+			method:         "GET",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "ステップのないシナリオ",
+			method:         "POST",
+			body:           `{"name": "empty", "steps": []}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "不正な時間指定",
+			method:         "POST",
+			body:           `{"name": "invalid", "steps": [{"name": "s", "at": "5 minutes", "profiles": {"POST /api/orders": {"errorRate": 0.3}}}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "シナリオ開始",
+			method:         "POST",
+			body:           `{"name": "order-incident", "steps": [{"name": "raise", "at": "1h", "for": "3m", "profiles": {"POST /api/orders": {"errorRate": 0.3}}}]}`,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "実行中の状態取得",
+			method:         "GET",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "シナリオ停止",
+			method:         "DELETE",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "/api/admin/scenario", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("ステータスコード = %v, want %v: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+		})
+	}
+
+	// 開始時刻前に停止したためプロファイルは変更されない
+	if len(faults.Get()) != 0 {
+		t.Errorf("プロファイル = %+v, want empty", faults.Get())
+	}
+}
@@ -1,20 +1,22 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/newrelic/go-agent/v3/newrelic"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/middleware"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/presenter"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase"
 )
 
 type CartHandler struct {
 	cartUseCase *usecase.CartUseCase
-	nrClient    *monitoring.NewRelicClient
+	telemetry   monitoring.Telemetry
 }
 
 type AddToCartRequest struct {
@@ -26,38 +28,66 @@ type UpdateCartItemRequest struct {
 	Quantity int `json:"quantity"`
 }
 
-// DefaultCartIDはconstants.goで定義
+// 一括追加できる商品数の上限（BatchAddToCartRequest の binding タグと合わせる）
+const maxBatchItems = 50
 
-func NewCartHandler(cartUseCase *usecase.CartUseCase, nrClient *monitoring.NewRelicClient) *CartHandler {
+// カートのアクション名（POST /api/cart/{action}）
+const (
+	BatchAddToCartAction = "items:batch"
+	ConfirmPricesAction  = "prices:confirm"
+)
+
+type BatchAddToCartRequest struct {
+	Items []AddToCartRequest `json:"items" binding:"required,min=1,max=50,dive"`
+}
+
+// CartResponse は価格変更の警告を含むカートの内容
+type CartResponse struct {
+	*entity.Cart
+	PriceChanges []entity.PriceChange `json:"priceChanges"`
+}
+
+func NewCartHandler(cartUseCase *usecase.CartUseCase, telemetry monitoring.Telemetry) *CartHandler {
 	return &CartHandler{
 		cartUseCase: cartUseCase,
-		nrClient:    nrClient,
+		telemetry:   telemetry,
 	}
 }
 
 func (h *CartHandler) GetCart(c *gin.Context) {
 	ctx := c.Request.Context()
-	cartID := DefaultCartID // 実際のアプリではユーザーセッションから取得
+	cartID := cartIDFromContext(c)
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "GetCart")
-		txn.AddAttribute("cart.id", cartID)
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "GetCart",
+		"cart.id": cartID,
+	})
 
 	cart, err := h.cartUseCase.GetCart(ctx, cartID)
 	if err != nil {
-		h.nrClient.NoticeError(err)
+		h.telemetry.NoticeError(ctx, err)
 		presenter.InternalServerErrorResponse(c, "Failed to get cart")
 		return
 	}
 
-	presenter.SuccessResponse(c, http.StatusOK, cart)
+	// カートに追加した時点から価格が変わった商品を警告として返す
+	priceChanges, err := h.cartUseCase.PriceChanges(ctx, cart)
+	if err != nil {
+		h.telemetry.NoticeError(ctx, err)
+		presenter.InternalServerErrorResponse(c, "Failed to get cart")
+		return
+	}
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"cart.priceChanges": len(priceChanges),
+	})
+
+	presenter.SuccessResponse(c, http.StatusOK, CartResponse{Cart: cart, PriceChanges: priceChanges})
 }
 
 func (h *CartHandler) AddToCart(c *gin.Context) {
 	ctx := c.Request.Context()
-	cartID := DefaultCartID
+	cartID := cartIDFromContext(c)
 
 	var req AddToCartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -65,43 +95,41 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 		return
 	}
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "AddToCart")
-		txn.AddAttribute("cart.id", cartID)
-		txn.AddAttribute("product.id", req.ProductID)
-		txn.AddAttribute("quantity", req.Quantity)
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":    "AddToCart",
+		"cart.id":    cartID,
+		"product.id": req.ProductID,
+		"quantity":   req.Quantity,
+	})
 
 	cart, err := h.cartUseCase.AddToCart(ctx, cartID, req.ProductID, req.Quantity)
 	if err != nil {
-		if err == entity.ErrProductNotFound {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrProductNotFound) {
 			presenter.NotFoundResponse(c, "Product not found")
 			return
 		}
-		if err == entity.ErrInsufficientStock {
+		if errors.Is(err, entity.ErrInsufficientStock) {
 			presenter.UnprocessableEntityResponse(c, "Insufficient stock")
 			return
 		}
 
-		h.nrClient.NoticeError(err)
 		presenter.InternalServerErrorResponse(c, "Failed to add item to cart")
 		return
 	}
 
-	// New Relic ビジネスメトリクス記録
-	userID := c.GetHeader("X-User-ID")
-	if userID == "" {
-		userID = "anonymous"
-	}
-	h.nrClient.RecordAddToCart(req.ProductID, req.Quantity, userID)
+	// ビジネスメトリクス記録
+	userID := userIDFromContext(c)
+	monitoring.RecordAddToCart(ctx, h.telemetry, req.ProductID, req.Quantity, userID)
 
 	presenter.SuccessResponse(c, http.StatusOK, cart)
 }
 
 func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 	ctx := c.Request.Context()
-	cartID := DefaultCartID
+	cartID := cartIDFromContext(c)
 	itemID := c.Param("id")
 
 	var req UpdateCartItemRequest
@@ -116,29 +144,205 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 		return
 	}
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "UpdateCartItem")
-		txn.AddAttribute("cart.id", cartID)
-		txn.AddAttribute("item.id", itemID)
-		txn.AddAttribute("quantity", req.Quantity)
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":  "UpdateCartItem",
+		"cart.id":  cartID,
+		"item.id":  itemID,
+		"quantity": req.Quantity,
+	})
 
 	cart, err := h.cartUseCase.UpdateCartItem(ctx, cartID, itemID, req.Quantity)
 	if err != nil {
-		if err == entity.ErrItemNotFound {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrItemNotFound) {
 			presenter.NotFoundResponse(c, "Cart item not found")
 			return
 		}
-		if err == entity.ErrInsufficientStock {
+		if errors.Is(err, entity.ErrInsufficientStock) {
 			presenter.UnprocessableEntityResponse(c, "Insufficient stock")
 			return
 		}
 
-		h.nrClient.NoticeError(err)
 		presenter.InternalServerErrorResponse(c, "Failed to update cart item")
 		return
 	}
 
 	presenter.SuccessResponse(c, http.StatusOK, cart)
 }
+
+// CartAction はアクション名に対応するカート操作を実行する（POST /api/cart/items:batch など）
+// gin はパスセグメント途中のコロンをルートに登録できないため /api/cart/:action で受けてアクション名で振り分ける
+func (h *CartHandler) CartAction(c *gin.Context) {
+	switch c.Param("action") {
+	case BatchAddToCartAction:
+		h.BatchAddToCart(c)
+	case ConfirmPricesAction:
+		h.ConfirmPrices(c)
+	default:
+		presenter.NotFoundResponse(c, "Not found")
+	}
+}
+
+// BatchAddToCart は複数の商品をまとめてカートに追加する（POST /api/cart/items:batch）
+func (h *CartHandler) BatchAddToCart(c *gin.Context) {
+	ctx := c.Request.Context()
+	cartID := cartIDFromContext(c)
+
+	var req BatchAddToCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		presenter.BadRequestResponse(c, fmt.Sprintf("Invalid request body (1-%d items with productId and quantity >= 1)", maxBatchItems))
+		return
+	}
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":   "BatchAddToCart",
+		"cart.id":   cartID,
+		"itemCount": len(req.Items),
+	})
+
+	items := make([]usecase.CartItemInput, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, usecase.CartItemInput{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	cart, err := h.cartUseCase.AddItemsToCart(ctx, cartID, items)
+	if err != nil {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrProductNotFound) {
+			presenter.NotFoundResponse(c, "Product not found")
+			return
+		}
+		if errors.Is(err, entity.ErrInvalidInput) {
+			presenter.BadRequestResponse(c, "Invalid cart items")
+			return
+		}
+		if errors.Is(err, entity.ErrInsufficientStock) {
+			presenter.UnprocessableEntityResponse(c, "Insufficient stock")
+			return
+		}
+
+		presenter.InternalServerErrorResponse(c, "Failed to add items to cart")
+		return
+	}
+
+	// ビジネスメトリクス記録
+	userID := userIDFromContext(c)
+	for _, item := range req.Items {
+		monitoring.RecordAddToCart(ctx, h.telemetry, item.ProductID, item.Quantity, userID)
+	}
+
+	presenter.SuccessResponse(c, http.StatusOK, cart)
+}
+
+// RemoveCartItem はカートから商品を削除する
+func (h *CartHandler) RemoveCartItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	cartID := cartIDFromContext(c)
+	itemID := c.Param("id")
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "RemoveCartItem",
+		"cart.id": cartID,
+		"item.id": itemID,
+	})
+
+	if _, err := h.cartUseCase.RemoveFromCart(ctx, cartID, itemID); err != nil {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrItemNotFound) {
+			presenter.NotFoundResponse(c, "Cart item not found")
+			return
+		}
+
+		presenter.InternalServerErrorResponse(c, "Failed to remove cart item")
+		return
+	}
+
+	presenter.NoContentResponse(c)
+}
+
+// ClearCart はカートを空にする（カートが存在しない場合も成功として扱う）
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	ctx := c.Request.Context()
+	cartID := cartIDFromContext(c)
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "ClearCart",
+		"cart.id": cartID,
+	})
+
+	if err := h.cartUseCase.ClearCart(ctx, cartID); err != nil {
+		// カートリポジトリはカートがない場合に ErrItemNotFound を返す
+		if errors.Is(err, entity.ErrItemNotFound) {
+			presenter.NotFoundResponse(c, "Cart not found")
+			return
+		}
+		h.telemetry.NoticeError(ctx, err)
+		presenter.InternalServerErrorResponse(c, "Failed to clear cart")
+		return
+	}
+
+	presenter.NoContentResponse(c)
+}
+
+// MergeCart は呼び出し元のログイン前のカート（X-Session-ID / cart_id Cookie）を認証済みユーザーのカートに統合する
+// 統合元は CartIDMiddleware が呼び出し元の識別情報から決定したカートのみとし、任意のカートIDは受け付けない
+func (h *CartHandler) MergeCart(c *gin.Context) {
+	ctx := c.Request.Context()
+	cartID := cartIDFromContext(c)
+	sourceCartID := c.GetString(middleware.AnonymousCartIDKey)
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":       "MergeCart",
+		"cart.id":       cartID,
+		"cart.sourceId": sourceCartID,
+	})
+
+	if sourceCartID == "" {
+		presenter.BadRequestResponse(c, "Authenticated user and anonymous cart identity (X-Session-ID header or cart_id cookie) are required")
+		return
+	}
+
+	cart, err := h.cartUseCase.MergeCarts(ctx, cartID, sourceCartID)
+	if err != nil {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrInvalidInput) {
+			presenter.BadRequestResponse(c, "Source cart must differ from the current cart")
+			return
+		}
+
+		presenter.InternalServerErrorResponse(c, "Failed to merge carts")
+		return
+	}
+
+	presenter.SuccessResponse(c, http.StatusOK, cart)
+}
+
+// ConfirmPrices はカート内の商品の単価を現在の価格に更新する（POST /api/cart/prices:confirm）
+func (h *CartHandler) ConfirmPrices(c *gin.Context) {
+	ctx := c.Request.Context()
+	cartID := cartIDFromContext(c)
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "ConfirmPrices",
+		"cart.id": cartID,
+	})
+
+	cart, err := h.cartUseCase.ConfirmPrices(ctx, cartID)
+	if err != nil {
+		h.telemetry.NoticeError(ctx, err)
+		presenter.InternalServerErrorResponse(c, "Failed to confirm prices")
+		return
+	}
+
+	presenter.SuccessResponse(c, http.StatusOK, cart)
+}
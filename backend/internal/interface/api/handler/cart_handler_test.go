@@ -2,12 +2,20 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/middleware"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase/mocks"
 )
 
 func TestCartHandler_GetCart(t *testing.T) {
@@ -310,3 +318,412 @@ func TestCartHandler_UpdateCartItem_NegativeQuantity(t *testing.T) {
 		t.Errorf("ステータスコード = %v, want %v", w.Code, http.StatusBadRequest)
 	}
 }
+
+// setupCartRouter は実際のハンドラーとモックリポジトリでカートAPIのルーターを作成する（middlewares は全ルートに適用する）
+func setupCartRouter(cartRepo *mocks.MockCartRepository, telemetry monitoring.Telemetry, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares...)
+
+	productRepo := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*entity.Product, error) {
+			prices := map[string]int{"product-1": 1000, "product-2": 2500}
+			price, exists := prices[id]
+			if !exists {
+				return nil, entity.ErrProductNotFound
+			}
+			product := entity.NewProduct(id, "説明", price, "image.jpg", 10)
+			product.ID = id
+			return product, nil
+		},
+	}
+
+	cartHandler := NewCartHandler(usecase.NewCartUseCase(cartRepo, productRepo, usecase.NewUnlimitedInventory()), telemetry)
+	router.GET("/api/cart", cartHandler.GetCart)
+	router.DELETE("/api/cart/items/:id", cartHandler.RemoveCartItem)
+	router.DELETE("/api/cart", cartHandler.ClearCart)
+	router.POST("/api/cart/merge", middleware.TrustedUserHeaderMiddleware(), middleware.CartIDMiddleware(middleware.CartFallbackShared, telemetry), cartHandler.MergeCart)
+	router.POST("/api/cart/:action", cartHandler.CartAction)
+	return router
+}
+
+func TestCartHandler_RemoveCartItem(t *testing.T) {
+	tests := []struct {
+		name           string
+		itemID         string
+		cartExists     bool
+		expectedStatus int
+	}{
+		{name: "カート内の商品を削除", itemID: "item-1", cartExists: true, expectedStatus: http.StatusNoContent},
+		{name: "存在しない商品は404", itemID: "item-999", cartExists: true, expectedStatus: http.StatusNotFound},
+		{name: "カートがない場合は404", itemID: "item-1", cartExists: false, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := entity.NewCart()
+			cart.ID = DefaultCartID
+			cart.AddItem(&entity.Product{ID: "product-1", Price: 1000}, 1)
+			cart.Items[0].ID = "item-1"
+
+			cartRepo := &mocks.MockCartRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					if !tt.cartExists {
+						return nil, entity.ErrItemNotFound
+					}
+					return cart, nil
+				},
+			}
+			router := setupCartRouter(cartRepo, monitoring.NewMemoryTelemetry())
+
+			req, _ := http.NewRequest("DELETE", "/api/cart/items/"+tt.itemID, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("ステータスコード = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus == http.StatusNoContent {
+				if w.Body.Len() != 0 {
+					t.Errorf("204のレスポンスボディが空でない: %s", w.Body.String())
+				}
+				if len(cartRepo.SaveCalls) != 1 || !cartRepo.SaveCalls[0].Cart.IsEmpty() {
+					t.Error("商品を削除したカートが保存されていない")
+				}
+			}
+		})
+	}
+}
+
+func TestCartHandler_ClearCart(t *testing.T) {
+	tests := []struct {
+		name           string
+		clearErr       error
+		expectedStatus int
+	}{
+		{name: "カートを空にする", expectedStatus: http.StatusNoContent},
+		{name: "カートがない場合は404", clearErr: entity.ErrItemNotFound, expectedStatus: http.StatusNotFound},
+		{name: "リポジトリエラーは500", clearErr: errors.New("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartRepo := &mocks.MockCartRepository{
+				ClearFunc: func(ctx context.Context, id string) error {
+					return tt.clearErr
+				},
+			}
+			telemetry := monitoring.NewMemoryTelemetry()
+			router := setupCartRouter(cartRepo, telemetry)
+
+			req, _ := http.NewRequest("DELETE", "/api/cart", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("ステータスコード = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if len(cartRepo.ClearCalls) != 1 || cartRepo.ClearCalls[0].ID != DefaultCartID {
+				t.Errorf("Clear呼び出し = %+v, want %s", cartRepo.ClearCalls, DefaultCartID)
+			}
+			if expectError := tt.expectedStatus == http.StatusInternalServerError; (len(telemetry.Errors()) > 0) != expectError {
+				t.Errorf("報告されたエラー = %v", telemetry.Errors())
+			}
+		})
+	}
+}
+
+func TestCartHandler_BatchAddToCart(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+		expectedTotal  int
+		expectedEvents int
+	}{
+		{
+			name:           "複数の商品をまとめて追加",
+			path:           "/api/cart/items:batch",
+			body:           `{"items": [{"productId": "product-1", "quantity": 2}, {"productId": "product-2", "quantity": 1}]}`,
+			expectedStatus: http.StatusOK,
+			expectedTotal:  4500,
+			expectedEvents: 2,
+		},
+		{
+			name:           "存在しない商品が含まれる場合は404",
+			path:           "/api/cart/items:batch",
+			body:           `{"items": [{"productId": "product-1", "quantity": 1}, {"productId": "missing", "quantity": 1}]}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "空の商品リストは400",
+			path:           "/api/cart/items:batch",
+			body:           `{"items": []}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "数量0は400",
+			path:           "/api/cart/items:batch",
+			body:           `{"items": [{"productId": "product-1", "quantity": 0}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "未知のアクションは404",
+			path:           "/api/cart/items:unknown",
+			body:           `{"items": [{"productId": "product-1", "quantity": 1}]}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartRepo := &mocks.MockCartRepository{
+				GetOrCreateFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					cart := entity.NewCart()
+					cart.ID = id
+					return cart, nil
+				},
+			}
+			telemetry := monitoring.NewMemoryTelemetry()
+			router := setupCartRouter(cartRepo, telemetry)
+
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("ステータスコード = %v, want %v: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if events := telemetry.Events(monitoring.EventAddToCart); len(events) != tt.expectedEvents {
+				t.Errorf("AddToCart イベント数 = %v, want %v", len(events), tt.expectedEvents)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if len(cartRepo.SaveCalls) != 0 {
+					t.Error("エラー時にカートが保存された")
+				}
+				return
+			}
+
+			var response struct {
+				Data entity.Cart `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("JSONパースエラー: %v", err)
+			}
+			if response.Data.TotalAmount != tt.expectedTotal {
+				t.Errorf("合計金額 = %v, want %v", response.Data.TotalAmount, tt.expectedTotal)
+			}
+		})
+	}
+}
+
+func TestCartHandler_BusinessMetricsUserID(t *testing.T) {
+	tests := []struct {
+		name           string
+		middlewares    []gin.HandlerFunc
+		userID         string
+		expectedUserID string
+	}{
+		{name: "認証済みユーザー", middlewares: []gin.HandlerFunc{middleware.TrustedUserHeaderMiddleware()}, userID: "user-1", expectedUserID: "user-1"},
+		{name: "認証プロキシを通らないヘッダーは使わない", userID: "user-1", expectedUserID: "anonymous"},
+		{name: "ユーザーなし", middlewares: []gin.HandlerFunc{middleware.TrustedUserHeaderMiddleware()}, expectedUserID: "anonymous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartRepo := &mocks.MockCartRepository{
+				GetOrCreateFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					cart := entity.NewCart()
+					cart.ID = id
+					return cart, nil
+				},
+			}
+			telemetry := monitoring.NewMemoryTelemetry()
+			router := setupCartRouter(cartRepo, telemetry, tt.middlewares...)
+
+			req, _ := http.NewRequest("POST", "/api/cart/items:batch", bytes.NewBufferString(`{"items": [{"productId": "product-1", "quantity": 1}]}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.userID != "" {
+				req.Header.Set(middleware.UserIDHeader, tt.userID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			events := telemetry.Events(monitoring.EventAddToCart)
+			if w.Code != http.StatusOK || len(events) != 1 {
+				t.Fatalf("ステータスコード = %v, AddToCart イベント数 = %v: %s", w.Code, len(events), w.Body.String())
+			}
+			if userID := events[0].Attributes["userId"]; userID != tt.expectedUserID {
+				t.Errorf("userId = %v, want %v", userID, tt.expectedUserID)
+			}
+		})
+	}
+}
+
+func TestCartHandler_MergeCart(t *testing.T) {
+	tests := []struct {
+		name             string
+		headers          map[string]string
+		body             string
+		expectedStatus   int
+		expectedSourceID string
+		expectedTotal    int
+	}{
+		{
+			name:             "ログイン前のセッションのカートを統合",
+			headers:          map[string]string{"X-User-ID": "user-42", "X-Session-ID": "anonymous-1"},
+			expectedStatus:   http.StatusOK,
+			expectedSourceID: "session-anonymous-1",
+			expectedTotal:    3000,
+		},
+		{
+			name:             "ボディで指定したカートIDは使わない",
+			headers:          map[string]string{"X-User-ID": "user-42", "X-Session-ID": "anonymous-1"},
+			body:             `{"sourceCartId": "user-7"}`,
+			expectedStatus:   http.StatusOK,
+			expectedSourceID: "session-anonymous-1",
+			expectedTotal:    3000,
+		},
+		{
+			name:           "匿名の識別情報がない場合は400",
+			headers:        map[string]string{"X-User-ID": "user-42"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "認証済みユーザーでない場合は400",
+			headers:        map[string]string{"X-Session-ID": "anonymous-1"},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := entity.NewCart()
+			target.ID = "user-user-42"
+			target.AddItem(&entity.Product{ID: "product-1", Price: 1000}, 1)
+
+			var sourceID string
+			cartRepo := &mocks.MockCartRepository{
+				GetOrCreateFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					return target, nil
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					sourceID = id
+					source := entity.NewCart()
+					source.ID = id
+					source.AddItem(&entity.Product{ID: "product-1", Price: 1000}, 2)
+					return source, nil
+				},
+			}
+			router := setupCartRouter(cartRepo, monitoring.NewMemoryTelemetry())
+
+			req, _ := http.NewRequest("POST", "/api/cart/merge", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("ステータスコード = %v, want %v: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			if sourceID != tt.expectedSourceID {
+				t.Errorf("統合元のカートID = %v, want %v", sourceID, tt.expectedSourceID)
+			}
+
+			var response struct {
+				Data entity.Cart `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("JSONパースエラー: %v", err)
+			}
+			if response.Data.TotalAmount != tt.expectedTotal {
+				t.Errorf("合計金額 = %v, want %v", response.Data.TotalAmount, tt.expectedTotal)
+			}
+		})
+	}
+}
+
+func TestCartHandler_PriceChanges(t *testing.T) {
+	// カートに追加した時点の単価は800円、現在の価格は1000円
+	cart := entity.NewCart()
+	cart.ID = DefaultCartID
+	cart.AddItem(&entity.Product{ID: "product-1", Price: 800}, 2)
+
+	cartRepo := &mocks.MockCartRepository{
+		GetOrCreateFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+			return cart, nil
+		},
+	}
+	router := setupCartRouter(cartRepo, monitoring.NewMemoryTelemetry())
+
+	getCart := func() CartResponse {
+		req, _ := http.NewRequest("GET", "/api/cart", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("ステータスコード = %v, want %v", w.Code, http.StatusOK)
+		}
+
+		var response struct {
+			Data CartResponse `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("JSONパースエラー: %v", err)
+		}
+		return response.Data
+	}
+
+	tests := []struct {
+		name                 string
+		confirm              bool
+		expectedPriceChanges []entity.PriceChange
+		expectedTotal        int
+	}{
+		{
+			name: "価格変更を警告として返す",
+			expectedPriceChanges: []entity.PriceChange{
+				{ItemID: cart.Items[0].ID, ProductID: "product-1", OldPrice: 800, NewPrice: 1000, Difference: 200},
+			},
+			expectedTotal: 1600,
+		},
+		{
+			name:                 "確認後は新しい価格で計算",
+			confirm:              true,
+			expectedPriceChanges: []entity.PriceChange{},
+			expectedTotal:        2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.confirm {
+				req, _ := http.NewRequest("POST", "/api/cart/prices:confirm", nil)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					t.Fatalf("価格確認: ステータスコード = %v, want %v", w.Code, http.StatusOK)
+				}
+			}
+
+			response := getCart()
+			if len(response.PriceChanges) != len(tt.expectedPriceChanges) {
+				t.Fatalf("価格変更 = %+v, want %+v", response.PriceChanges, tt.expectedPriceChanges)
+			}
+			for i, expected := range tt.expectedPriceChanges {
+				if response.PriceChanges[i] != expected {
+					t.Errorf("価格変更 = %+v, want %+v", response.PriceChanges[i], expected)
+				}
+			}
+			if response.Cart.TotalAmount != tt.expectedTotal {
+				t.Errorf("合計金額 = %v, want %v", response.Cart.TotalAmount, tt.expectedTotal)
+			}
+		})
+	}
+}
@@ -1,4 +1,27 @@
 package handler
 
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/middleware"
+)
+
 // 共通定数
-const DefaultCartID = "default" // シンプルな実装のため固定カートIDを使用
+const DefaultCartID = "default" // CartIDMiddleware を通らない場合の共有カートID
+
+// cartIDFromContext は CartIDMiddleware が決定したカートIDを返す
+func cartIDFromContext(c *gin.Context) string {
+	if cartID := c.GetString(middleware.CartIDKey); cartID != "" {
+		return cartID
+	}
+	return DefaultCartID
+}
+
+// userIDFromContext は TrustedUserHeaderMiddleware が設定した認証済みのユーザーIDを返す（ビジネスメトリクス用）
+// 認証プロキシを通らない X-User-ID ヘッダーは使わず、認証済みユーザーがいない場合は anonymous を返す
+func userIDFromContext(c *gin.Context) string {
+	if userID := c.GetString(middleware.UserIDKey); userID != "" {
+		return userID
+	}
+	return "anonymous"
+}
@@ -1,10 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/newrelic/go-agent/v3/newrelic"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
@@ -14,7 +14,7 @@ import (
 
 type OrderHandler struct {
 	orderUseCase *usecase.OrderUseCase
-	nrClient     *monitoring.NewRelicClient
+	telemetry    monitoring.Telemetry
 }
 
 type CreateOrderRequest struct {
@@ -26,43 +26,62 @@ type CreateOrderItem struct {
 	Quantity  int    `json:"quantity" binding:"required,min=1"`
 }
 
-// cart_handlerと同じDefaultCartIDを使用
+// CancelOrderRequest はキャンセル理由（ボディは省略可）
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+// UpdateOrderStatusRequest は管理用のステータス変更
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"max=200"`
+}
 
-func NewOrderHandler(orderUseCase *usecase.OrderUseCase, nrClient *monitoring.NewRelicClient) *OrderHandler {
+func NewOrderHandler(orderUseCase *usecase.OrderUseCase, telemetry monitoring.Telemetry) *OrderHandler {
 	return &OrderHandler{
 		orderUseCase: orderUseCase,
-		nrClient:     nrClient,
+		telemetry:    telemetry,
 	}
 }
 
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	ctx := c.Request.Context()
-	cartID := DefaultCartID // 実際のアプリではユーザーセッションから取得
+	cartID := cartIDFromContext(c)
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "CreateOrder")
-		txn.AddAttribute("cart.id", cartID)
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "CreateOrder",
+		"cart.id": cartID,
+	})
 
 	order, err := h.orderUseCase.CreateOrder(ctx, cartID)
 	if err != nil {
-		if err == entity.ErrEmptyCart {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrEmptyCart) {
 			presenter.UnprocessableEntityResponse(c, "Cart is empty")
 			return
 		}
+		if errors.Is(err, entity.ErrInsufficientStock) {
+			presenter.UnprocessableEntityResponse(c, "Insufficient stock")
+			return
+		}
+		if errors.Is(err, entity.ErrPriceChanged) {
+			presenter.ErrorResponse(c, http.StatusConflict, "PRICE_CHANGED", "Product prices have changed; review the cart and confirm the new prices")
+			return
+		}
+		if errors.Is(err, entity.ErrPaymentUnavailable) {
+			presenter.ErrorResponse(c, http.StatusServiceUnavailable, "PAYMENT_UNAVAILABLE", "Payment gateway is unavailable, please retry")
+			return
+		}
 
-		h.nrClient.NoticeError(err)
 		presenter.InternalServerErrorResponse(c, "Failed to create order")
 		return
 	}
 
-	// New Relic ビジネスメトリクス記録
-	userID := c.GetHeader("X-User-ID")
-	if userID == "" {
-		userID = "anonymous"
-	}
-	h.nrClient.RecordPurchase(
+	// ビジネスメトリクス記録
+	userID := userIDFromContext(c)
+	monitoring.RecordPurchase(ctx, h.telemetry,
 		order.ID,
 		float64(order.TotalAmount),
 		order.GetItemCount(),
@@ -76,11 +95,11 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 	ctx := c.Request.Context()
 	orderID := c.Param("id")
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "GetOrder")
-		txn.AddAttribute("order.id", orderID)
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":  "GetOrder",
+		"order.id": orderID,
+	})
 
 	if orderID == "" {
 		presenter.BadRequestResponse(c, "Order ID is required")
@@ -89,12 +108,13 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 
 	order, err := h.orderUseCase.GetOrder(ctx, orderID)
 	if err != nil {
-		if err == entity.ErrOrderNotFound {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrOrderNotFound) {
 			presenter.NotFoundResponse(c, "Order not found")
 			return
 		}
 
-		h.nrClient.NoticeError(err)
 		presenter.InternalServerErrorResponse(c, "Failed to get order")
 		return
 	}
@@ -105,17 +125,121 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 func (h *OrderHandler) GetOrders(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "GetOrders")
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "GetOrders",
+	})
 
 	orders, err := h.orderUseCase.GetAllOrders(ctx)
 	if err != nil {
-		h.nrClient.NoticeError(err)
+		h.telemetry.NoticeError(ctx, err)
 		presenter.InternalServerErrorResponse(c, "Failed to get orders")
 		return
 	}
 
 	presenter.SuccessResponse(c, http.StatusOK, orders)
 }
+
+// CancelOrder は出荷前の注文をキャンセルする（出荷済み・キャンセル済みなどは 409）
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID := c.Param("id")
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":  "CancelOrder",
+		"order.id": orderID,
+	})
+
+	var req CancelOrderRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			presenter.BadRequestResponse(c, "Invalid request body")
+			return
+		}
+	}
+
+	order, err := h.orderUseCase.CancelOrder(ctx, orderID, req.Reason)
+	if err != nil {
+		h.respondTransitionError(c, err, "Failed to cancel order")
+		return
+	}
+
+	presenter.SuccessResponse(c, http.StatusOK, order)
+}
+
+// UpdateOrderStatus は注文のステータスを遷移表に従って変更する（出荷・配達・返金などの管理操作）
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID := c.Param("id")
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":  "UpdateOrderStatus",
+		"order.id": orderID,
+	})
+
+	var req UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		presenter.BadRequestResponse(c, "Invalid request body (status is required)")
+		return
+	}
+
+	order, err := h.orderUseCase.TransitionOrder(ctx, orderID, entity.OrderStatus(req.Status), req.Reason)
+	if err != nil {
+		h.respondTransitionError(c, err, "Failed to update order status")
+		return
+	}
+
+	presenter.SuccessResponse(c, http.StatusOK, order)
+}
+
+// GetPaymentDeadLetters は再試行を使い切った決済ジョブの一覧を返す
+func (h *OrderHandler) GetPaymentDeadLetters(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "GetPaymentDeadLetters",
+	})
+
+	presenter.SuccessResponse(c, http.StatusOK, h.orderUseCase.PaymentDeadLetters(ctx))
+}
+
+// RequeuePayment はデッドレターの注文の決済を再実行する
+func (h *OrderHandler) RequeuePayment(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID := c.Param("orderId")
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":  "RequeuePayment",
+		"order.id": orderID,
+	})
+
+	order, err := h.orderUseCase.RequeuePayment(ctx, orderID)
+	if err != nil {
+		h.respondTransitionError(c, err, "Failed to requeue payment")
+		return
+	}
+
+	presenter.SuccessResponse(c, http.StatusAccepted, order)
+}
+
+// respondTransitionError は注文のステータス変更のエラーをレスポンスに変換する
+func (h *OrderHandler) respondTransitionError(c *gin.Context, err error, message string) {
+	h.telemetry.NoticeError(c.Request.Context(), err)
+
+	switch {
+	case errors.Is(err, entity.ErrOrderNotFound):
+		presenter.NotFoundResponse(c, "Order not found")
+	case errors.Is(err, entity.ErrInvalidOrderStatus):
+		presenter.ErrorResponse(c, http.StatusConflict, "INVALID_ORDER_STATUS", "Order status does not allow this transition")
+	case errors.Is(err, entity.ErrInvalidInput):
+		presenter.BadRequestResponse(c, "Invalid order ID or status")
+	case errors.Is(err, entity.ErrPaymentUnavailable):
+		presenter.ErrorResponse(c, http.StatusServiceUnavailable, "PAYMENT_UNAVAILABLE", "Payment gateway is unavailable, please retry")
+	default:
+		presenter.InternalServerErrorResponse(c, message)
+	}
+}
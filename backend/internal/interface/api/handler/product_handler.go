@@ -1,11 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/newrelic/go-agent/v3/newrelic"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
@@ -15,33 +15,48 @@ import (
 
 type ProductHandler struct {
 	productUseCase *usecase.ProductUseCase
-	nrClient       *monitoring.NewRelicClient
+	telemetry      monitoring.Telemetry
 }
 
-func NewProductHandler(productUseCase *usecase.ProductUseCase, nrClient *monitoring.NewRelicClient) *ProductHandler {
+// UpdateStockRequest は在庫数・在庫僅少の閾値の設定（少なくとも一方を指定）
+type UpdateStockRequest struct {
+	Stock             *int `json:"stock"`
+	LowStockThreshold *int `json:"lowStockThreshold"`
+}
+
+type StockChangeRequest struct {
+	ProductID string `json:"productId" binding:"required"`
+	Delta     int    `json:"delta"`
+}
+
+type AdjustStockRequest struct {
+	Changes []StockChangeRequest `json:"changes" binding:"required,min=1,max=100,dive"`
+}
+
+func NewProductHandler(productUseCase *usecase.ProductUseCase, telemetry monitoring.Telemetry) *ProductHandler {
 	return &ProductHandler{
 		productUseCase: productUseCase,
-		nrClient:       nrClient,
+		telemetry:      telemetry,
 	}
 }
 
 func (h *ProductHandler) GetProducts(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "GetProducts")
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "GetProducts",
+	})
 
 	products, err := h.productUseCase.GetAllProducts(ctx)
 	if err != nil {
-		h.nrClient.NoticeError(err)
+		h.telemetry.NoticeError(ctx, err)
 		presenter.InternalServerErrorResponse(c, "Failed to get products")
 		return
 	}
 
-	// New Relic カスタムイベント記録
-	h.nrClient.RecordCustomEvent("ProductListView", map[string]interface{}{
+	// カスタムイベント記録
+	h.telemetry.RecordEvent(ctx, "ProductListView", map[string]interface{}{
 		"productCount": len(products),
 		"userAgent":    c.GetHeader("User-Agent"),
 	})
@@ -53,11 +68,11 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	ctx := c.Request.Context()
 	productID := c.Param("id")
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "GetProduct")
-		txn.AddAttribute("product.id", productID)
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":    "GetProduct",
+		"product.id": productID,
+	})
 
 	if productID == "" {
 		presenter.BadRequestResponse(c, "Product ID is required")
@@ -66,41 +81,122 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 
 	product, err := h.productUseCase.GetProductByID(ctx, productID)
 	if err != nil {
-		if err == entity.ErrProductNotFound {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrProductNotFound) {
 			presenter.NotFoundResponse(c, "Product not found")
 			return
 		}
 
-		h.nrClient.NoticeError(err)
 		presenter.InternalServerErrorResponse(c, "Failed to get product")
 		return
 	}
 
-	// New Relic ビジネスメトリクス記録
-	userID := c.GetHeader("X-User-ID") // 実際のアプリではセッションから取得
-	if userID == "" {
-		userID = "anonymous"
+	// ビジネスメトリクス記録
+	userID := userIDFromContext(c)
+	monitoring.RecordProductView(ctx, h.telemetry, productID, userID)
+
+	presenter.SuccessResponse(c, http.StatusOK, product)
+}
+
+// UpdateStock は商品の在庫数・在庫僅少の閾値を設定する（INVENTORY_MODE=tracked で在庫切れを発生させる）
+func (h *ProductHandler) UpdateStock(c *gin.Context) {
+	ctx := c.Request.Context()
+	productID := c.Param("id")
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":    "UpdateStock",
+		"product.id": productID,
+	})
+
+	var req UpdateStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Stock == nil && req.LowStockThreshold == nil) {
+		presenter.BadRequestResponse(c, "Invalid request body (stock or lowStockThreshold is required)")
+		return
+	}
+
+	// 在庫数と閾値はまとめて設定する（一方だけが反映されることはない）
+	product, err := h.productUseCase.UpdateStock(ctx, productID, entity.StockSettings{Stock: req.Stock, LowStockThreshold: req.LowStockThreshold})
+	if err != nil {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrProductNotFound) {
+			presenter.NotFoundResponse(c, "Product not found")
+			return
+		}
+		if errors.Is(err, entity.ErrInvalidInput) {
+			presenter.BadRequestResponse(c, "Stock and lowStockThreshold must be zero or greater")
+			return
+		}
+
+		presenter.InternalServerErrorResponse(c, "Failed to update stock")
+		return
 	}
-	h.nrClient.RecordProductView(productID, userID)
 
 	presenter.SuccessResponse(c, http.StatusOK, product)
 }
 
+// AdjustStock は複数商品の在庫数をまとめて増減する（入荷・棚卸しの反映用）
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		presenter.BadRequestResponse(c, "Invalid request body (1-100 changes with productId and delta)")
+		return
+	}
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler":       "AdjustStock",
+		"stock.changes": len(req.Changes),
+	})
+
+	changes := make([]entity.StockChange, len(req.Changes))
+	for i, change := range req.Changes {
+		changes[i] = entity.StockChange{ProductID: change.ProductID, Delta: change.Delta}
+	}
+
+	products, err := h.productUseCase.AdjustStock(ctx, changes)
+	if err != nil {
+		h.telemetry.NoticeError(ctx, err)
+
+		if errors.Is(err, entity.ErrProductNotFound) {
+			presenter.NotFoundResponse(c, "Product not found")
+			return
+		}
+		if errors.Is(err, entity.ErrInsufficientStock) {
+			presenter.UnprocessableEntityResponse(c, "Stock cannot go below the reserved quantity")
+			return
+		}
+		if errors.Is(err, entity.ErrInvalidInput) {
+			presenter.BadRequestResponse(c, "Invalid stock changes")
+			return
+		}
+
+		presenter.InternalServerErrorResponse(c, "Failed to adjust stock")
+		return
+	}
+
+	presenter.SuccessResponse(c, http.StatusOK, products)
+}
+
 // SLMデモ用のエラー発生エンドポイント
 func (h *ProductHandler) TriggerError(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// New Relic トランザクションにカスタム属性を追加
-	if txn := newrelic.FromContext(ctx); txn != nil {
-		txn.AddAttribute("handler", "TriggerError")
-		txn.AddAttribute("demo", "error_simulation")
-	}
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(ctx, map[string]interface{}{
+		"handler": "TriggerError",
+		"demo":    "error_simulation",
+	})
 
 	// 意図的にエラーを発生させる
 	err := fmt.Errorf("simulated error for SLM demonstration")
-	h.nrClient.NoticeError(err)
+	h.telemetry.NoticeError(ctx, err)
 
-	h.nrClient.RecordError("DemoError", "Intentional error for SLM testing", map[string]interface{}{
+	monitoring.RecordError(ctx, h.telemetry, "DemoError", "Intentional error for SLM testing", map[string]interface{}{
 		"endpoint":  "/api/v1/error",
 		"userAgent": c.GetHeader("User-Agent"),
 	})
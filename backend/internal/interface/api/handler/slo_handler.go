@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/presenter"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/slo"
+)
+
+type SLOHandler struct {
+	evaluator *slo.Evaluator
+	alerter   *slo.Alerter
+	telemetry monitoring.Telemetry
+}
+
+type SLOResponse struct {
+	Objectives  []slo.Status `json:"objectives"`
+	EvaluatedAt time.Time    `json:"evaluatedAt"`
+}
+
+type SLOAlertsResponse struct {
+	Alerts []slo.Alert `json:"alerts"`
+	Firing int         `json:"firing"`
+}
+
+func NewSLOHandler(evaluator *slo.Evaluator, alerter *slo.Alerter, telemetry monitoring.Telemetry) *SLOHandler {
+	return &SLOHandler{
+		evaluator: evaluator,
+		alerter:   alerter,
+		telemetry: telemetry,
+	}
+}
+
+// GetSLOs はプロセス内で計測したSLIからSLOの達成率・残りエラーバジェット・バーンレートを返す
+func (h *SLOHandler) GetSLOs(c *gin.Context) {
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+		"handler": "GetSLOs",
+	})
+
+	presenter.SuccessResponse(c, http.StatusOK, SLOResponse{
+		Objectives:  h.evaluator.Evaluate(),
+		EvaluatedAt: time.Now(),
+	})
+}
+
+// GetAlerts はバーンレートアラートの発報状態を返す（最後の定期評価時点）
+func (h *SLOHandler) GetAlerts(c *gin.Context) {
+	alerts := h.alerter.Alerts()
+
+	firing := 0
+	for _, alert := range alerts {
+		if alert.State == slo.AlertFiring {
+			firing++
+		}
+	}
+
+	// トランザクションにカスタム属性を追加
+	h.telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+		"handler":           "GetAlerts",
+		"slo.alerts.firing": firing,
+	})
+
+	presenter.SuccessResponse(c, http.StatusOK, SLOAlertsResponse{
+		Alerts: alerts,
+		Firing: firing,
+	})
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/slo"
+)
+
+func TestSLOHandler_GetSLOs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	objectives := slo.DefaultObjectives()
+	recorder := slo.NewRecorder(objectives)
+	recorder.Record("GET /api/products", http.StatusOK, 100*time.Millisecond)
+	recorder.Record("GET /api/products", http.StatusInternalServerError, 100*time.Millisecond)
+
+	evaluator := slo.NewEvaluator(objectives, recorder)
+	sloHandler := NewSLOHandler(evaluator, slo.NewAlerter(evaluator, slo.DefaultAlertRules(objectives)), monitoring.NewMemoryTelemetry())
+	router := gin.New()
+	router.GET("/api/slo", sloHandler.GetSLOs)
+
+	req, _ := http.NewRequest("GET", "/api/slo", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ステータスコード = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Success bool        `json:"success"`
+		Data    SLOResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("JSONパースエラー: %v", err)
+	}
+
+	if len(response.Data.Objectives) != len(objectives) {
+		t.Fatalf("SLO数 = %v, want %v", len(response.Data.Objectives), len(objectives))
+	}
+	availability := response.Data.Objectives[0]
+	if availability.Name != "api-availability" || availability.Total != 2 || availability.Good != 1 {
+		t.Errorf("availability = %+v, want total=2 good=1", availability)
+	}
+	if availability.Attainment != 0.5 {
+		t.Errorf("attainment = %v, want 0.5", availability.Attainment)
+	}
+}
+
+func TestSLOHandler_GetAlerts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	objectives := slo.DefaultObjectives()
+	recorder := slo.NewRecorder(objectives)
+	for i := 0; i < 10; i++ {
+		recorder.Record("POST /api/orders", http.StatusInternalServerError, 100*time.Millisecond)
+	}
+
+	evaluator := slo.NewEvaluator(objectives, recorder)
+	alerter := slo.NewAlerter(evaluator, slo.DefaultAlertRules(objectives))
+	alerter.Evaluate(context.Background())
+
+	sloHandler := NewSLOHandler(evaluator, alerter, monitoring.NewMemoryTelemetry())
+	router := gin.New()
+	router.GET("/api/slo/alerts", sloHandler.GetAlerts)
+
+	req, _ := http.NewRequest("GET", "/api/slo/alerts", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ステータスコード = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    SLOAlertsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("JSONパースエラー: %v", err)
+	}
+
+	// 可用性SLOの3ルールのみ発報（レイテンシは閾値以内）
+	if len(response.Data.Alerts) != 6 {
+		t.Fatalf("アラート数 = %v, want 6", len(response.Data.Alerts))
+	}
+	if response.Data.Firing != 3 {
+		t.Errorf("firing = %v, want 3", response.Data.Firing)
+	}
+	if response.Data.Alerts[0].State != slo.AlertFiring {
+		t.Errorf("%s state = %v, want firing", response.Data.Alerts[0].Rule.Name, response.Data.Alerts[0].State)
+	}
+}
@@ -118,6 +118,12 @@ paths:
                           type: string
                         stock:
                           type: integer
+                        reserved:
+                          type: integer
+                          description: 決済待ちの注文で引き当て済みの数量
+                        lowStockThreshold:
+                          type: integer
+                          description: 在庫僅少の閾値（0 の場合は LOW_STOCK_THRESHOLD）
                         createdAt:
                           type: string
                           format: date-time
@@ -136,6 +142,8 @@ paths:
                         price: 25000
                         imageUrl: "/images/headphones.svg"
                         stock: 10
+                        reserved: 0
+                        lowStockThreshold: 0
                         createdAt: "2025-07-30T04:20:19Z"
                         updatedAt: "2025-07-30T04:20:19Z"
                       - id: "5cfac614-a3d7-4230-a2a4-5b633834d1d2"
@@ -144,6 +152,8 @@ paths:
                         price: 35000
                         imageUrl: "/images/smartwatch.svg"
                         stock: 5
+                        reserved: 0
+                        lowStockThreshold: 0
                         createdAt: "2025-07-30T04:20:19Z"
                         updatedAt: "2025-07-30T04:20:19Z"
         '500':
@@ -214,6 +224,12 @@ paths:
                         type: string
                       stock:
                         type: integer
+                      reserved:
+                        type: integer
+                        description: 決済待ちの注文で引き当て済みの数量
+                      lowStockThreshold:
+                        type: integer
+                        description: 在庫僅少の閾値（0 の場合は LOW_STOCK_THRESHOLD）
                       createdAt:
                         type: string
                         format: date-time
@@ -232,6 +248,8 @@ paths:
                       price: 25000
                       imageUrl: "/images/headphones.svg"
                       stock: 10
+                      reserved: 0
+                      lowStockThreshold: 0
                       createdAt: "2025-07-30T04:20:19Z"
                       updatedAt: "2025-07-30T04:20:19Z"
         '404':
@@ -266,6 +284,19 @@ paths:
       description: 現在のカート内容を取得します。ユーザージャーニーの重要な部分です。
       tags:
         - Cart
+      parameters:
+        - name: X-User-ID
+          in: header
+          required: false
+          description: ユーザーID（CART_TRUST_USER_HEADER=true の場合のみ認証済みユーザーとしてユーザー単位のカートを使用、anonymous は除く。値は検証しないため認証プロキシの背後でのみ有効にする）
+          schema:
+            type: string
+        - name: X-Session-ID
+          in: header
+          required: false
+          description: セッションID（X-User-ID がない場合にセッション単位のカートを使用）。どちらもない場合は cart_id Cookie（cart-{値}）、それもない場合は CART_FALLBACK に従う。決定したカートIDは X-Cart-ID ヘッダーで返す
+          schema:
+            type: string
       responses:
         '200':
           description: カート内容の取得に成功
@@ -295,6 +326,9 @@ paths:
                               format: uuid
                             product:
                               type: object
+                            unitPrice:
+                              type: integer
+                              description: カートに追加した時点の単価（合計金額・注文金額はこの単価で計算）
                             quantity:
                               type: integer
                             subtotal:
@@ -309,6 +343,22 @@ paths:
                       updatedAt:
                         type: string
                         format: date-time
+                      priceChanges:
+                        type: array
+                        description: カートに追加した時点から価格が変わった商品
+                        items:
+                          type: object
+                          properties:
+                            itemId:
+                              type: string
+                            productId:
+                              type: string
+                            oldPrice:
+                              type: integer
+                            newPrice:
+                              type: integer
+                            difference:
+                              type: integer
               examples:
                 with_items:
                   summary: アイテムが入ったカート
@@ -323,12 +373,37 @@ paths:
                             id: "fa03a45e-8138-41b7-b2af-ed63881fb5f9"
                             name: "ワイヤレスヘッドホン"
                             price: 25000
+                          unitPrice: 25000
                           quantity: 2
                           subtotal: 50000
                           addedAt: "2025-07-30T04:25:00Z"
                       totalAmount: 50000
                       itemCount: 2
                       updatedAt: "2025-07-30T04:25:00Z"
+                      priceChanges: []
+                price_changed:
+                  summary: カート追加後に値上げされた商品がある
+                  value:
+                    success: true
+                    data:
+                      id: "default"
+                      items:
+                        - id: "item-1"
+                          productId: "fa03a45e-8138-41b7-b2af-ed63881fb5f9"
+                          product:
+                            id: "fa03a45e-8138-41b7-b2af-ed63881fb5f9"
+                            name: "ワイヤレスヘッドホン"
+                            price: 27000
+                          unitPrice: 25000
+                          quantity: 2
+                      totalAmount: 50000
+                      itemCount: 2
+                      priceChanges:
+                        - itemId: "item-1"
+                          productId: "fa03a45e-8138-41b7-b2af-ed63881fb5f9"
+                          oldPrice: 25000
+                          newPrice: 27000
+                          difference: 2000
                 empty_cart:
                   summary: 空のカート
                   value:
@@ -339,6 +414,40 @@ paths:
                       totalAmount: 0
                       itemCount: 0
                       updatedAt: "2025-07-30T04:20:19Z"
+                      priceChanges: []
+    delete:
+      summary: カートを空にする
+      description: カート内の全ての商品を削除します
+      tags:
+        - Cart
+      parameters:
+        - name: X-User-ID
+          in: header
+          required: false
+          description: ユーザーID（CART_TRUST_USER_HEADER=true の場合のみ認証済みユーザーとしてユーザー単位のカートを使用、anonymous は除く。値は検証しないため認証プロキシの背後でのみ有効にする）
+          schema:
+            type: string
+        - name: X-Session-ID
+          in: header
+          required: false
+          description: セッションID（X-User-ID がない場合にセッション単位のカートを使用）。どちらもない場合は cart_id Cookie（cart-{値}）、それもない場合は CART_FALLBACK に従う。決定したカートIDは X-Cart-ID ヘッダーで返す
+          schema:
+            type: string
+      responses:
+        '204':
+          description: カートを空にした
+        '404':
+          description: カートが見つからない
+          content:
+            application/json:
+              examples:
+                not_found:
+                  summary: カートが見つからない
+                  value:
+                    success: false
+                    error:
+                      code: "NOT_FOUND"
+                      message: "Cart not found"
 
   /api/cart/items:
     post:
@@ -346,6 +455,19 @@ paths:
       description: 指定された商品をカートに追加します
       tags:
         - Cart
+      parameters:
+        - name: X-User-ID
+          in: header
+          required: false
+          description: ユーザーID（CART_TRUST_USER_HEADER=true の場合のみ認証済みユーザーとしてユーザー単位のカートを使用、anonymous は除く。値は検証しないため認証プロキシの背後でのみ有効にする）
+          schema:
+            type: string
+        - name: X-Session-ID
+          in: header
+          required: false
+          description: セッションID（X-User-ID がない場合にセッション単位のカートを使用）。どちらもない場合は cart_id Cookie（cart-{値}）、それもない場合は CART_FALLBACK に従う。決定したカートIDは X-Cart-ID ヘッダーで返す
+          schema:
+            type: string
       requestBody:
         required: true
         content:
@@ -422,15 +544,259 @@ paths:
                       code: "UNPROCESSABLE_ENTITY"
                       message: "Insufficient stock"
 
+  /api/cart/items:batch:
+    post:
+      summary: 複数の商品をまとめてカートに追加
+      description: 指定された商品をまとめてカートに追加します（最大50件）。1件でも商品が見つからない場合はカートを変更しません
+      tags:
+        - Cart
+      parameters:
+        - name: X-User-ID
+          in: header
+          required: false
+          description: ユーザーID（CART_TRUST_USER_HEADER=true の場合のみ認証済みユーザーとしてユーザー単位のカートを使用、anonymous は除く。値は検証しないため認証プロキシの背後でのみ有効にする）
+          schema:
+            type: string
+        - name: X-Session-ID
+          in: header
+          required: false
+          description: セッションID（X-User-ID がない場合にセッション単位のカートを使用）。どちらもない場合は cart_id Cookie（cart-{値}）、それもない場合は CART_FALLBACK に従う。決定したカートIDは X-Cart-ID ヘッダーで返す
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required:
+                - items
+              properties:
+                items:
+                  type: array
+                  minItems: 1
+                  maxItems: 50
+                  items:
+                    type: object
+                    required:
+                      - productId
+                      - quantity
+                    properties:
+                      productId:
+                        type: string
+                        format: uuid
+                        description: 商品ID
+                      quantity:
+                        type: integer
+                        minimum: 1
+                        description: 追加する数量
+            examples:
+              add_multiple:
+                summary: ヘッドホンを2個、キーボードを1個追加
+                value:
+                  items:
+                    - productId: "fa03a45e-8138-41b7-b2af-ed63881fb5f9"
+                      quantity: 2
+                    - productId: "b9c36d3a-54e5-4a26-8e0a-2e1a6c5fb2d4"
+                      quantity: 1
+      responses:
+        '200':
+          description: カートへの追加に成功
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  success:
+                    type: boolean
+                    example: true
+                  data:
+                    type: object
+        '400':
+          description: リクエストボディが不正（空の商品リスト、数量が1未満など）
+          content:
+            application/json:
+              examples:
+                bad_request:
+                  summary: 不正なリクエスト
+                  value:
+                    success: false
+                    error:
+                      code: "BAD_REQUEST"
+                      message: "Invalid request body (1-50 items with productId and quantity >= 1)"
+        '404':
+          description: 指定された商品のいずれかが見つからない
+          content:
+            application/json:
+              examples:
+                not_found:
+                  summary: 商品が見つからない
+                  value:
+                    success: false
+                    error:
+                      code: "NOT_FOUND"
+                      message: "Product not found"
+        '422':
+          description: 在庫不足（同じ商品の行は合算して確認）
+          content:
+            application/json:
+              examples:
+                insufficient_stock:
+                  summary: 在庫不足
+                  value:
+                    success: false
+                    error:
+                      code: "UNPROCESSABLE_ENTITY"
+                      message: "Insufficient stock"
+
+  /api/cart/prices:confirm:
+    post:
+      summary: 価格変更の確認
+      description: カート内の商品の単価を現在の価格に更新し、GET /api/cart の priceChanges を解消します
+      tags:
+        - Cart
+      parameters:
+        - name: X-User-ID
+          in: header
+          required: false
+          description: ユーザーID（CART_TRUST_USER_HEADER=true の場合のみ認証済みユーザーとしてユーザー単位のカートを使用、anonymous は除く。値は検証しないため認証プロキシの背後でのみ有効にする）
+          schema:
+            type: string
+        - name: X-Session-ID
+          in: header
+          required: false
+          description: セッションID（X-User-ID がない場合にセッション単位のカートを使用）。どちらもない場合は cart_id Cookie（cart-{値}）、それもない場合は CART_FALLBACK に従う。決定したカートIDは X-Cart-ID ヘッダーで返す
+          schema:
+            type: string
+      responses:
+        '200':
+          description: 新しい価格でカートを更新
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  success:
+                    type: boolean
+                    example: true
+                  data:
+                    type: object
+
+  /api/cart/merge:
+    post:
+      summary: カートの統合
+      description: 呼び出し元のログイン前のカート（X-Session-ID または cart_id Cookie のカート）を認証済みユーザーのカートに統合し、統合元のカートを削除します。統合元は呼び出し元の識別情報から決定し、任意のカートIDは指定できません。同じ商品は数量を合算し、在庫を超える場合は在庫数までに制限します（INVENTORY_MODE=tracked の場合）。統合元のカートが存在しない場合は現在のカートをそのまま返します
+      tags:
+        - Cart
+      parameters:
+        - name: X-User-ID
+          in: header
+          required: false
+          description: ユーザーID（CART_TRUST_USER_HEADER=true の場合のみ認証済みユーザーとしてユーザー単位のカートを使用、anonymous は除く。値は検証しないため認証プロキシの背後でのみ有効にする）
+          schema:
+            type: string
+        - name: X-Session-ID
+          in: header
+          required: false
+          description: セッションID（X-User-ID がない場合にセッション単位のカートを使用）。どちらもない場合は cart_id Cookie（cart-{値}）、それもない場合は CART_FALLBACK に従う。決定したカートIDは X-Cart-ID ヘッダーで返す
+          schema:
+            type: string
+      responses:
+        '200':
+          description: カートの統合に成功
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  success:
+                    type: boolean
+                    example: true
+                  data:
+                    type: object
+        '400':
+          description: 認証済みユーザーまたはログイン前の識別情報（X-Session-ID / cart_id Cookie）がない
+          content:
+            application/json:
+              examples:
+                missing_identity:
+                  summary: 統合元の識別情報がない
+                  value:
+                    success: false
+                    error:
+                      code: "BAD_REQUEST"
+                      message: "Authenticated user and anonymous cart identity (X-Session-ID header or cart_id cookie) are required"
+
+  /api/cart/items/{id}:
+    delete:
+      summary: カートから商品を削除
+      description: カート内の指定商品を削除します
+      tags:
+        - Cart
+      parameters:
+        - name: X-User-ID
+          in: header
+          required: false
+          description: ユーザーID（CART_TRUST_USER_HEADER=true の場合のみ認証済みユーザーとしてユーザー単位のカートを使用、anonymous は除く。値は検証しないため認証プロキシの背後でのみ有効にする）
+          schema:
+            type: string
+        - name: X-Session-ID
+          in: header
+          required: false
+          description: セッションID（X-User-ID がない場合にセッション単位のカートを使用）。どちらもない場合は cart_id Cookie（cart-{値}）、それもない場合は CART_FALLBACK に従う。決定したカートIDは X-Cart-ID ヘッダーで返す
+          schema:
+            type: string
+        - name: id
+          in: path
+          required: true
+          description: カートアイテムID
+          schema:
+            type: string
+            format: uuid
+      responses:
+        '204':
+          description: 商品を削除した
+        '404':
+          description: 指定されたカートアイテムが見つからない
+          content:
+            application/json:
+              examples:
+                not_found:
+                  summary: カートアイテムが見つからない
+                  value:
+                    success: false
+                    error:
+                      code: "NOT_FOUND"
+                      message: "Cart item not found"
+
   /api/orders:
     post:
       summary: 注文作成
-      description: カート内容を基に注文を作成します。最も重要なビジネスKPIを測定するエンドポイントです。
+      description: カート内容を基に注文を作成します。最も重要なビジネスKPIを測定するエンドポイントです。Idempotency-Key を指定すると、同じキーの再送には最初のレスポンスを返します（二重注文の防止）
       tags:
         - Orders
+      parameters:
+        - name: X-User-ID
+          in: header
+          required: false
+          description: ユーザーID（CART_TRUST_USER_HEADER=true の場合のみ認証済みユーザーとしてユーザー単位のカートを使用、anonymous は除く。値は検証しないため認証プロキシの背後でのみ有効にする）
+          schema:
+            type: string
+        - name: X-Session-ID
+          in: header
+          required: false
+          description: セッションID（X-User-ID がない場合にセッション単位のカートを使用）。どちらもない場合は cart_id Cookie（cart-{値}）、それもない場合は CART_FALLBACK に従う。決定したカートIDは X-Cart-ID ヘッダーで返す
+          schema:
+            type: string
+        - name: Idempotency-Key
+          in: header
+          required: false
+          description: 再送を識別するキー（255文字以内）。呼び出し元ごとに最初のレスポンスを IDEMPOTENCY_TTL の間保存し、同じキーの再送には保存したレスポンスを Idempotent-Replayed ヘッダー付きで返す（5xx は保存しない）。キーを指定したリクエストのボディは 1MiB まで（超える場合は 413）
+          schema:
+            type: string
       responses:
         '201':
-          description: 注文作成に成功
+          description: 注文作成に成功（再送の場合は Idempotent-Replayed ヘッダーが true）
           content:
             application/json:
               schema:
@@ -451,6 +817,12 @@ paths:
                         type: integer
                       status:
                         type: string
+                        enum: ["pending", "paid", "shipped", "delivered", "completed", "failed", "canceled", "refunded"]
+                      payment:
+                        type: object
+                        description: 決済の状況（status・attempts・providerReference・declineCode・failureReason と各日時）。決済に失敗した注文は failureReason で理由を確認できる
+                      statusHistory:
+                        type: array
                       createdAt:
                         type: string
                         format: date-time
@@ -469,10 +841,17 @@ paths:
                           quantity: 2
                           subtotal: 50000
                       totalAmount: 50000
-                      status: "completed"
+                      status: "pending"
+                      payment:
+                        status: "pending"
+                        attempts: 0
+                      statusHistory:
+                        - to: "pending"
+                          reason: "order created"
+                          at: "2025-07-30T04:30:00Z"
                       createdAt: "2025-07-30T04:30:00Z"
         '422':
-          description: カートが空、または在庫不足
+          description: カートが空、または在庫不足（INVENTORY_MODE=tracked の場合、注文作成時に全商品の在庫を引き当てる）
           content:
             application/json:
               examples:
@@ -483,6 +862,124 @@ paths:
                     error:
                       code: "UNPROCESSABLE_ENTITY"
                       message: "Cart is empty"
+                insufficient_stock:
+                  summary: 在庫不足
+                  value:
+                    success: false
+                    error:
+                      code: "UNPROCESSABLE_ENTITY"
+                      message: "Insufficient stock"
+                idempotency_key_reused:
+                  summary: Idempotency-Key を別のリクエストに使用
+                  value:
+                    success: false
+                    error:
+                      code: "IDEMPOTENCY_KEY_REUSED"
+                      message: "Idempotency-Key was already used for a different request"
+        '409':
+          description: カートに追加した後に価格が変わった商品がある。GET /api/cart の priceChanges を確認し、POST /api/cart/prices:confirm で新しい価格を確定してから再度注文する。同じ Idempotency-Key のリクエストを処理中の場合も409
+          content:
+            application/json:
+              examples:
+                price_changed:
+                  summary: 価格変更の確認が必要
+                  value:
+                    success: false
+                    error:
+                      code: "PRICE_CHANGED"
+                      message: "Product prices have changed; review the cart and confirm the new prices"
+                idempotency_key_in_use:
+                  summary: 同じ Idempotency-Key のリクエストを処理中
+                  value:
+                    success: false
+                    error:
+                      code: "IDEMPOTENCY_KEY_IN_USE"
+                      message: "A request with the same Idempotency-Key is still being processed"
+        '503':
+          description: 決済キューが満杯、またはサーバーの停止中で決済を受け付けられない（注文は failed になり、カートはそのまま残る）
+          content:
+            application/json:
+              examples:
+                payment_unavailable:
+                  summary: 決済キューが満杯
+                  value:
+                    success: false
+                    error:
+                      code: "PAYMENT_UNAVAILABLE"
+                      message: "Payment gateway is unavailable, please retry"
+
+  /api/orders/{id}/cancel:
+    post:
+      summary: 注文キャンセル
+      description: 出荷前（pending・paid）の注文をキャンセルします。INVENTORY_MODE=tracked の場合は在庫を戻します。売上確定済みの注文は決済ゲートウェイで返金してからキャンセルします。遷移は statusHistory に理由とともに記録されます。
+      tags:
+        - Orders
+      parameters:
+        - name: id
+          in: path
+          required: true
+          description: 注文ID
+          schema:
+            type: string
+      requestBody:
+        required: false
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                reason:
+                  type: string
+                  maxLength: 200
+                  example: "changed my mind"
+      responses:
+        '200':
+          description: キャンセルに成功
+          content:
+            application/json:
+              examples:
+                canceled:
+                  summary: キャンセル成功
+                  value:
+                    success: true
+                    data:
+                      id: "order-12345"
+                      totalAmount: 50000
+                      status: "canceled"
+                      statusHistory:
+                        - to: "pending"
+                          reason: "order created"
+                          at: "2025-07-30T04:30:00Z"
+                        - from: "pending"
+                          to: "canceled"
+                          reason: "changed my mind"
+                          at: "2025-07-30T04:30:05Z"
+        '404':
+          description: 指定された注文が見つからない
+        '409':
+          description: 現在のステータスからキャンセルできない（出荷済み・キャンセル済みなど）
+          content:
+            application/json:
+              examples:
+                invalid_status:
+                  summary: 不正なステータス遷移
+                  value:
+                    success: false
+                    error:
+                      code: "INVALID_ORDER_STATUS"
+                      message: "Order status does not allow this transition"
+        '503':
+          description: 決済ゲートウェイの障害で返金できない（注文はキャンセルされない）
+          content:
+            application/json:
+              examples:
+                payment_unavailable:
+                  summary: 決済ゲートウェイの障害
+                  value:
+                    success: false
+                    error:
+                      code: "PAYMENT_UNAVAILABLE"
+                      message: "Payment gateway is unavailable, please retry"
 
   /api/v1/error:
     get:
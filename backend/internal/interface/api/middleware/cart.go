@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/presenter"
+)
+
+// 識別情報がない場合のカートの扱い
+const (
+	CartFallbackCookie = "cookie" // 新しいカートIDを発行してCookieに保存する
+	CartFallbackShared = "shared" // 全クライアントで共有のカートを使う（従来の振る舞い）
+	CartFallbackReject = "reject" // 400エラーを返す
+)
+
+const (
+	CartIDKey          = "CartID"
+	AnonymousCartIDKey = "AnonymousCartID"
+	UserIDKey          = "UserID"
+	CartIDHeader       = "X-Cart-ID"
+	UserIDHeader       = "X-User-ID"
+	CartCookieName     = "cart_id"
+	SharedCartID       = "default"
+	cartCookieMaxAge   = 30 * 24 * 60 * 60 // 30日
+	maxCartIDLength    = 128
+	anonymousUserID    = "anonymous"
+)
+
+// X-User-ID ヘッダーを認証済みのユーザーIDとして扱うミドルウェア
+// ヘッダーの値は検証しないため、認証プロキシがヘッダーを付与・上書きする環境でのみ登録する（CART_TRUST_USER_HEADER）
+func TrustedUserHeaderMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID := c.GetHeader(UserIDHeader); userID != anonymousUserID && validCartIdentity(userID) {
+			c.Set(UserIDKey, userID)
+		}
+		c.Next()
+	}
+}
+
+// カートIDを決定するミドルウェア
+// 認証済みユーザー（TrustedUserHeaderMiddleware が設定したユーザーID）、X-Session-ID、cart_id Cookie の順に識別し、
+// 決定したカートIDを X-Cart-ID ヘッダーで返す
+// 認証済みユーザーが匿名の識別情報も送った場合は、そのカートID（ログイン前のカート）を AnonymousCartIDKey に設定する
+func CartIDMiddleware(fallback string, telemetry monitoring.Telemetry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cartID, source := resolveCartID(c)
+
+		if cartID == "" {
+			switch fallback {
+			case CartFallbackShared:
+				cartID, source = SharedCartID, "shared"
+			case CartFallbackReject:
+				presenter.BadRequestResponse(c, "Cart identity is required (X-Session-ID header or cart_id cookie)")
+				c.Abort()
+				return
+			default:
+				token := uuid.New().String()
+				cartID, source = cookieCartID(token), "new"
+				c.SetSameSite(http.SameSiteLaxMode)
+				c.SetCookie(CartCookieName, token, cartCookieMaxAge, "/", "", false, true)
+			}
+		}
+
+		c.Set(CartIDKey, cartID)
+		if source == "user" {
+			if anonymousCartID, _ := resolveAnonymousCartID(c); anonymousCartID != "" {
+				c.Set(AnonymousCartIDKey, anonymousCartID)
+			}
+		}
+		c.Header(CartIDHeader, cartID)
+
+		// トランザクションにカスタム属性を追加
+		telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+			"cart.id":     cartID,
+			"cart.source": source,
+		})
+
+		c.Next()
+	}
+}
+
+// resolveCartID はリクエストの識別情報からカートIDを決定する（識別できない場合は空）
+// 識別情報の種類ごとにプレフィックスを付け、クライアントが指定した値が別の種類のカートIDと一致しないようにする
+func resolveCartID(c *gin.Context) (cartID string, source string) {
+	if userID := c.GetString(UserIDKey); userID != "" {
+		return "user-" + userID, "user"
+	}
+	return resolveAnonymousCartID(c)
+}
+
+// resolveAnonymousCartID は認証前の識別情報（X-Session-ID / cart_id Cookie）からカートIDを決定する
+func resolveAnonymousCartID(c *gin.Context) (cartID string, source string) {
+	if sessionID := c.GetHeader("X-Session-ID"); validCartIdentity(sessionID) {
+		return "session-" + sessionID, "session"
+	}
+	if cookie, err := c.Cookie(CartCookieName); err == nil && validCartIdentity(cookie) {
+		return cookieCartID(cookie), "cookie"
+	}
+	return "", ""
+}
+
+func cookieCartID(token string) string {
+	return "cart-" + token
+}
+
+func validCartIdentity(value string) bool {
+	return value != "" && len(value) <= maxCartIDLength
+}
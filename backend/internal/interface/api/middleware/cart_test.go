@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+)
+
+func TestCartIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		fallback        string
+		trustUserHeader bool
+		headers         map[string]string
+		cookie          string
+		expectedStatus  int
+		expectedCartID  string // "new" は新規発行
+		expectCookie    bool
+	}{
+		{name: "認証済みユーザーを優先", fallback: CartFallbackCookie, trustUserHeader: true, headers: map[string]string{"X-User-ID": "user-1", "X-Session-ID": "abc"}, expectedStatus: http.StatusOK, expectedCartID: "user-user-1"},
+		{name: "anonymousは認証済みユーザーとみなさない", fallback: CartFallbackCookie, trustUserHeader: true, headers: map[string]string{"X-User-ID": "anonymous", "X-Session-ID": "abc"}, expectedStatus: http.StatusOK, expectedCartID: "session-abc"},
+		{name: "信頼しない設定ではX-User-IDを無視", fallback: CartFallbackCookie, headers: map[string]string{"X-User-ID": "user-1", "X-Session-ID": "abc"}, expectedStatus: http.StatusOK, expectedCartID: "session-abc"},
+		{name: "X-Session-ID", fallback: CartFallbackCookie, headers: map[string]string{"X-Session-ID": "abc"}, expectedStatus: http.StatusOK, expectedCartID: "session-abc"},
+		{name: "Cookie", fallback: CartFallbackCookie, cookie: "123", expectedStatus: http.StatusOK, expectedCartID: "cart-123"},
+		{name: "Cookieで他のユーザーのカートIDは指定できない", fallback: CartFallbackCookie, cookie: "user-42", expectedStatus: http.StatusOK, expectedCartID: "cart-user-42"},
+		{name: "識別情報なしは新しいカートIDをCookieで発行", fallback: CartFallbackCookie, expectedStatus: http.StatusOK, expectedCartID: "new", expectCookie: true},
+		{name: "識別情報なしで共有カート", fallback: CartFallbackShared, expectedStatus: http.StatusOK, expectedCartID: SharedCartID},
+		{name: "識別情報なしで拒否", fallback: CartFallbackReject, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if tt.trustUserHeader {
+				router.Use(TrustedUserHeaderMiddleware())
+			}
+			router.GET("/api/cart", CartIDMiddleware(tt.fallback, monitoring.NewMemoryTelemetry()), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"cartId": c.GetString(CartIDKey)})
+			})
+
+			req, _ := http.NewRequest("GET", "/api/cart", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: CartCookieName, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("ステータスコード = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			cartID := w.Header().Get(CartIDHeader)
+			switch tt.expectedCartID {
+			case "new":
+				if !strings.HasPrefix(cartID, "cart-") {
+					t.Errorf("X-Cart-ID = %v, want cart-で始まる新しいID", cartID)
+				}
+			default:
+				if cartID != tt.expectedCartID {
+					t.Errorf("X-Cart-ID = %v, want %v", cartID, tt.expectedCartID)
+				}
+			}
+
+			setCookie := w.Header().Get("Set-Cookie")
+			token := strings.TrimPrefix(cartID, "cart-")
+			if tt.expectCookie && !strings.Contains(setCookie, CartCookieName+"="+token) {
+				t.Errorf("Set-Cookie = %v, want %s=%s", setCookie, CartCookieName, token)
+			}
+			if !tt.expectCookie && setCookie != "" {
+				t.Errorf("Set-Cookie = %v, want なし", setCookie)
+			}
+		})
+	}
+}
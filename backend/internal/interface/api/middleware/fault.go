@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/presenter"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+)
+
+// SLMデモ用の障害注入ミドルウェア
+// ルートに対応するプロファイルがある場合のみ遅延・エラーを発生させる
+func FaultInjectionMiddleware(faults *fault.Config, telemetry monitoring.Telemetry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		decision, exists := faults.Decide(c.Request.Method, c.FullPath())
+		if !exists {
+			c.Next()
+			return
+		}
+
+		// SLMデモ用のレスポンス時間調整
+		time.Sleep(decision.Delay)
+
+		if !decision.Fail {
+			c.Next()
+			return
+		}
+
+		// SLMデモ用のエラー生成
+		telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+			"fault.injected": true,
+			"fault.route":    decision.Route,
+			"fault.delayMs":  decision.Delay.Milliseconds(),
+		})
+
+		profile := decision.Profile
+		statusCode := profile.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusInternalServerError
+		}
+		errorCode := profile.ErrorCode
+		if errorCode == "" {
+			errorCode = "INTERNAL_SERVER_ERROR"
+		}
+
+		presenter.ErrorResponse(c, statusCode, errorCode, "Simulated fault for SLM demonstration")
+		c.Abort()
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+func TestFaultInjectionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	faults := fault.NewConfig(fault.Profiles{
+		"POST /api/orders":      {ErrorRate: 1.0, StatusCode: http.StatusServiceUnavailable, ErrorCode: "SERVICE_UNAVAILABLE"},
+		"GET /api/products/:id": {ErrorRate: 1.0},
+		"GET /api/products":     {ErrorRate: 0},
+	}, utils.NewSeededRand(1))
+
+	router := gin.New()
+	router.Use(FaultInjectionMiddleware(faults, monitoring.NewMemoryTelemetry()))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"success": true}) }
+	router.GET("/api/products", ok)
+	router.GET("/api/products/:id", ok)
+	router.POST("/api/orders", ok)
+	router.GET("/api/cart", ok)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "指定したステータスとエラーコードを返す", method: "POST", path: "/api/orders", expectedStatus: http.StatusServiceUnavailable, expectedCode: "SERVICE_UNAVAILABLE"},
+		{name: "パスパラメータ付きルートにもマッチ", method: "GET", path: "/api/products/abc", expectedStatus: http.StatusInternalServerError, expectedCode: "INTERNAL_SERVER_ERROR"},
+		{name: "エラー率0は正常応答", method: "GET", path: "/api/products", expectedStatus: http.StatusOK},
+		{name: "プロファイルのないルートは正常応答", method: "GET", path: "/api/cart", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("ステータスコード = %v, want %v", w.Code, tt.expectedStatus)
+			}
+
+			if tt.expectedCode != "" {
+				var response struct {
+					Success bool `json:"success"`
+					Error   struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				if response.Success {
+					t.Error("successがtrueです")
+				}
+				if response.Error.Code != tt.expectedCode {
+					t.Errorf("エラーコード = %v, want %v", response.Error.Code, tt.expectedCode)
+				}
+			}
+		})
+	}
+
+	// 実行中の設定変更が即座に反映される
+	faults.Merge(fault.Profiles{"POST /api/orders": {ErrorRate: 0}})
+	req, _ := http.NewRequest("POST", "/api/orders", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("設定変更後のステータスコード = %v, want %v", w.Code, http.StatusOK)
+	}
+}
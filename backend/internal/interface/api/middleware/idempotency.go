@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/presenter"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentBodySize     = 1 << 20 // ハッシュのために読み込むリクエストボディの上限（1MiB）
+	idempotencySweepInterval  = time.Minute
+	idempotencyScopeSeparator = "\x00"
+)
+
+// IdempotencyStore は Idempotency-Key ごとに最初のリクエストのレスポンスを TTL の間保持する
+type IdempotencyStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mutex     sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	response    *idempotentResponse // nil は処理中
+	expiresAt   time.Time
+}
+
+type idempotentResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// キーの状態
+type idempotencyState int
+
+const (
+	idempotencyNew      idempotencyState = iota // 初めてのキー（処理中として登録した）
+	idempotencyInFlight                         // 同じキーのリクエストを処理中
+	idempotencyMismatch                         // 別のリクエストで使用済み
+	idempotencyReplay                           // 保存したレスポンスを返す
+)
+
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+// begin はキーの状態を返す。初めてのキーは処理中として登録する
+func (s *IdempotencyStore) begin(key, fingerprint string) (idempotencyState, *idempotentResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	entry, exists := s.entries[key]
+	if !exists || !now.Before(entry.expiresAt) {
+		s.entries[key] = &idempotencyEntry{fingerprint: fingerprint, expiresAt: now.Add(s.ttl)}
+		return idempotencyNew, nil
+	}
+
+	switch {
+	case entry.fingerprint != fingerprint:
+		return idempotencyMismatch, nil
+	case entry.response == nil:
+		return idempotencyInFlight, nil
+	default:
+		return idempotencyReplay, entry.response
+	}
+}
+
+// complete は処理中のキーにレスポンスを保存する（有効期限はレスポンスの保存から TTL）
+func (s *IdempotencyStore) complete(key string, response *idempotentResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, exists := s.entries[key]; exists {
+		entry.response = response
+		entry.expiresAt = s.now().Add(s.ttl)
+	}
+}
+
+// release は処理中のキーを削除し、同じキーで再試行できるようにする
+func (s *IdempotencyStore) release(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, key)
+}
+
+// sweep は期限切れのキーを削除する（mutex を保持した状態で呼び出す）
+func (s *IdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < idempotencySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// Idempotency-Key ヘッダーによる重複リクエストの防止ミドルウェア
+// 変更系のリクエスト（POST/PUT/PATCH/DELETE）でキーが指定された場合のみ、最初のレスポンスを保存して再送時に同じレスポンスを返す
+// キーは呼び出し元（認証済みユーザー / X-Session-ID / Cookie）ごとに区別し、同じキーを別のリクエスト（パス・ボディ）に使った場合は422、
+// 同じキーのリクエストを処理中の場合は409を返す。5xxのレスポンスは保存せず、同じキーで再試行できる
+// キーを指定したリクエストのボディは maxIdempotentBodySize まで読み込み、超える場合は413を返す
+func IdempotencyMiddleware(store *IdempotencyStore, telemetry monitoring.Telemetry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			presenter.BadRequestResponse(c, "Idempotency-Key must be at most 255 characters")
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize)); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					presenter.ErrorResponse(c, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE", "Request body must be at most 1MiB when Idempotency-Key is set")
+					c.Abort()
+					return
+				}
+				presenter.BadRequestResponse(c, "Failed to read request body")
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		identity, _ := resolveCartID(c)
+		key := identity + idempotencyScopeSeparator + idempotencyKey
+		state, response := store.begin(key, requestFingerprint(c.Request, body))
+
+		// トランザクションにカスタム属性を追加
+		telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+			"idempotency.key":      idempotencyKey,
+			"idempotency.replayed": state == idempotencyReplay,
+		})
+
+		switch state {
+		case idempotencyInFlight:
+			presenter.ErrorResponse(c, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE", "A request with the same Idempotency-Key is still being processed")
+			c.Abort()
+			return
+		case idempotencyMismatch:
+			presenter.ErrorResponse(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+			c.Abort()
+			return
+		case idempotencyReplay:
+			for name, values := range response.header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(response.status, response.header.Get("Content-Type"), response.body)
+			c.Abort()
+			return
+		}
+
+		// パニックの場合も処理中のキーを残さない（RecoveryMiddleware で500になる）
+		completed := false
+		defer func() {
+			if !completed {
+				store.release(key)
+			}
+		}()
+
+		// 前段のミドルウェアが設定したヘッダー（X-Request-ID など）は保存しない
+		headerBefore := c.Writer.Header().Clone()
+		writer := &recordingResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+		store.complete(key, &idempotentResponse{
+			status: c.Writer.Status(),
+			header: addedHeaders(headerBefore, c.Writer.Header()),
+			body:   writer.body.Bytes(),
+		})
+		completed = true
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// requestFingerprint は同じキーが同じリクエストに使われているか判定するためのハッシュ
+func requestFingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.Method+" "+req.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// addedHeaders はハンドラーが追加・変更したヘッダーを返す
+func addedHeaders(before, after http.Header) http.Header {
+	added := http.Header{}
+	for name, values := range after {
+		if previous, exists := before[name]; !exists || !slices.Equal(previous, values) {
+			added[name] = append([]string(nil), values...)
+		}
+	}
+	return added
+}
+
+// recordingResponseWriter はクライアントに返したレスポンスボディを保存する
+type recordingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
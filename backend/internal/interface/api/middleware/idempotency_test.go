@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+)
+
+type idempotentRequest struct {
+	method  string
+	key     string
+	session string
+	body    string
+}
+
+func (r idempotentRequest) send(router *gin.Engine) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(r.method, "/api/orders", strings.NewReader(r.body))
+	if r.key != "" {
+		req.Header.Set(IdempotencyKeyHeader, r.key)
+	}
+	if r.session != "" {
+		req.Header.Set("X-Session-ID", r.session)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	order := idempotentRequest{method: "POST", key: "key-1", session: "abc", body: "{}"}
+
+	tests := []struct {
+		name             string
+		first            idempotentRequest
+		second           idempotentRequest
+		handlerStatus    int
+		elapsed          time.Duration // 2回目のリクエストまでの経過時間
+		expectedCalls    int
+		expectedStatus   int
+		expectedReplayed bool
+	}{
+		{name: "同じキーの再送は保存したレスポンスを返す", first: order, second: order, handlerStatus: http.StatusCreated, expectedCalls: 1, expectedStatus: http.StatusCreated, expectedReplayed: true},
+		{name: "キーなしは毎回処理する", first: idempotentRequest{method: "POST", session: "abc"}, second: idempotentRequest{method: "POST", session: "abc"}, handlerStatus: http.StatusCreated, expectedCalls: 2, expectedStatus: http.StatusCreated},
+		{name: "参照系のリクエストは対象外", first: idempotentRequest{method: "GET", key: "key-1"}, second: idempotentRequest{method: "GET", key: "key-1"}, handlerStatus: http.StatusOK, expectedCalls: 2, expectedStatus: http.StatusOK},
+		{name: "別のリクエストに同じキーを使うと422", first: order, second: idempotentRequest{method: "POST", key: "key-1", session: "abc", body: `{"note":"changed"}`}, handlerStatus: http.StatusCreated, expectedCalls: 1, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "呼び出し元が異なれば別のキー", first: order, second: idempotentRequest{method: "POST", key: "key-1", session: "xyz", body: "{}"}, handlerStatus: http.StatusCreated, expectedCalls: 2, expectedStatus: http.StatusCreated},
+		{name: "クライアントエラーも保存する", first: order, second: order, handlerStatus: http.StatusUnprocessableEntity, expectedCalls: 1, expectedStatus: http.StatusUnprocessableEntity, expectedReplayed: true},
+		{name: "5xxは保存せずに再試行できる", first: order, second: order, handlerStatus: http.StatusServiceUnavailable, expectedCalls: 2, expectedStatus: http.StatusServiceUnavailable},
+		{name: "有効期限を過ぎたキーは再度処理する", first: order, second: order, handlerStatus: http.StatusCreated, elapsed: 2 * time.Hour, expectedCalls: 2, expectedStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			store := NewIdempotencyStore(time.Hour)
+			store.now = func() time.Time { return now }
+
+			calls := 0
+			router := gin.New()
+			router.Use(IdempotencyMiddleware(store, monitoring.NewMemoryTelemetry()))
+			handler := func(c *gin.Context) {
+				calls++
+				c.Header("Location", fmt.Sprintf("/api/orders/order-%d", calls))
+				c.JSON(tt.handlerStatus, gin.H{"id": fmt.Sprintf("order-%d", calls)})
+			}
+			router.GET("/api/orders", handler)
+			router.POST("/api/orders", handler)
+
+			first := tt.first.send(router)
+			now = now.Add(tt.elapsed)
+			second := tt.second.send(router)
+
+			if calls != tt.expectedCalls {
+				t.Errorf("ハンドラーの呼び出し回数 = %v, want %v", calls, tt.expectedCalls)
+			}
+			if second.Code != tt.expectedStatus {
+				t.Fatalf("ステータスコード = %v, want %v", second.Code, tt.expectedStatus)
+			}
+			replayed := second.Header().Get(IdempotentReplayedHeader) == "true"
+			if replayed != tt.expectedReplayed {
+				t.Errorf("Idempotent-Replayed = %v, want %v", replayed, tt.expectedReplayed)
+			}
+			if tt.expectedReplayed {
+				if second.Body.String() != first.Body.String() {
+					t.Errorf("ボディ = %s, want %s", second.Body.String(), first.Body.String())
+				}
+				// ハンドラーが設定したヘッダーも再現する
+				if second.Header().Get("Location") != first.Header().Get("Location") || second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+					t.Errorf("ヘッダー = %v, want %v", second.Header(), first.Header())
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_InFlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := NewIdempotencyStore(time.Hour)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(store, monitoring.NewMemoryTelemetry()))
+	router.POST("/api/orders", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"id": "order-1"})
+	})
+
+	order := idempotentRequest{method: "POST", key: "key-1", session: "abc"}
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- order.send(router) }()
+	<-started
+
+	// 最初のリクエストの処理中に届いた同じキーのリクエストは409
+	if w := order.send(router); w.Code != http.StatusConflict {
+		t.Errorf("処理中の再送: ステータスコード = %v, want %v", w.Code, http.StatusConflict)
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("最初のリクエスト: ステータスコード = %v, want %v", w.Code, http.StatusCreated)
+	}
+	if w := order.send(router); w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("完了後の再送: ステータスコード = %v, Idempotent-Replayed = %q", w.Code, w.Header().Get(IdempotentReplayedHeader))
+	}
+}
+
+func TestIdempotencyMiddleware_InvalidKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(NewIdempotencyStore(time.Hour), monitoring.NewMemoryTelemetry()))
+	router.POST("/api/orders", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "order-1"})
+	})
+
+	w := idempotentRequest{method: "POST", key: strings.Repeat("k", maxIdempotencyKeyLength+1)}.send(router)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("ステータスコード = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestIdempotencyMiddleware_BodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(NewIdempotencyStore(time.Hour), monitoring.NewMemoryTelemetry()))
+	router.POST("/api/orders", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": "order-1"})
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{name: "上限以内のボディは処理する", body: strings.Repeat("a", maxIdempotentBodySize), expectedStatus: http.StatusCreated, expectedCalls: 1},
+		{name: "上限を超えるボディは413", body: strings.Repeat("a", maxIdempotentBodySize+1), expectedStatus: http.StatusRequestEntityTooLarge, expectedCalls: 1},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := idempotentRequest{method: "POST", key: fmt.Sprintf("key-%d", i), body: tt.body}.send(router)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("ステータスコード = %v, want %v", w.Code, tt.expectedStatus)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("ハンドラーの呼び出し回数 = %v, want %v", calls, tt.expectedCalls)
+			}
+		})
+	}
+}
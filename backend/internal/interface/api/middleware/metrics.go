@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+)
+
+// Prometheus用のリクエストメトリクスを記録するミドルウェア
+// 障害注入による遅延・エラーも含めて計測するため FaultInjectionMiddleware より前に登録する
+func PrometheusMiddleware(exporter *monitoring.PrometheusExporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			// 存在しないルートはラベルの組み合わせが増えないよう記録しない
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		defer func() {
+			// パニックは RecoveryMiddleware で500になるため、エラーとして記録してから再送出する
+			if recovered := recover(); recovered != nil {
+				exporter.ObserveRequest(c.Request.Method, route, http.StatusInternalServerError, time.Since(start))
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+
+		exporter.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
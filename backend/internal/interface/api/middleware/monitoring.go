@@ -7,70 +7,69 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/newrelic/go-agent/v3/integrations/nrgin"
-	"github.com/newrelic/go-agent/v3/newrelic"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
 )
 
-func NewRelicMiddleware(nrClient *monitoring.NewRelicClient) gin.HandlerFunc {
-	if nrClient.GetApplication() == nil {
+func NewRelicMiddleware(telemetry monitoring.Telemetry) gin.HandlerFunc {
+	app := monitoring.NewRelicApplication(telemetry)
+	if app == nil {
 		// New Relic が無効な場合は何もしないミドルウェアを返す
 		return func(c *gin.Context) {
 			c.Next()
 		}
 	}
 
-	return nrgin.Middleware(nrClient.GetApplication())
+	return nrgin.Middleware(app)
 }
 
 // Distributed Tracingヘッダーを処理するミドルウェア
-func DistributedTracingMiddleware() gin.HandlerFunc {
+func DistributedTracingMiddleware(telemetry monitoring.Telemetry) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// New Relic v3では、distributed tracingはW3C Trace Contextとして処理される
 		// カスタムヘッダーは属性として記録
 		if tracePayload := c.GetHeader("newrelic"); tracePayload != "" {
-			if txn := newrelic.FromContext(c.Request.Context()); txn != nil {
-				// カスタム属性として記録
-				txn.AddAttribute("distributedTrace.payload", tracePayload)
-			}
+			telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+				"distributedTrace.payload": tracePayload,
+			})
 		}
 
 		// フロントエンドからのNew Relicトレース情報
 		if newRelicTrace := c.GetHeader("X-NewRelic-Trace"); newRelicTrace != "" {
-			if txn := newrelic.FromContext(c.Request.Context()); txn != nil {
-				txn.AddAttribute("frontend.trace.id", newRelicTrace)
-			}
+			telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+				"frontend.trace.id": newRelicTrace,
+			})
 		}
 
 		// ブラウザからのリクエストであることを記録
 		if browserFlag := c.GetHeader("X-NewRelic-Browser"); browserFlag == "true" {
-			if txn := newrelic.FromContext(c.Request.Context()); txn != nil {
-				txn.AddAttribute("request.source", "browser")
-			}
+			telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+				"request.source": "browser",
+			})
 		}
 
-		// W3C Trace Context ヘッダーの処理（New Relic v3で自動サポート）
+		// W3C Trace Context ヘッダーの処理（New Relic v3で自動サポート、OpenTelemetry は TracingMiddleware で引き継ぐ）
 		if traceParent := c.GetHeader("traceparent"); traceParent != "" {
-			if txn := newrelic.FromContext(c.Request.Context()); txn != nil {
-				// 明示的にカスタム属性として記録
-				txn.AddAttribute("trace.parent", traceParent)
-			}
+			// 明示的にカスタム属性として記録
+			telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+				"trace.parent": traceParent,
+			})
 		}
 
 		// トレース状態ヘッダーも処理
 		if traceState := c.GetHeader("tracestate"); traceState != "" {
-			if txn := newrelic.FromContext(c.Request.Context()); txn != nil {
-				txn.AddAttribute("trace.state", traceState)
-			}
+			telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+				"trace.state": traceState,
+			})
 		}
 
-		// セッションIDを受け取ってNew Relicに記録
+		// セッションIDを受け取ってトランザクションに記録
 		if sessionId := c.GetHeader("X-Session-ID"); sessionId != "" {
-			if txn := newrelic.FromContext(c.Request.Context()); txn != nil {
-				txn.AddAttribute("session.id", sessionId)
+			telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+				"session.id": sessionId,
 				// ユーザーIDとしても設定（RUMとの連携用）
-				txn.AddAttribute("user.id", sessionId)
-			}
+				"user.id": sessionId,
+			})
 			// Ginコンテキストにも保存（ハンドラーで利用可能に）
 			c.Set("SessionID", sessionId)
 		}
@@ -95,7 +94,7 @@ func LoggingMiddleware() gin.HandlerFunc {
 	})
 }
 
-func RequestIDMiddleware() gin.HandlerFunc {
+func RequestIDMiddleware(telemetry monitoring.Telemetry) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader("X-Request-ID")
 		if requestID == "" {
@@ -105,10 +104,10 @@ func RequestIDMiddleware() gin.HandlerFunc {
 		c.Header("X-Request-ID", requestID)
 		c.Set("RequestID", requestID)
 
-		// New Relic トランザクションに属性を追加
-		if txn := newrelic.FromContext(c.Request.Context()); txn != nil {
-			txn.AddAttribute("request.id", requestID)
-		}
+		// トランザクションにカスタム属性を追加
+		telemetry.AddAttributes(c.Request.Context(), map[string]interface{}{
+			"request.id": requestID,
+		})
 
 		c.Next()
 	}
@@ -119,16 +118,14 @@ func generateRequestID() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
 }
 
-func RecoveryMiddleware(nrClient *monitoring.NewRelicClient) gin.HandlerFunc {
+// パニックを500に変換するミドルウェア
+// トランザクション終了前に報告できるよう NewRelicMiddleware より後に登録する
+func RecoveryMiddleware(telemetry monitoring.Telemetry) gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		if err, ok := recovered.(string); ok {
-			log.Printf("Panic recovered: %s", err)
-
-			// New Relic にエラーを報告
-			if nrClient != nil {
-				nrClient.NoticeError(fmt.Errorf("panic: %s", err))
-			}
-		}
+		// 任意の型のパニックをスタックトレース付きで報告
+		panicErr := monitoring.NewPanicError(recovered)
+		log.Printf("Panic recovered: %v", panicErr.Value)
+		telemetry.NoticeError(c.Request.Context(), panicErr)
 
 		c.AbortWithStatus(500)
 	})
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		value         interface{}
+		expectedError string
+	}{
+		{name: "文字列のパニック", value: "boom", expectedError: "panic: boom"},
+		{name: "エラーのパニック", value: errors.New("nil map"), expectedError: "panic: nil map"},
+		{name: "その他の型のパニック", value: 42, expectedError: "panic: 42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telemetry := monitoring.NewMemoryTelemetry()
+			router := gin.New()
+			router.Use(RecoveryMiddleware(telemetry))
+			router.GET("/panic", func(c *gin.Context) {
+				panic(tt.value)
+			})
+
+			req, _ := http.NewRequest("GET", "/panic", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("ステータスコード = %v, want %v", w.Code, http.StatusInternalServerError)
+			}
+
+			errs := telemetry.Errors()
+			if len(errs) != 1 {
+				t.Fatalf("報告されたエラー数 = %v, want 1", len(errs))
+			}
+			if errs[0].Error() != tt.expectedError {
+				t.Errorf("エラー = %v, want %v", errs[0], tt.expectedError)
+			}
+
+			var panicErr *monitoring.PanicError
+			if !errors.As(errs[0], &panicErr) {
+				t.Fatalf("PanicError ではない: %T", errs[0])
+			}
+			// スタックトレースにパニックの発生箇所が含まれる
+			if !strings.Contains(string(panicErr.Stack), "TestRecoveryMiddleware") {
+				t.Errorf("スタックトレースにパニックの発生箇所が含まれない: %s", panicErr.Stack)
+			}
+		})
+	}
+}
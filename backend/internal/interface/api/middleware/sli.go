@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/slo"
+)
+
+// SLIを記録するミドルウェア
+// 障害注入による遅延・エラーも含めて計測するため FaultInjectionMiddleware より前に登録する
+func SLIMiddleware(recorder *slo.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			// 存在しないルートはSLIの対象外
+			c.Next()
+			return
+		}
+
+		key := fault.RouteKey(c.Request.Method, route)
+		start := time.Now()
+
+		defer func() {
+			// パニックは RecoveryMiddleware で500になるため、エラーとして記録してから再送出する
+			if recovered := recover(); recovered != nil {
+				recorder.Record(key, http.StatusInternalServerError, time.Since(start))
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+
+		recorder.Record(key, c.Writer.Status(), time.Since(start))
+	}
+}
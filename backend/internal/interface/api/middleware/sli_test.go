@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/slo"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+func TestSLIMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := slo.NewRecorder(slo.Objectives{
+		{Name: "latency", SLI: slo.SLILatency, Target: 0.9, Window: utils.Duration(time.Hour), ThresholdMs: 100},
+	})
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.Use(SLIMiddleware(recorder))
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/unavailable", func(c *gin.Context) { c.Status(http.StatusServiceUnavailable) })
+	router.GET("/panic", func(c *gin.Context) { panic(struct{}{}) })
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedRoute  string
+		expectedErrors int64
+	}{
+		{
+			name:           "正常なレスポンス",
+			path:           "/ok",
+			expectedStatus: http.StatusOK,
+			expectedRoute:  "GET /ok",
+			expectedErrors: 0,
+		},
+		{
+			name:           "5xxはエラーとして記録",
+			path:           "/unavailable",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedRoute:  "GET /unavailable",
+			expectedErrors: 1,
+		},
+		{
+			name:           "パニックはエラーとして記録",
+			path:           "/panic",
+			expectedStatus: http.StatusInternalServerError,
+			expectedRoute:  "GET /panic",
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("ステータスコード = %v, want %v", w.Code, tt.expectedStatus)
+			}
+
+			counts := recorder.Count([]string{tt.expectedRoute}, time.Hour, 100*time.Millisecond)
+			if counts.Total != 1 || counts.Errors != tt.expectedErrors || counts.Fast != 1 {
+				t.Errorf("counts = %+v, want total=1 errors=%d fast=1", counts, tt.expectedErrors)
+			}
+		})
+	}
+
+	// 存在しないルートは記録しない
+	req, _ := http.NewRequest("GET", "/not-found", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if routes := recorder.Routes(); len(routes) != 3 {
+		t.Errorf("routes = %v, want 3 routes", routes)
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+)
+
+const tracingInstrumentationName = "github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/middleware"
+
+// OpenTelemetry のサーバースパンを作成するミドルウェア
+// traceparent/tracestate ヘッダーからW3C Trace Contextを引き継ぎ、ルートごとのスパンとリクエスト時間のメトリクスを記録する
+func TracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer(tracingInstrumentationName)
+	meter := otel.Meter(tracingInstrumentationName)
+
+	duration, err := meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Duration of HTTP server requests."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		name := fault.RouteKey(c.Request.Method, route)
+		if route == "" {
+			name = c.Request.Method
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+			),
+		)
+		c.Request = c.Request.WithContext(ctx)
+		start := time.Now()
+
+		defer func() {
+			status := c.Writer.Status()
+			recovered := recover()
+			if recovered != nil {
+				// パニックは RecoveryMiddleware で500になるため、エラーとして記録してから再送出する
+				status = http.StatusInternalServerError
+				span.RecordError(fmt.Errorf("panic: %v", recovered), trace.WithStackTrace(true))
+			}
+
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			span.End()
+
+			if duration != nil {
+				duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+					attribute.String("http.request.method", c.Request.Method),
+					attribute.String("http.route", route),
+					attribute.Int("http.response.status_code", status),
+				))
+			}
+
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	router := gin.New()
+	router.Use(TracingMiddleware())
+	router.GET("/api/products/:id", func(c *gin.Context) {
+		// ハンドラーのコンテキストにサーバースパンが設定されている
+		if !trace.SpanContextFromContext(c.Request.Context()).IsValid() {
+			t.Error("リクエストのコンテキストにスパンがない")
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+	router.POST("/api/orders", func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+	})
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		traceparent    string
+		expectedName   string
+		expectedStatus int
+		expectedCode   codes.Code
+	}{
+		{name: "traceparentのトレースを引き継ぐ", method: "GET", path: "/api/products/abc", traceparent: "00-" + traceID + "-" + parentSpanID + "-01", expectedName: "GET /api/products/:id", expectedStatus: http.StatusOK, expectedCode: codes.Unset},
+		{name: "5xxはエラーとして記録", method: "POST", path: "/api/orders", expectedName: "POST /api/orders", expectedStatus: http.StatusInternalServerError, expectedCode: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			spans := recorder.Ended()
+			span := spans[len(spans)-1]
+
+			if span.Name() != tt.expectedName {
+				t.Errorf("スパン名 = %v, want %v", span.Name(), tt.expectedName)
+			}
+			if span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("SpanKind = %v, want server", span.SpanKind())
+			}
+			if span.Status().Code != tt.expectedCode {
+				t.Errorf("ステータス = %v, want %v", span.Status().Code, tt.expectedCode)
+			}
+			if !containsAttribute(span.Attributes(), attribute.Int("http.response.status_code", tt.expectedStatus)) {
+				t.Errorf("http.response.status_code が %v でない: %v", tt.expectedStatus, span.Attributes())
+			}
+			if tt.traceparent != "" {
+				if span.SpanContext().TraceID().String() != traceID {
+					t.Errorf("TraceID = %v, want %v", span.SpanContext().TraceID(), traceID)
+				}
+				if span.Parent().SpanID().String() != parentSpanID {
+					t.Errorf("親SpanID = %v, want %v", span.Parent().SpanID(), parentSpanID)
+				}
+			}
+		})
+	}
+}
+
+func containsAttribute(attributes []attribute.KeyValue, expected attribute.KeyValue) bool {
+	for _, kv := range attributes {
+		if kv == expected {
+			return true
+		}
+	}
+	return false
+}
@@ -49,3 +49,7 @@ func InternalServerErrorResponse(c *gin.Context, message string) {
 func UnprocessableEntityResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", message)
 }
+
+func NoContentResponse(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
@@ -1,36 +1,65 @@
 package api
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/handler"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/middleware"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/fault"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/slo"
 )
 
 type Router struct {
-	healthHandler  *handler.HealthHandler
-	productHandler *handler.ProductHandler
-	cartHandler    *handler.CartHandler
-	orderHandler   *handler.OrderHandler
-	swaggerHandler *handler.SwaggerHandler
-	nrClient       *monitoring.NewRelicClient
+	healthHandler   *handler.HealthHandler
+	productHandler  *handler.ProductHandler
+	cartHandler     *handler.CartHandler
+	orderHandler    *handler.OrderHandler
+	swaggerHandler  *handler.SwaggerHandler
+	adminHandler    *handler.AdminHandler
+	sloHandler      *handler.SLOHandler
+	faults          *fault.Config
+	idempotency     *middleware.IdempotencyStore
+	sliRecorder     *slo.Recorder
+	prometheus      *monitoring.PrometheusExporter
+	telemetry       monitoring.Telemetry
+	cartFallback    string
+	trustUserHeader bool
 }
 
 func NewRouter(
 	productUseCase *usecase.ProductUseCase,
 	cartUseCase *usecase.CartUseCase,
 	orderUseCase *usecase.OrderUseCase,
-	nrClient *monitoring.NewRelicClient,
+	faults *fault.Config,
+	scenarios *fault.ScenarioRunner,
+	sliRecorder *slo.Recorder,
+	sloEvaluator *slo.Evaluator,
+	sloAlerter *slo.Alerter,
+	prometheus *monitoring.PrometheusExporter,
+	telemetry monitoring.Telemetry,
+	cartFallback string,
+	trustUserHeader bool,
+	idempotencyTTL time.Duration,
 ) *Router {
 	return &Router{
-		healthHandler:  handler.NewHealthHandler(),
-		productHandler: handler.NewProductHandler(productUseCase, nrClient),
-		cartHandler:    handler.NewCartHandler(cartUseCase, nrClient),
-		orderHandler:   handler.NewOrderHandler(orderUseCase, nrClient),
-		swaggerHandler: handler.NewSwaggerHandler(),
-		nrClient:       nrClient,
+		healthHandler:   handler.NewHealthHandler(),
+		productHandler:  handler.NewProductHandler(productUseCase, telemetry),
+		cartHandler:     handler.NewCartHandler(cartUseCase, telemetry),
+		orderHandler:    handler.NewOrderHandler(orderUseCase, telemetry),
+		swaggerHandler:  handler.NewSwaggerHandler(),
+		adminHandler:    handler.NewAdminHandler(faults, scenarios, telemetry),
+		sloHandler:      handler.NewSLOHandler(sloEvaluator, sloAlerter, telemetry),
+		faults:          faults,
+		idempotency:     middleware.NewIdempotencyStore(idempotencyTTL),
+		sliRecorder:     sliRecorder,
+		prometheus:      prometheus,
+		telemetry:       telemetry,
+		cartFallback:    cartFallback,
+		trustUserHeader: trustUserHeader,
 	}
 }
 
@@ -42,15 +71,28 @@ func (r *Router) SetupRoutes() *gin.Engine {
 
 	// ミドルウェア設定
 	router.Use(middleware.LoggingMiddleware())
-	router.Use(middleware.RecoveryMiddleware(r.nrClient))
 	router.Use(middleware.CORS())
-	router.Use(middleware.NewRelicMiddleware(r.nrClient))
-	router.Use(middleware.DistributedTracingMiddleware())
-	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.TracingMiddleware())
+	router.Use(middleware.NewRelicMiddleware(r.telemetry))
+	router.Use(middleware.RecoveryMiddleware(r.telemetry))
+	router.Use(middleware.DistributedTracingMiddleware(r.telemetry))
+	router.Use(middleware.RequestIDMiddleware(r.telemetry))
+	router.Use(middleware.SLIMiddleware(r.sliRecorder))
+	router.Use(middleware.PrometheusMiddleware(r.prometheus))
+	router.Use(middleware.FaultInjectionMiddleware(r.faults, r.telemetry))
+	// 認証プロキシが付与した X-User-ID のみを認証済みユーザーとして扱う
+	if r.trustUserHeader {
+		router.Use(middleware.TrustedUserHeaderMiddleware())
+	}
+	// Idempotency-Key を指定した変更系のリクエスト（POST /api/orders など）の再送を防ぐ
+	router.Use(middleware.IdempotencyMiddleware(r.idempotency, r.telemetry))
 
 	// ヘルスチェックエンドポイント
 	router.GET("/health", r.healthHandler.HealthCheck)
 
+	// Prometheus メトリクスエンドポイント
+	router.GET("/metrics", gin.WrapH(r.prometheus.Handler()))
+
 	// APIルートグループ
 	apiV1 := router.Group("/api")
 	{
@@ -58,19 +100,47 @@ func (r *Router) SetupRoutes() *gin.Engine {
 		apiV1.GET("/products", r.productHandler.GetProducts)
 		apiV1.GET("/products/:id", r.productHandler.GetProduct)
 
+		// カートIDの決定（認証済みユーザー / X-Session-ID / Cookie）
+		cartID := middleware.CartIDMiddleware(r.cartFallback, r.telemetry)
+
 		// カート関連エンドポイント
-		apiV1.GET("/cart", r.cartHandler.GetCart)
-		apiV1.POST("/cart/items", r.cartHandler.AddToCart)
-		apiV1.PUT("/cart/items/:id", r.cartHandler.UpdateCartItem)
+		apiV1.GET("/cart", cartID, r.cartHandler.GetCart)
+		apiV1.POST("/cart/items", cartID, r.cartHandler.AddToCart)
+		apiV1.PUT("/cart/items/:id", cartID, r.cartHandler.UpdateCartItem)
+		apiV1.DELETE("/cart/items/:id", cartID, r.cartHandler.RemoveCartItem)
+		apiV1.DELETE("/cart", cartID, r.cartHandler.ClearCart)
+		apiV1.POST("/cart/merge", cartID, r.cartHandler.MergeCart)
+		apiV1.POST("/cart/:action", cartID, r.cartHandler.CartAction) // POST /api/cart/items:batch, /api/cart/prices:confirm
 
 		// 注文関連エンドポイント
-		apiV1.POST("/orders", r.orderHandler.CreateOrder)
+		apiV1.POST("/orders", cartID, r.orderHandler.CreateOrder)
 		apiV1.GET("/orders/:id", r.orderHandler.GetOrder)
+		apiV1.POST("/orders/:id/cancel", r.orderHandler.CancelOrder)
 		apiV1.GET("/orders", r.orderHandler.GetOrders) // オプション: 全注文取得
 
 		// SLMデモ用エンドポイント
 		apiV1.GET("/v1/error", r.productHandler.TriggerError)
 
+		// SLO評価エンドポイント（New Relic なしで達成状況を確認）
+		apiV1.GET("/slo", r.sloHandler.GetSLOs)
+		apiV1.GET("/slo/alerts", r.sloHandler.GetAlerts)
+
+		// SLMデモ用の障害注入設定エンドポイント
+		adminGroup := apiV1.Group("/admin")
+		{
+			adminGroup.GET("/faults", r.adminHandler.GetFaults)
+			adminGroup.PUT("/faults", r.adminHandler.ReplaceFaults)
+			adminGroup.PATCH("/faults", r.adminHandler.MergeFaults)
+			adminGroup.GET("/scenario", r.adminHandler.GetScenario)
+			adminGroup.POST("/scenario", r.adminHandler.StartScenario)
+			adminGroup.DELETE("/scenario", r.adminHandler.StopScenario)
+			adminGroup.PUT("/products/:id/stock", r.productHandler.UpdateStock)
+			adminGroup.PATCH("/products/stock", r.productHandler.AdjustStock)
+			adminGroup.POST("/orders/:id/status", r.orderHandler.UpdateOrderStatus)
+			adminGroup.GET("/payments/dead-letters", r.orderHandler.GetPaymentDeadLetters)
+			adminGroup.POST("/payments/dead-letters/:orderId/requeue", r.orderHandler.RequeuePayment)
+		}
+
 		// Swagger APIドキュメントエンドポイント
 		docsGroup := apiV1.Group("/docs")
 		{
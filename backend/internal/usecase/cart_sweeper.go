@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
+)
+
+// AbandonedCart は商品が入ったまま期限切れで削除されたカート
+type AbandonedCart struct {
+	Cart *entity.Cart
+	Age  time.Duration // カート作成からの経過時間
+	Idle time.Duration // 最終更新からの経過時間
+}
+
+// CartSweeper は一定時間更新されていないカートを定期的に削除する
+// 商品が入ったカートは放棄されたカートとして通知する（空のカートは通知せずに削除）
+type CartSweeper struct {
+	cartRepo    repository.CartRepository
+	ttl         time.Duration
+	onAbandoned func(ctx context.Context, abandoned AbandonedCart)
+	now         func() time.Time
+
+	mutex sync.Mutex
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func NewCartSweeper(cartRepo repository.CartRepository, ttl time.Duration, onAbandoned func(ctx context.Context, abandoned AbandonedCart)) *CartSweeper {
+	return &CartSweeper{
+		cartRepo:    cartRepo,
+		ttl:         ttl,
+		onAbandoned: onAbandoned,
+		now:         time.Now,
+	}
+}
+
+// Sweep は期限切れのカートを削除し、削除した件数を返す
+func (s *CartSweeper) Sweep(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "CartSweeper.Sweep")
+	defer span.End()
+
+	now := s.now()
+	expired, err := s.cartRepo.DeleteExpired(ctx, now.Add(-s.ttl))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired carts: %w", err)
+	}
+
+	for _, cart := range expired {
+		if cart.IsEmpty() || s.onAbandoned == nil {
+			continue
+		}
+		s.onAbandoned(ctx, AbandonedCart{
+			Cart: cart,
+			Age:  now.Sub(cart.CreatedAt),
+			Idle: now.Sub(cart.UpdatedAt),
+		})
+	}
+
+	return len(expired), nil
+}
+
+// Start は interval ごとの定期削除をバックグラウンドで開始する（実行中の場合は何もしない）
+func (s *CartSweeper) Start(interval time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go s.run(interval, s.stop, s.done)
+}
+
+// Stop は定期削除を停止し、実行中の削除の完了を待つ
+func (s *CartSweeper) Stop() {
+	s.mutex.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (s *CartSweeper) run(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := s.Sweep(context.Background()); err != nil {
+				log.Printf("Cart sweep failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase/mocks"
+)
+
+func TestCartSweeper_Sweep(t *testing.T) {
+	now := time.Date(2025, 7, 30, 12, 0, 0, 0, time.UTC)
+	ttl := 30 * time.Minute
+
+	newCart := func(id string, created, updated time.Time, withItems bool) *entity.Cart {
+		cart := entity.NewCart()
+		cart.ID = id
+		if withItems {
+			cart.AddItem(&entity.Product{ID: "product-1", Price: 1500}, 2)
+		}
+		cart.CreatedAt = created
+		cart.UpdatedAt = updated
+		return cart
+	}
+
+	tests := []struct {
+		name              string
+		expired           []*entity.Cart
+		deleteErr         error
+		expectedCount     int
+		expectedAbandoned []AbandonedCart
+		expectedErr       bool
+	}{
+		{
+			name: "商品が入ったカートのみ放棄として通知",
+			expired: []*entity.Cart{
+				newCart("cart-items", now.Add(-2*time.Hour), now.Add(-45*time.Minute), true),
+				newCart("cart-empty", now.Add(-time.Hour), now.Add(-time.Hour), false),
+			},
+			expectedCount: 2,
+			expectedAbandoned: []AbandonedCart{
+				{Age: 2 * time.Hour, Idle: 45 * time.Minute},
+			},
+		},
+		{
+			name:          "期限切れのカートがない",
+			expired:       []*entity.Cart{},
+			expectedCount: 0,
+		},
+		{
+			name:        "リポジトリエラー",
+			deleteErr:   errors.New("database error"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartRepo := &mocks.MockCartRepository{
+				DeleteExpiredFunc: func(ctx context.Context, expiredBefore time.Time) ([]*entity.Cart, error) {
+					return tt.expired, tt.deleteErr
+				},
+			}
+
+			var abandoned []AbandonedCart
+			sweeper := NewCartSweeper(cartRepo, ttl, func(ctx context.Context, cart AbandonedCart) {
+				abandoned = append(abandoned, cart)
+			})
+			sweeper.now = func() time.Time { return now }
+
+			count, err := sweeper.Sweep(context.Background())
+
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("エラー = %v, expectedErr %v", err, tt.expectedErr)
+			}
+			if count != tt.expectedCount {
+				t.Errorf("削除件数 = %v, want %v", count, tt.expectedCount)
+			}
+			if len(cartRepo.DeleteExpiredCalls) != 1 || !cartRepo.DeleteExpiredCalls[0].ExpiredBefore.Equal(now.Add(-ttl)) {
+				t.Errorf("DeleteExpired呼び出し = %+v, want 期限 %v", cartRepo.DeleteExpiredCalls, now.Add(-ttl))
+			}
+			if len(abandoned) != len(tt.expectedAbandoned) {
+				t.Fatalf("放棄カート数 = %v, want %v", len(abandoned), len(tt.expectedAbandoned))
+			}
+			for i, expected := range tt.expectedAbandoned {
+				if abandoned[i].Cart.IsEmpty() {
+					t.Error("空のカートが放棄として通知された")
+				}
+				if abandoned[i].Age != expected.Age || abandoned[i].Idle != expected.Idle {
+					t.Errorf("経過時間 = %v/%v, want %v/%v", abandoned[i].Age, abandoned[i].Idle, expected.Age, expected.Idle)
+				}
+			}
+		})
+	}
+}
+
+func TestCartSweeper_StartStop(t *testing.T) {
+	swept := make(chan struct{}, 1)
+	cartRepo := &mocks.MockCartRepository{
+		DeleteExpiredFunc: func(ctx context.Context, expiredBefore time.Time) ([]*entity.Cart, error) {
+			select {
+			case swept <- struct{}{}:
+			default:
+			}
+			return nil, nil
+		},
+	}
+
+	sweeper := NewCartSweeper(cartRepo, time.Minute, nil)
+	sweeper.Start(time.Millisecond)
+	sweeper.Start(time.Millisecond) // 二重起動しない
+
+	select {
+	case <-swept:
+	case <-time.After(time.Second):
+		t.Fatal("定期削除が実行されない")
+	}
+
+	sweeper.Stop()
+	sweeper.Stop() // 停止済みでも安全
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
@@ -11,16 +12,21 @@ import (
 type CartUseCase struct {
 	cartRepo    repository.CartRepository
 	productRepo repository.ProductRepository
+	inventory   Inventory
 }
 
-func NewCartUseCase(cartRepo repository.CartRepository, productRepo repository.ProductRepository) *CartUseCase {
+func NewCartUseCase(cartRepo repository.CartRepository, productRepo repository.ProductRepository, inventory Inventory) *CartUseCase {
 	return &CartUseCase{
 		cartRepo:    cartRepo,
 		productRepo: productRepo,
+		inventory:   inventory,
 	}
 }
 
 func (uc *CartUseCase) GetCart(ctx context.Context, cartID string) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartUseCase.GetCart")
+	defer span.End()
+
 	if cartID == "" {
 		return nil, entity.ErrInvalidInput
 	}
@@ -34,6 +40,9 @@ func (uc *CartUseCase) GetCart(ctx context.Context, cartID string) (*entity.Cart
 }
 
 func (uc *CartUseCase) AddToCart(ctx context.Context, cartID, productID string, quantity int) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartUseCase.AddToCart")
+	defer span.End()
+
 	if cartID == "" || productID == "" || quantity <= 0 {
 		return nil, entity.ErrInvalidInput
 	}
@@ -50,6 +59,11 @@ func (uc *CartUseCase) AddToCart(ctx context.Context, cartID, productID string,
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 
+	// カート内の数量と合わせて在庫を確認
+	if err := uc.inventory.CheckAvailable(product, cart.QuantityOf(product.ID)+quantity); err != nil {
+		return nil, fmt.Errorf("failed to add item to cart: %w", err)
+	}
+
 	// カートに商品を追加
 	if err := cart.AddItem(product, quantity); err != nil {
 		return nil, fmt.Errorf("failed to add item to cart: %w", err)
@@ -63,7 +77,71 @@ func (uc *CartUseCase) AddToCart(ctx context.Context, cartID, productID string,
 	return cart, nil
 }
 
+// CartItemInput はカートに追加する商品と数量
+type CartItemInput struct {
+	ProductID string
+	Quantity  int
+}
+
+// AddItemsToCart は複数の商品をまとめてカートに追加する
+// 1件でも商品が見つからない場合はカートを変更しない
+func (uc *CartUseCase) AddItemsToCart(ctx context.Context, cartID string, items []CartItemInput) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartUseCase.AddItemsToCart")
+	defer span.End()
+
+	if cartID == "" || len(items) == 0 {
+		return nil, entity.ErrInvalidInput
+	}
+	for _, item := range items {
+		if item.ProductID == "" || item.Quantity <= 0 {
+			return nil, entity.ErrInvalidInput
+		}
+	}
+
+	// 先に全ての商品情報を取得
+	products := make([]*entity.Product, 0, len(items))
+	for _, item := range items {
+		product, err := uc.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product %s: %w", item.ProductID, err)
+		}
+		products = append(products, product)
+	}
+
+	// カートを取得または作成
+	cart, err := uc.cartRepo.GetOrCreate(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	// 同じ商品の行を合算し、カート内の数量と合わせて在庫を確認
+	requested := make(map[string]int, len(items))
+	for i, item := range items {
+		requested[item.ProductID] += item.Quantity
+		if err := uc.inventory.CheckAvailable(products[i], cart.QuantityOf(item.ProductID)+requested[item.ProductID]); err != nil {
+			return nil, fmt.Errorf("failed to add item to cart: %w", err)
+		}
+	}
+
+	// カートに商品を追加
+	for i, item := range items {
+		if err := cart.AddItem(products[i], item.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to add item to cart: %w", err)
+		}
+	}
+
+	// カートを保存
+	if err := uc.cartRepo.Save(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to save cart: %w", err)
+	}
+
+	return cart, nil
+}
+
 func (uc *CartUseCase) UpdateCartItem(ctx context.Context, cartID, itemID string, quantity int) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartUseCase.UpdateCartItem")
+	defer span.End()
+
 	if cartID == "" || itemID == "" {
 		return nil, entity.ErrInvalidInput
 	}
@@ -74,6 +152,22 @@ func (uc *CartUseCase) UpdateCartItem(ctx context.Context, cartID, itemID string
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
 
+	// 変更後の数量の在庫を確認（削除する場合は確認しない）
+	if quantity > 0 {
+		for _, item := range cart.Items {
+			if item.ID != itemID {
+				continue
+			}
+			product, err := uc.productRepo.GetByID(ctx, item.ProductID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get product: %w", err)
+			}
+			if err := uc.inventory.CheckAvailable(product, quantity); err != nil {
+				return nil, fmt.Errorf("failed to update cart item: %w", err)
+			}
+		}
+	}
+
 	// カートアイテムの数量を更新
 	if err := cart.UpdateItemQuantity(itemID, quantity); err != nil {
 		return nil, fmt.Errorf("failed to update cart item: %w", err)
@@ -88,6 +182,9 @@ func (uc *CartUseCase) UpdateCartItem(ctx context.Context, cartID, itemID string
 }
 
 func (uc *CartUseCase) RemoveFromCart(ctx context.Context, cartID, itemID string) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartUseCase.RemoveFromCart")
+	defer span.End()
+
 	if cartID == "" || itemID == "" {
 		return nil, entity.ErrInvalidInput
 	}
@@ -112,6 +209,9 @@ func (uc *CartUseCase) RemoveFromCart(ctx context.Context, cartID, itemID string
 }
 
 func (uc *CartUseCase) ClearCart(ctx context.Context, cartID string) error {
+	ctx, span := startSpan(ctx, "CartUseCase.ClearCart")
+	defer span.End()
+
 	if cartID == "" {
 		return entity.ErrInvalidInput
 	}
@@ -122,3 +222,124 @@ func (uc *CartUseCase) ClearCart(ctx context.Context, cartID string) error {
 
 	return nil
 }
+
+// MergeCarts は sourceCartID のカートを targetCartID のカートに統合し、統合元のカートを削除する
+// 同じ商品は数量を合算し、合算後の数量が在庫を超える場合は在庫数までに制限する（統合先の既存の数量は減らさない。unlimited モードでは制限しない）
+// 統合元のカートが存在しない場合は統合先のカートをそのまま返す
+func (uc *CartUseCase) MergeCarts(ctx context.Context, targetCartID, sourceCartID string) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartUseCase.MergeCarts")
+	defer span.End()
+
+	if targetCartID == "" || sourceCartID == "" || targetCartID == sourceCartID {
+		return nil, entity.ErrInvalidInput
+	}
+
+	// 統合先のカートを取得または作成
+	target, err := uc.cartRepo.GetOrCreate(ctx, targetCartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	// 統合元のカートを取得
+	source, err := uc.cartRepo.GetByID(ctx, sourceCartID)
+	if errors.Is(err, entity.ErrItemNotFound) {
+		return target, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source cart: %w", err)
+	}
+
+	for _, item := range source.Items {
+		product, err := uc.productRepo.GetByID(ctx, item.ProductID)
+		if errors.Is(err, entity.ErrProductNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product %s: %w", item.ProductID, err)
+		}
+
+		// 在庫は AddToCart と同じ基準で確認し、不足する場合のみ在庫数までに制限する（unlimited モードでは制限しない）
+		quantity := item.Quantity
+		if err := uc.inventory.CheckAvailable(product, target.QuantityOf(product.ID)+quantity); err != nil {
+			if !errors.Is(err, entity.ErrInsufficientStock) {
+				return nil, fmt.Errorf("failed to check stock for product %s: %w", product.ID, err)
+			}
+			quantity = product.AvailableStock() - target.QuantityOf(product.ID)
+		}
+		if quantity <= 0 {
+			continue
+		}
+
+		if err := target.AddItem(product, quantity); err != nil {
+			return nil, fmt.Errorf("failed to add item to cart: %w", err)
+		}
+	}
+
+	// カートを保存
+	if err := uc.cartRepo.Save(ctx, target); err != nil {
+		return nil, fmt.Errorf("failed to save cart: %w", err)
+	}
+
+	// 統合元のカートを削除
+	if err := uc.cartRepo.Delete(ctx, sourceCartID); err != nil && !errors.Is(err, entity.ErrItemNotFound) {
+		return nil, fmt.Errorf("failed to delete source cart: %w", err)
+	}
+
+	return target, nil
+}
+
+// PriceChanges はカートに追加した時点から価格が変わった商品を返す
+func (uc *CartUseCase) PriceChanges(ctx context.Context, cart *entity.Cart) ([]entity.PriceChange, error) {
+	ctx, span := startSpan(ctx, "CartUseCase.PriceChanges")
+	defer span.End()
+
+	products, err := currentProducts(ctx, uc.productRepo, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	return cart.PriceChanges(products), nil
+}
+
+// ConfirmPrices はカート内の商品の単価を現在の価格に更新する（価格変更をユーザーが確認した後に使用）
+func (uc *CartUseCase) ConfirmPrices(ctx context.Context, cartID string) (*entity.Cart, error) {
+	ctx, span := startSpan(ctx, "CartUseCase.ConfirmPrices")
+	defer span.End()
+
+	if cartID == "" {
+		return nil, entity.ErrInvalidInput
+	}
+
+	cart, err := uc.cartRepo.GetOrCreate(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	products, err := currentProducts(ctx, uc.productRepo, cart)
+	if err != nil {
+		return nil, err
+	}
+	cart.ApplyCurrentPrices(products)
+
+	if err := uc.cartRepo.Save(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to save cart: %w", err)
+	}
+
+	return cart, nil
+}
+
+// currentProducts はカート内の商品の現在の情報を取得する（販売終了した商品は含めない）
+func currentProducts(ctx context.Context, productRepo repository.ProductRepository, cart *entity.Cart) (map[string]*entity.Product, error) {
+	products := make(map[string]*entity.Product, len(cart.Items))
+	for _, item := range cart.Items {
+		product, err := productRepo.GetByID(ctx, item.ProductID)
+		if errors.Is(err, entity.ErrProductNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product %s: %w", item.ProductID, err)
+		}
+		products[item.ProductID] = product
+	}
+	return products, nil
+}
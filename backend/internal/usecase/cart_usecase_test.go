@@ -75,7 +75,7 @@ func TestCartUseCase_GetCart(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCartRepo := tt.setupMock()
 			mockProductRepo := &mocks.MockProductRepository{}
-			uc := NewCartUseCase(mockCartRepo, mockProductRepo)
+			uc := NewCartUseCase(mockCartRepo, mockProductRepo, NewUnlimitedInventory())
 			ctx := context.Background()
 
 			cart, err := uc.GetCart(ctx, tt.cartID)
@@ -228,14 +228,14 @@ func TestCartUseCase_AddToCart(t *testing.T) {
 				}
 			},
 		},
-		// SLMハンズオン用に在庫チェックが無効化されたため、在庫不足テストケースは削除
+		// 在庫不足（INVENTORY_MODE=tracked）のテストは inventory_test.go
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCartRepo := tt.setupCartMock()
 			mockProductRepo := tt.setupProductMock()
-			uc := NewCartUseCase(mockCartRepo, mockProductRepo)
+			uc := NewCartUseCase(mockCartRepo, mockProductRepo, NewUnlimitedInventory())
 			ctx := context.Background()
 
 			cart, err := uc.AddToCart(ctx, tt.cartID, tt.productID, tt.quantity)
@@ -337,7 +337,7 @@ func TestCartUseCase_UpdateCartItem(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCartRepo := tt.setupMock()
 			mockProductRepo := &mocks.MockProductRepository{}
-			uc := NewCartUseCase(mockCartRepo, mockProductRepo)
+			uc := NewCartUseCase(mockCartRepo, mockProductRepo, NewUnlimitedInventory())
 			ctx := context.Background()
 
 			cart, err := uc.UpdateCartItem(ctx, tt.cartID, tt.itemID, tt.quantity)
@@ -435,7 +435,7 @@ func TestCartUseCase_RemoveFromCart(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCartRepo := tt.setupMock()
 			mockProductRepo := &mocks.MockProductRepository{}
-			uc := NewCartUseCase(mockCartRepo, mockProductRepo)
+			uc := NewCartUseCase(mockCartRepo, mockProductRepo, NewUnlimitedInventory())
 			ctx := context.Background()
 
 			cart, err := uc.RemoveFromCart(ctx, tt.cartID, tt.itemID)
@@ -500,7 +500,7 @@ func TestCartUseCase_ClearCart(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCartRepo := tt.setupMock()
 			mockProductRepo := &mocks.MockProductRepository{}
-			uc := NewCartUseCase(mockCartRepo, mockProductRepo)
+			uc := NewCartUseCase(mockCartRepo, mockProductRepo, NewUnlimitedInventory())
 			ctx := context.Background()
 
 			err := uc.ClearCart(ctx, tt.cartID)
@@ -517,3 +517,325 @@ func TestCartUseCase_ClearCart(t *testing.T) {
 		})
 	}
 }
+
+func TestCartUseCase_AddItemsToCart(t *testing.T) {
+	productMock := func() *mocks.MockProductRepository {
+		return &mocks.MockProductRepository{
+			GetByIDFunc: func(ctx context.Context, id string) (*entity.Product, error) {
+				switch id {
+				case "product-1":
+					product := entity.NewProduct("商品1", "説明", 1000, "image.jpg", 10)
+					product.ID = id
+					return product, nil
+				case "product-2":
+					product := entity.NewProduct("商品2", "説明", 2500, "image.jpg", 10)
+					product.ID = id
+					return product, nil
+				}
+				return nil, entity.ErrProductNotFound
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		cartID        string
+		items         []CartItemInput
+		expectedError error
+		expectedTotal int
+		expectSave    bool
+	}{
+		{
+			name:          "複数の商品をまとめて追加",
+			cartID:        "cart-123",
+			items:         []CartItemInput{{ProductID: "product-1", Quantity: 2}, {ProductID: "product-2", Quantity: 1}},
+			expectedTotal: 4500,
+			expectSave:    true,
+		},
+		{
+			name:          "同じ商品は数量を合算",
+			cartID:        "cart-123",
+			items:         []CartItemInput{{ProductID: "product-1", Quantity: 1}, {ProductID: "product-1", Quantity: 2}},
+			expectedTotal: 3000,
+			expectSave:    true,
+		},
+		{
+			name:          "存在しない商品が含まれる場合はカートを変更しない",
+			cartID:        "cart-123",
+			items:         []CartItemInput{{ProductID: "product-1", Quantity: 1}, {ProductID: "missing", Quantity: 1}},
+			expectedError: entity.ErrProductNotFound,
+		},
+		{
+			name:          "数量0は不正な入力",
+			cartID:        "cart-123",
+			items:         []CartItemInput{{ProductID: "product-1", Quantity: 0}},
+			expectedError: entity.ErrInvalidInput,
+		},
+		{
+			name:          "空の商品リストは不正な入力",
+			cartID:        "cart-123",
+			items:         []CartItemInput{},
+			expectedError: entity.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartMock := &mocks.MockCartRepository{
+				GetOrCreateFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					cart := entity.NewCart()
+					cart.ID = id
+					return cart, nil
+				},
+				SaveFunc: func(ctx context.Context, cart *entity.Cart) error {
+					return nil
+				},
+			}
+			uc := NewCartUseCase(cartMock, productMock(), NewUnlimitedInventory())
+
+			cart, err := uc.AddItemsToCart(context.Background(), tt.cartID, tt.items)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("エラー = %v, want %v", err, tt.expectedError)
+			}
+			if (len(cartMock.SaveCalls) > 0) != tt.expectSave {
+				t.Errorf("Save呼び出し = %v, want %v", len(cartMock.SaveCalls) > 0, tt.expectSave)
+			}
+			if tt.expectedError != nil {
+				if len(cartMock.GetOrCreateCalls) != 0 {
+					t.Error("エラー時にカートを取得している")
+				}
+				return
+			}
+			if cart.TotalAmount != tt.expectedTotal {
+				t.Errorf("合計金額 = %v, want %v", cart.TotalAmount, tt.expectedTotal)
+			}
+		})
+	}
+}
+
+func TestCartUseCase_MergeCarts(t *testing.T) {
+	productMock := func() *mocks.MockProductRepository {
+		return &mocks.MockProductRepository{
+			GetByIDFunc: func(ctx context.Context, id string) (*entity.Product, error) {
+				stocks := map[string]int{"product-1": 10, "product-2": 3}
+				stock, exists := stocks[id]
+				if !exists {
+					return nil, entity.ErrProductNotFound
+				}
+				product := entity.NewProduct(id, "説明", 1000, "image.jpg", stock)
+				product.ID = id
+				return product, nil
+			},
+		}
+	}
+
+	newCart := func(id string, quantities map[string]int) *entity.Cart {
+		cart := entity.NewCart()
+		cart.ID = id
+		for _, productID := range []string{"product-1", "product-2", "discontinued"} {
+			if quantity, exists := quantities[productID]; exists {
+				cart.AddItem(&entity.Product{ID: productID, Price: 1000}, quantity)
+			}
+		}
+		return cart
+	}
+
+	tests := []struct {
+		name               string
+		inventoryMode      string
+		targetCartID       string
+		sourceCartID       string
+		target             map[string]int
+		source             map[string]int // nil の場合は統合元のカートなし
+		expectedError      error
+		expectedQuantities map[string]int
+		expectDelete       bool
+	}{
+		{
+			name:               "同じ商品は数量を合算",
+			targetCartID:       "user-1",
+			sourceCartID:       "session-1",
+			target:             map[string]int{"product-1": 2},
+			source:             map[string]int{"product-1": 3, "product-2": 1},
+			expectedQuantities: map[string]int{"product-1": 5, "product-2": 1},
+			expectDelete:       true,
+		},
+		{
+			name:               "在庫を超える数量は在庫数まで",
+			inventoryMode:      InventoryModeTracked,
+			targetCartID:       "user-1",
+			sourceCartID:       "session-1",
+			target:             map[string]int{"product-2": 2},
+			source:             map[string]int{"product-2": 5},
+			expectedQuantities: map[string]int{"product-2": 3},
+			expectDelete:       true,
+		},
+		{
+			name:               "unlimitedモードでは在庫を超えても制限しない",
+			inventoryMode:      InventoryModeUnlimited,
+			targetCartID:       "user-1",
+			sourceCartID:       "session-1",
+			target:             map[string]int{"product-2": 2},
+			source:             map[string]int{"product-2": 5},
+			expectedQuantities: map[string]int{"product-2": 7},
+			expectDelete:       true,
+		},
+		{
+			name:               "統合先の既存の数量は減らさない",
+			inventoryMode:      InventoryModeTracked,
+			targetCartID:       "user-1",
+			sourceCartID:       "session-1",
+			target:             map[string]int{"product-2": 4},
+			source:             map[string]int{"product-2": 1},
+			expectedQuantities: map[string]int{"product-2": 4},
+			expectDelete:       true,
+		},
+		{
+			name:               "販売終了した商品は統合しない",
+			targetCartID:       "user-1",
+			sourceCartID:       "session-1",
+			target:             map[string]int{},
+			source:             map[string]int{"product-1": 1, "discontinued": 1},
+			expectedQuantities: map[string]int{"product-1": 1, "discontinued": 0},
+			expectDelete:       true,
+		},
+		{
+			name:               "統合元のカートがない場合は何もしない",
+			targetCartID:       "user-1",
+			sourceCartID:       "session-1",
+			target:             map[string]int{"product-1": 1},
+			expectedQuantities: map[string]int{"product-1": 1},
+		},
+		{
+			name:          "同じカートは統合できない",
+			targetCartID:  "user-1",
+			sourceCartID:  "user-1",
+			expectedError: entity.ErrInvalidInput,
+		},
+		{
+			name:          "統合元のカートIDが空",
+			targetCartID:  "user-1",
+			sourceCartID:  "",
+			expectedError: entity.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newCart(tt.targetCartID, tt.target)
+			cartMock := &mocks.MockCartRepository{
+				GetOrCreateFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					return target, nil
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					if tt.source == nil {
+						return nil, entity.ErrItemNotFound
+					}
+					return newCart(id, tt.source), nil
+				},
+			}
+			products := productMock()
+			inventory, err := NewInventory(tt.inventoryMode, products)
+			if err != nil {
+				t.Fatalf("在庫の初期化に失敗: %v", err)
+			}
+			uc := NewCartUseCase(cartMock, products, inventory)
+
+			cart, err := uc.MergeCarts(context.Background(), tt.targetCartID, tt.sourceCartID)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("エラー = %v, want %v", err, tt.expectedError)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+			for productID, expected := range tt.expectedQuantities {
+				if quantity := cart.QuantityOf(productID); quantity != expected {
+					t.Errorf("%s の数量 = %v, want %v", productID, quantity, expected)
+				}
+			}
+			if deleted := len(cartMock.DeleteCalls) == 1 && cartMock.DeleteCalls[0].ID == tt.sourceCartID; deleted != tt.expectDelete {
+				t.Errorf("統合元カートの削除 = %+v, want %v", cartMock.DeleteCalls, tt.expectDelete)
+			}
+		})
+	}
+}
+
+func TestCartUseCase_ConfirmPrices(t *testing.T) {
+	tests := []struct {
+		name                 string
+		cartID               string
+		currentPrice         int
+		expectedError        error
+		expectedPriceChanges int
+		expectedTotal        int
+	}{
+		{
+			name:                 "値上げを確認",
+			cartID:               "cart-123",
+			currentPrice:         1200,
+			expectedPriceChanges: 1,
+			expectedTotal:        2400,
+		},
+		{
+			name:                 "価格が変わっていない",
+			cartID:               "cart-123",
+			currentPrice:         1000,
+			expectedPriceChanges: 0,
+			expectedTotal:        2000,
+		},
+		{
+			name:          "空のカートID",
+			cartID:        "",
+			currentPrice:  1000,
+			expectedError: entity.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := entity.NewCart()
+			cart.ID = tt.cartID
+			cart.AddItem(&entity.Product{ID: "product-1", Price: 1000}, 2)
+
+			cartMock := &mocks.MockCartRepository{
+				GetOrCreateFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					return cart, nil
+				},
+			}
+			productMock := &mocks.MockProductRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Product, error) {
+					return &entity.Product{ID: id, Price: tt.currentPrice}, nil
+				},
+			}
+			uc := NewCartUseCase(cartMock, productMock, NewUnlimitedInventory())
+			ctx := context.Background()
+
+			changes, err := uc.PriceChanges(ctx, cart)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if len(changes) != tt.expectedPriceChanges {
+				t.Errorf("確認前の価格変更数 = %v, want %v", len(changes), tt.expectedPriceChanges)
+			}
+
+			confirmed, err := uc.ConfirmPrices(ctx, tt.cartID)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("エラー = %v, want %v", err, tt.expectedError)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+			if confirmed.TotalAmount != tt.expectedTotal {
+				t.Errorf("合計金額 = %v, want %v", confirmed.TotalAmount, tt.expectedTotal)
+			}
+			if len(cartMock.SaveCalls) != 1 {
+				t.Errorf("Save呼び出し回数 = %v, want 1", len(cartMock.SaveCalls))
+			}
+			if changes, _ := uc.PriceChanges(ctx, confirmed); len(changes) != 0 {
+				t.Errorf("確認後の価格変更 = %v, want なし", changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
+)
+
+// 在庫管理モード
+const (
+	InventoryModeUnlimited = "unlimited" // 在庫を確認・減算しない（SLMハンズオンのデフォルト）
+	InventoryModeTracked   = "tracked"   // 注文時に在庫を引き当て、決済完了で確定、決済失敗・キャンセルで解放する
+)
+
+// Inventory はカート・注文操作での在庫の扱い
+type Inventory interface {
+	// CheckAvailable はカート内の数量分の在庫があるか確認する（不足する場合は ErrInsufficientStock）
+	CheckAvailable(product *entity.Product, quantity int) error
+	// Reserve は注文の全商品の在庫を引き当てる（1件でも不足する場合は何も引き当てない）
+	Reserve(ctx context.Context, order *entity.Order) error
+	// Commit は引き当て中の在庫を確定する
+	Commit(ctx context.Context, order *entity.Order) error
+	// Release は引き当て中の在庫を解放する
+	Release(ctx context.Context, order *entity.Order) error
+	// Restock は確定済みの在庫を在庫数に戻す（決済後のキャンセル用）
+	Restock(ctx context.Context, order *entity.Order) error
+}
+
+// NewInventory は在庫管理モードに対応する Inventory を返す
+func NewInventory(mode string, productRepo repository.ProductRepository) (Inventory, error) {
+	switch mode {
+	case InventoryModeUnlimited, "":
+		return NewUnlimitedInventory(), nil
+	case InventoryModeTracked:
+		return NewTrackedInventory(productRepo), nil
+	}
+	return nil, fmt.Errorf("unknown inventory mode %q", mode)
+}
+
+type unlimitedInventory struct{}
+
+// NewUnlimitedInventory は在庫を確認・変更しない Inventory を返す
+func NewUnlimitedInventory() Inventory {
+	return unlimitedInventory{}
+}
+
+func (unlimitedInventory) CheckAvailable(product *entity.Product, quantity int) error { return nil }
+
+func (unlimitedInventory) Reserve(ctx context.Context, order *entity.Order) error { return nil }
+
+func (unlimitedInventory) Commit(ctx context.Context, order *entity.Order) error { return nil }
+
+func (unlimitedInventory) Release(ctx context.Context, order *entity.Order) error { return nil }
+
+func (unlimitedInventory) Restock(ctx context.Context, order *entity.Order) error { return nil }
+
+type trackedInventory struct {
+	productRepo repository.ProductRepository
+}
+
+// NewTrackedInventory は商品の在庫を引き当て・確定・解放する Inventory を返す
+func NewTrackedInventory(productRepo repository.ProductRepository) Inventory {
+	return &trackedInventory{productRepo: productRepo}
+}
+
+func (i *trackedInventory) CheckAvailable(product *entity.Product, quantity int) error {
+	if !product.IsAvailable(quantity) {
+		return entity.ErrInsufficientStock
+	}
+	return nil
+}
+
+func (i *trackedInventory) Reserve(ctx context.Context, order *entity.Order) error {
+	if err := i.productRepo.ReserveStock(ctx, order.StockQuantities()); err != nil {
+		return fmt.Errorf("failed to reserve stock: %w", err)
+	}
+	order.Reservation = entity.StockReserved
+	return nil
+}
+
+func (i *trackedInventory) Commit(ctx context.Context, order *entity.Order) error {
+	// 引き当て中の注文のみ確定する（二重に減算しない）
+	if order.Reservation != entity.StockReserved {
+		return nil
+	}
+	if err := i.productRepo.CommitStock(ctx, order.StockQuantities()); err != nil {
+		return fmt.Errorf("failed to commit stock: %w", err)
+	}
+	order.Reservation = entity.StockCommitted
+	return nil
+}
+
+func (i *trackedInventory) Release(ctx context.Context, order *entity.Order) error {
+	// 引き当て中の注文のみ解放する（二重に戻さない）
+	if order.Reservation != entity.StockReserved {
+		return nil
+	}
+	if err := i.productRepo.ReleaseStock(ctx, order.StockQuantities()); err != nil {
+		return fmt.Errorf("failed to release stock: %w", err)
+	}
+	order.Reservation = entity.StockReleased
+	return nil
+}
+
+func (i *trackedInventory) Restock(ctx context.Context, order *entity.Order) error {
+	// 確定済みの注文のみ戻す（二重に戻さない）
+	if order.Reservation != entity.StockCommitted {
+		return nil
+	}
+	changes := make([]entity.StockChange, 0, len(order.Items))
+	for _, quantity := range order.StockQuantities() {
+		changes = append(changes, entity.StockChange{ProductID: quantity.ProductID, Delta: quantity.Quantity})
+	}
+	if err := i.productRepo.AdjustStock(ctx, changes); err != nil {
+		return fmt.Errorf("failed to restock: %w", err)
+	}
+	order.Reservation = entity.StockReleased
+	return nil
+}
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase/mocks"
+)
+
+func TestNewInventory(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		expectError bool
+	}{
+		{name: "未指定は在庫無制限", mode: ""},
+		{name: "在庫無制限", mode: InventoryModeUnlimited},
+		{name: "在庫管理", mode: InventoryModeTracked},
+		{name: "未知のモードはエラー", mode: "infinite", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inventory, err := NewInventory(tt.mode, &mocks.MockProductRepository{})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("NewInventory(%q) error = %v, expectError %v", tt.mode, err, tt.expectError)
+			}
+			if !tt.expectError && inventory == nil {
+				t.Error("Inventory が nil")
+			}
+		})
+	}
+}
+
+func TestTrackedInventory(t *testing.T) {
+	ctx := context.Background()
+	productRepo := &mocks.MockProductRepository{}
+	inventory := NewTrackedInventory(productRepo)
+
+	product := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 3)
+	if err := inventory.CheckAvailable(product, 4); !errors.Is(err, entity.ErrInsufficientStock) {
+		t.Errorf("CheckAvailable(4) error = %v, want %v", err, entity.ErrInsufficientStock)
+	}
+	if err := inventory.CheckAvailable(product, 3); err != nil {
+		t.Errorf("CheckAvailable(3) error = %v", err)
+	}
+
+	cart := entity.NewCart()
+	cart.AddItem(product, 2)
+	order, _ := entity.NewOrder(cart)
+
+	if err := inventory.Reserve(ctx, order); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if order.Reservation != entity.StockReserved {
+		t.Errorf("Reservation = %v, want %v", order.Reservation, entity.StockReserved)
+	}
+	if len(productRepo.ReserveStockCalls) != 1 || productRepo.ReserveStockCalls[0].Items[0].Quantity != 2 {
+		t.Errorf("ReserveStock 呼び出し = %+v", productRepo.ReserveStockCalls)
+	}
+
+	// 確定後の確定・解放は在庫を変更しない
+	for i := 0; i < 2; i++ {
+		if err := inventory.Commit(ctx, order); err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+		if err := inventory.Release(ctx, order); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+	}
+	if order.Reservation != entity.StockCommitted {
+		t.Errorf("Reservation = %v, want %v", order.Reservation, entity.StockCommitted)
+	}
+	if len(productRepo.CommitStockCalls) != 1 || len(productRepo.ReleaseStockCalls) != 0 {
+		t.Errorf("CommitStock 呼び出し = %v, ReleaseStock 呼び出し = %v, want 1, 0", len(productRepo.CommitStockCalls), len(productRepo.ReleaseStockCalls))
+	}
+}
+
+func TestTrackedInventory_Restock(t *testing.T) {
+	ctx := context.Background()
+	productRepo := &mocks.MockProductRepository{}
+	inventory := NewTrackedInventory(productRepo)
+
+	product := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 3)
+	product.ID = "product-123"
+	cart := entity.NewCart()
+	cart.AddItem(product, 2)
+	order, _ := entity.NewOrder(cart)
+
+	// 引き当て中の注文は戻し入れの対象外
+	order.Reservation = entity.StockReserved
+	if err := inventory.Restock(ctx, order); err != nil {
+		t.Fatalf("Restock() error = %v", err)
+	}
+	if len(productRepo.AdjustStockCalls) != 0 {
+		t.Fatalf("AdjustStock 呼び出し回数 = %v, want 0", len(productRepo.AdjustStockCalls))
+	}
+
+	// 確定済みの在庫は一度だけ戻す
+	order.Reservation = entity.StockCommitted
+	for i := 0; i < 2; i++ {
+		if err := inventory.Restock(ctx, order); err != nil {
+			t.Fatalf("Restock() error = %v", err)
+		}
+	}
+	if len(productRepo.AdjustStockCalls) != 1 {
+		t.Fatalf("AdjustStock 呼び出し回数 = %v, want 1", len(productRepo.AdjustStockCalls))
+	}
+	if changes := productRepo.AdjustStockCalls[0].Changes; len(changes) != 1 || changes[0].ProductID != "product-123" || changes[0].Delta != 2 {
+		t.Errorf("AdjustStock の変更 = %+v", changes)
+	}
+	if order.Reservation != entity.StockReleased {
+		t.Errorf("Reservation = %v, want %v", order.Reservation, entity.StockReleased)
+	}
+}
+
+func TestOrderUseCase_CreateOrder_TrackedInventory(t *testing.T) {
+	tests := []struct {
+		name             string
+		reserveErr       error
+		createErr        error
+		queueCapacity    int
+		expectedErr      error
+		expectedReleases int
+		expectedUpdates  int
+	}{
+		{
+			name:          "在庫を引き当てて注文を作成",
+			queueCapacity: 1,
+		},
+		{
+			name:          "在庫不足",
+			reserveErr:    entity.ErrInsufficientStock,
+			queueCapacity: 1,
+			expectedErr:   entity.ErrInsufficientStock,
+		},
+		{
+			name:             "注文の保存に失敗した場合は引き当てを解放",
+			createErr:        errors.New("database error"),
+			queueCapacity:    1,
+			expectedReleases: 1,
+		},
+		{
+			name:             "決済キューが満杯の場合は注文を失敗にして引き当てを解放",
+			queueCapacity:    0,
+			expectedErr:      entity.ErrPaymentUnavailable,
+			expectedReleases: 1,
+			expectedUpdates:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 10)
+			product.ID = "product-123"
+
+			orderRepo := &mocks.MockOrderRepository{
+				CreateFunc: func(ctx context.Context, order *entity.Order) error { return tt.createErr },
+			}
+			cartRepo := &mocks.MockCartRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Cart, error) {
+					cart := entity.NewCart()
+					cart.ID = id
+					cart.AddItem(product, 2)
+					return cart, nil
+				},
+			}
+			productRepo := &mocks.MockProductRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Product, error) { return product, nil },
+				ReserveStockFunc: func(ctx context.Context, items []entity.StockQuantity) error {
+					return tt.reserveErr
+				},
+			}
+			uc := NewOrderUseCase(orderRepo, cartRepo, productRepo, NewTrackedInventory(productRepo), &mocks.MockPaymentGateway{}, newTestPaymentQueue(tt.queueCapacity), newTestRetryer(), nil)
+
+			order, err := uc.CreateOrder(context.Background(), "cart-123")
+
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CreateOrder() error = %v, want %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr == nil && tt.createErr == nil {
+				if err != nil {
+					t.Fatalf("予期しないエラー: %v", err)
+				}
+				if order.Reservation != entity.StockReserved {
+					t.Errorf("Reservation = %v, want %v", order.Reservation, entity.StockReserved)
+				}
+			}
+			if len(productRepo.ReleaseStockCalls) != tt.expectedReleases {
+				t.Errorf("ReleaseStock 呼び出し回数 = %v, want %v", len(productRepo.ReleaseStockCalls), tt.expectedReleases)
+			}
+			if len(orderRepo.UpdateCalls) != tt.expectedUpdates {
+				t.Errorf("Update 呼び出し回数 = %v, want %v", len(orderRepo.UpdateCalls), tt.expectedUpdates)
+			}
+			if tt.expectedUpdates > 0 && orderRepo.UpdateCalls[0].Order.Status != entity.OrderStatusFailed {
+				t.Errorf("Status = %v, want %v", orderRepo.UpdateCalls[0].Order.Status, entity.OrderStatusFailed)
+			}
+		})
+	}
+}
+
+func TestCartUseCase_TrackedInventory(t *testing.T) {
+	ctx := context.Background()
+	product := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 3)
+	product.ID = "product-123"
+	cart := entity.NewCart()
+	cart.ID = "cart-123"
+
+	cartRepo := &mocks.MockCartRepository{
+		GetOrCreateFunc: func(ctx context.Context, id string) (*entity.Cart, error) { return cart, nil },
+		GetByIDFunc:     func(ctx context.Context, id string) (*entity.Cart, error) { return cart, nil },
+		SaveFunc:        func(ctx context.Context, cart *entity.Cart) error { return nil },
+	}
+	productRepo := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*entity.Product, error) { return product, nil },
+	}
+	uc := NewCartUseCase(cartRepo, productRepo, NewTrackedInventory(productRepo))
+
+	if _, err := uc.AddToCart(ctx, cart.ID, product.ID, 2); err != nil {
+		t.Fatalf("AddToCart(2) error = %v", err)
+	}
+	// カート内の数量と合わせて在庫を超える
+	if _, err := uc.AddToCart(ctx, cart.ID, product.ID, 2); !errors.Is(err, entity.ErrInsufficientStock) {
+		t.Errorf("AddToCart(2) error = %v, want %v", err, entity.ErrInsufficientStock)
+	}
+	if _, err := uc.AddItemsToCart(ctx, cart.ID, []CartItemInput{{ProductID: product.ID, Quantity: 1}, {ProductID: product.ID, Quantity: 1}}); !errors.Is(err, entity.ErrInsufficientStock) {
+		t.Errorf("AddItemsToCart() error = %v, want %v", err, entity.ErrInsufficientStock)
+	}
+	if _, err := uc.UpdateCartItem(ctx, cart.ID, cart.Items[0].ID, 4); !errors.Is(err, entity.ErrInsufficientStock) {
+		t.Errorf("UpdateCartItem(4) error = %v, want %v", err, entity.ErrInsufficientStock)
+	}
+	if quantity := cart.QuantityOf(product.ID); quantity != 2 {
+		t.Errorf("カート内の数量 = %v, want 2", quantity)
+	}
+}
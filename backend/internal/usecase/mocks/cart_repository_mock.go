@@ -2,17 +2,19 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 )
 
 // MockCartRepository はCartRepositoryのモック実装
 type MockCartRepository struct {
-	GetByIDFunc     func(ctx context.Context, id string) (*entity.Cart, error)
-	GetOrCreateFunc func(ctx context.Context, id string) (*entity.Cart, error)
-	SaveFunc        func(ctx context.Context, cart *entity.Cart) error
-	DeleteFunc      func(ctx context.Context, id string) error
-	ClearFunc       func(ctx context.Context, id string) error
+	GetByIDFunc       func(ctx context.Context, id string) (*entity.Cart, error)
+	GetOrCreateFunc   func(ctx context.Context, id string) (*entity.Cart, error)
+	SaveFunc          func(ctx context.Context, cart *entity.Cart) error
+	DeleteFunc        func(ctx context.Context, id string) error
+	ClearFunc         func(ctx context.Context, id string) error
+	DeleteExpiredFunc func(ctx context.Context, expiredBefore time.Time) ([]*entity.Cart, error)
 
 	// 呼び出し記録用
 	GetByIDCalls []struct {
@@ -35,6 +37,10 @@ type MockCartRepository struct {
 		Ctx context.Context
 		ID  string
 	}
+	DeleteExpiredCalls []struct {
+		Ctx           context.Context
+		ExpiredBefore time.Time
+	}
 }
 
 func (m *MockCartRepository) GetByID(ctx context.Context, id string) (*entity.Cart, error) {
@@ -91,3 +97,14 @@ func (m *MockCartRepository) Clear(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+func (m *MockCartRepository) DeleteExpired(ctx context.Context, expiredBefore time.Time) ([]*entity.Cart, error) {
+	m.DeleteExpiredCalls = append(m.DeleteExpiredCalls, struct {
+		Ctx           context.Context
+		ExpiredBefore time.Time
+	}{ctx, expiredBefore})
+	if m.DeleteExpiredFunc != nil {
+		return m.DeleteExpiredFunc(ctx, expiredBefore)
+	}
+	return nil, nil
+}
@@ -0,0 +1,86 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+)
+
+// MockPaymentGateway はPaymentGatewayのモック実装
+// 関数が未設定の場合は成功の結果を返す（Get は ErrPaymentNotFound。決済処理はバックグラウンドで呼ばれるため呼び出し記録はロックで保護する）
+type MockPaymentGateway struct {
+	AuthorizeFunc func(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error)
+	CaptureFunc   func(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error)
+	RefundFunc    func(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error)
+	VoidFunc      func(ctx context.Context, transactionID string) (*entity.PaymentResult, error)
+	GetFunc       func(ctx context.Context, transactionID string) (*entity.PaymentResult, error)
+
+	// 呼び出し記録用
+	mutex          sync.Mutex
+	AuthorizeCalls []entity.PaymentRequest
+	CaptureCalls   []string
+	RefundCalls    []string
+	VoidCalls      []string
+	GetCalls       []string
+}
+
+func (m *MockPaymentGateway) Authorize(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error) {
+	m.mutex.Lock()
+	m.AuthorizeCalls = append(m.AuthorizeCalls, req)
+	m.mutex.Unlock()
+	if m.AuthorizeFunc != nil {
+		return m.AuthorizeFunc(ctx, req)
+	}
+	return mockPaymentResult("txn-"+req.OrderID, entity.PaymentStatusAuthorized, req.Amount), nil
+}
+
+func (m *MockPaymentGateway) Capture(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+	m.mutex.Lock()
+	m.CaptureCalls = append(m.CaptureCalls, transactionID)
+	m.mutex.Unlock()
+	if m.CaptureFunc != nil {
+		return m.CaptureFunc(ctx, transactionID, amount)
+	}
+	return mockPaymentResult(transactionID, entity.PaymentStatusCaptured, amount), nil
+}
+
+func (m *MockPaymentGateway) Refund(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+	m.mutex.Lock()
+	m.RefundCalls = append(m.RefundCalls, transactionID)
+	m.mutex.Unlock()
+	if m.RefundFunc != nil {
+		return m.RefundFunc(ctx, transactionID, amount)
+	}
+	return mockPaymentResult(transactionID, entity.PaymentStatusRefunded, amount), nil
+}
+
+func (m *MockPaymentGateway) Void(ctx context.Context, transactionID string) (*entity.PaymentResult, error) {
+	m.mutex.Lock()
+	m.VoidCalls = append(m.VoidCalls, transactionID)
+	m.mutex.Unlock()
+	if m.VoidFunc != nil {
+		return m.VoidFunc(ctx, transactionID)
+	}
+	return mockPaymentResult(transactionID, entity.PaymentStatusVoided, 0), nil
+}
+
+func (m *MockPaymentGateway) Get(ctx context.Context, transactionID string) (*entity.PaymentResult, error) {
+	m.mutex.Lock()
+	m.GetCalls = append(m.GetCalls, transactionID)
+	m.mutex.Unlock()
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, transactionID)
+	}
+	return nil, entity.ErrPaymentNotFound
+}
+
+func mockPaymentResult(transactionID string, status entity.PaymentStatus, amount int) *entity.PaymentResult {
+	return &entity.PaymentResult{
+		TransactionID: transactionID,
+		Status:        status,
+		Amount:        amount,
+		ProcessedAt:   time.Now(),
+	}
+}
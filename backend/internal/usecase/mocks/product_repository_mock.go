@@ -8,14 +8,19 @@ import (
 
 // MockProductRepository はProductRepositoryのモック実装
 type MockProductRepository struct {
-	GetAllFunc        func(ctx context.Context) ([]*entity.Product, error)
-	GetByIDFunc       func(ctx context.Context, id string) (*entity.Product, error)
-	CreateFunc        func(ctx context.Context, product *entity.Product) error
-	UpdateFunc        func(ctx context.Context, product *entity.Product) error
-	DeleteFunc        func(ctx context.Context, id string) error
-	UpdateStockFunc   func(ctx context.Context, id string, newStock int) error
-	DecreaseStockFunc func(ctx context.Context, id string, quantity int) error
-	IncreaseStockFunc func(ctx context.Context, id string, quantity int) error
+	GetAllFunc              func(ctx context.Context) ([]*entity.Product, error)
+	GetByIDFunc             func(ctx context.Context, id string) (*entity.Product, error)
+	CreateFunc              func(ctx context.Context, product *entity.Product) error
+	UpdateFunc              func(ctx context.Context, product *entity.Product) error
+	DeleteFunc              func(ctx context.Context, id string) error
+	UpdateStockFunc         func(ctx context.Context, id string, newStock int) error
+	UpdateStockSettingsFunc func(ctx context.Context, id string, settings entity.StockSettings) error
+	DecreaseStockFunc       func(ctx context.Context, id string, quantity int) error
+	IncreaseStockFunc       func(ctx context.Context, id string, quantity int) error
+	ReserveStockFunc        func(ctx context.Context, items []entity.StockQuantity) error
+	CommitStockFunc         func(ctx context.Context, items []entity.StockQuantity) error
+	ReleaseStockFunc        func(ctx context.Context, items []entity.StockQuantity) error
+	AdjustStockFunc         func(ctx context.Context, changes []entity.StockChange) error
 
 	// 呼び出し記録用
 	GetAllCalls  []context.Context
@@ -40,6 +45,11 @@ type MockProductRepository struct {
 		ID       string
 		NewStock int
 	}
+	UpdateStockSettingsCalls []struct {
+		Ctx      context.Context
+		ID       string
+		Settings entity.StockSettings
+	}
 	DecreaseStockCalls []struct {
 		Ctx      context.Context
 		ID       string
@@ -50,6 +60,22 @@ type MockProductRepository struct {
 		ID       string
 		Quantity int
 	}
+	ReserveStockCalls []struct {
+		Ctx   context.Context
+		Items []entity.StockQuantity
+	}
+	CommitStockCalls []struct {
+		Ctx   context.Context
+		Items []entity.StockQuantity
+	}
+	ReleaseStockCalls []struct {
+		Ctx   context.Context
+		Items []entity.StockQuantity
+	}
+	AdjustStockCalls []struct {
+		Ctx     context.Context
+		Changes []entity.StockChange
+	}
 }
 
 func (m *MockProductRepository) GetAll(ctx context.Context) ([]*entity.Product, error) {
@@ -116,6 +142,18 @@ func (m *MockProductRepository) UpdateStock(ctx context.Context, id string, newS
 	return nil
 }
 
+func (m *MockProductRepository) UpdateStockSettings(ctx context.Context, id string, settings entity.StockSettings) error {
+	m.UpdateStockSettingsCalls = append(m.UpdateStockSettingsCalls, struct {
+		Ctx      context.Context
+		ID       string
+		Settings entity.StockSettings
+	}{ctx, id, settings})
+	if m.UpdateStockSettingsFunc != nil {
+		return m.UpdateStockSettingsFunc(ctx, id, settings)
+	}
+	return nil
+}
+
 func (m *MockProductRepository) DecreaseStock(ctx context.Context, id string, quantity int) error {
 	m.DecreaseStockCalls = append(m.DecreaseStockCalls, struct {
 		Ctx      context.Context
@@ -139,3 +177,47 @@ func (m *MockProductRepository) IncreaseStock(ctx context.Context, id string, qu
 	}
 	return nil
 }
+
+func (m *MockProductRepository) ReserveStock(ctx context.Context, items []entity.StockQuantity) error {
+	m.ReserveStockCalls = append(m.ReserveStockCalls, struct {
+		Ctx   context.Context
+		Items []entity.StockQuantity
+	}{ctx, items})
+	if m.ReserveStockFunc != nil {
+		return m.ReserveStockFunc(ctx, items)
+	}
+	return nil
+}
+
+func (m *MockProductRepository) CommitStock(ctx context.Context, items []entity.StockQuantity) error {
+	m.CommitStockCalls = append(m.CommitStockCalls, struct {
+		Ctx   context.Context
+		Items []entity.StockQuantity
+	}{ctx, items})
+	if m.CommitStockFunc != nil {
+		return m.CommitStockFunc(ctx, items)
+	}
+	return nil
+}
+
+func (m *MockProductRepository) ReleaseStock(ctx context.Context, items []entity.StockQuantity) error {
+	m.ReleaseStockCalls = append(m.ReleaseStockCalls, struct {
+		Ctx   context.Context
+		Items []entity.StockQuantity
+	}{ctx, items})
+	if m.ReleaseStockFunc != nil {
+		return m.ReleaseStockFunc(ctx, items)
+	}
+	return nil
+}
+
+func (m *MockProductRepository) AdjustStock(ctx context.Context, changes []entity.StockChange) error {
+	m.AdjustStockCalls = append(m.AdjustStockCalls, struct {
+		Ctx     context.Context
+		Changes []entity.StockChange
+	}{ctx, changes})
+	if m.AdjustStockFunc != nil {
+		return m.AdjustStockFunc(ctx, changes)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import "sync"
+
+// orderLocks は注文ごとのロック
+// 同じ注文のステータス変更（決済結果の反映・キャンセルなど）を直列化し、別の注文は並行に処理する
+// ロックは注文の読み込みから保存までの短い区間だけ保持し、決済ゲートウェイの呼び出しや再試行の待機中は保持しない
+type orderLocks struct {
+	mutex sync.Mutex
+	locks map[string]*orderLock
+}
+
+type orderLock struct {
+	sync.Mutex
+	refs int // ロックを保持・待機・参照している数（0 になったら map から削除する）
+
+	// ロックの外で返金中・注文を保存中（完了するまで他のステータス変更を受け付けない。ロックを保持して読み書きする）
+	busy bool
+	idle *sync.Cond // busy が解除されたことを待つ
+}
+
+func newOrderLocks() *orderLocks {
+	return &orderLocks{locks: make(map[string]*orderLock)}
+}
+
+// lock は注文のロックを取得し、ロックの状態と解放する関数を返す
+func (l *orderLocks) lock(orderID string) (*orderLock, func()) {
+	l.mutex.Lock()
+	lock, exists := l.locks[orderID]
+	if !exists {
+		lock = &orderLock{}
+		lock.idle = sync.NewCond(&lock.Mutex)
+		l.locks[orderID] = lock
+	}
+	lock.refs++
+	l.mutex.Unlock()
+
+	lock.Lock()
+	return lock, func() {
+		lock.Unlock()
+		l.release(orderID, lock)
+	}
+}
+
+// lockIdle は注文のロックを取得する。ロックの外で返金中・保存中の注文は、完了するまで（ロックを解放して）待つ
+func (l *orderLocks) lockIdle(orderID string) (*orderLock, func()) {
+	lock, unlock := l.lock(orderID)
+	for lock.busy {
+		lock.idle.Wait()
+	}
+	return lock, unlock
+}
+
+// retain はロックを解放した後もロックの状態（busy）を保持し、保持をやめる関数を返す
+func (l *orderLocks) retain(orderID string, lock *orderLock) func() {
+	l.mutex.Lock()
+	lock.refs++
+	l.mutex.Unlock()
+
+	return func() { l.release(orderID, lock) }
+}
+
+func (l *orderLocks) release(orderID string, lock *orderLock) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, orderID)
+	}
+}
+
+// setBusy は返金中・保存中の状態を変更し、解除した場合は lockIdle で待っている処理を再開する（ロックを保持して呼び出す）
+func (lock *orderLock) setBusy(busy bool) {
+	lock.busy = busy
+	if !busy {
+		lock.idle.Broadcast()
+	}
+}
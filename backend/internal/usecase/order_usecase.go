@@ -2,42 +2,63 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand"
-	"os"
-	"strconv"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
 )
 
+// PaymentOutcome は注文に反映した決済の結果（決済成功率SLIの計測用）
+type PaymentOutcome struct {
+	Order    *entity.Order
+	Success  bool
+	Duration time.Duration // 決済ゲートウェイの処理時間
+}
+
 type OrderUseCase struct {
 	orderRepo   repository.OrderRepository
 	cartRepo    repository.CartRepository
 	productRepo repository.ProductRepository
+	inventory   Inventory
+	payments    PaymentGateway
+	queue       *PaymentQueue
+	retryer     *Retryer // 注文の保存の再試行
+	onPayment   func(ctx context.Context, outcome PaymentOutcome)
+
+	// 決済結果の反映とキャンセルなどのステータス変更を注文ごとに直列化する
+	locks *orderLocks
 }
 
 func NewOrderUseCase(
 	orderRepo repository.OrderRepository,
 	cartRepo repository.CartRepository,
 	productRepo repository.ProductRepository,
+	inventory Inventory,
+	payments PaymentGateway,
+	queue *PaymentQueue,
+	retryer *Retryer,
+	onPayment func(ctx context.Context, outcome PaymentOutcome),
 ) *OrderUseCase {
 	return &OrderUseCase{
 		orderRepo:   orderRepo,
 		cartRepo:    cartRepo,
 		productRepo: productRepo,
+		inventory:   inventory,
+		payments:    payments,
+		queue:       queue,
+		retryer:     retryer,
+		onPayment:   onPayment,
+		locks:       newOrderLocks(),
 	}
 }
 
 func (uc *OrderUseCase) CreateOrder(ctx context.Context, cartID string) (*entity.Order, error) {
-	// SLMデモ用のレスポンス時間調整
-	uc.simulateResponseTime()
-
-	// SLMデモ用のランダムエラー生成
-	if uc.shouldSimulateError() {
-		return nil, fmt.Errorf("simulated order processing error")
-	}
+	ctx, span := startSpan(ctx, "OrderUseCase.CreateOrder")
+	defer span.End()
 
 	if cartID == "" {
 		return nil, entity.ErrInvalidInput
@@ -53,8 +74,15 @@ func (uc *OrderUseCase) CreateOrder(ctx context.Context, cartID string) (*entity
 		return nil, entity.ErrEmptyCart
 	}
 
-	// SLMハンズオン用に在庫チェックを無効化
-	// 在庫確認は行わず、すべての商品が利用可能として処理
+	// カートに追加した後に価格が変わった商品がある場合は、ユーザーの確認なしに注文しない
+	products, err := currentProducts(ctx, uc.productRepo, cart)
+	if err != nil {
+		return nil, err
+	}
+	if changes := cart.PriceChanges(products); len(changes) > 0 {
+		span.SetAttributes(attribute.Int("cart.priceChanges", len(changes)))
+		return nil, entity.ErrPriceChanged
+	}
 
 	// 注文を作成
 	order, err := entity.NewOrder(cart)
@@ -62,14 +90,25 @@ func (uc *OrderUseCase) CreateOrder(ctx context.Context, cartID string) (*entity
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
 
-	// SLMハンズオン用に在庫減少処理を無効化
-	// 在庫は減らさず、注文のみ作成
+	// 在庫を引き当て（INVENTORY_MODE=unlimited の場合は何もしない）
+	if err := uc.inventory.Reserve(ctx, order); err != nil {
+		return nil, err
+	}
 
 	// 注文を保存
 	if err := uc.orderRepo.Create(ctx, order); err != nil {
+		uc.restoreStock(ctx, order)
 		return nil, fmt.Errorf("failed to save order: %w", err)
 	}
 
+	// 決済処理をキューに追加（決済ゲートウェイの応答を待たずに注文を返す）
+	// リクエストのキャンセルは引き継がず、トレースのみ引き継いで子スパンとして記録する
+	if err := uc.queue.Enqueue(ctx, order); err != nil {
+		// 決済できない注文は失敗にして在庫を戻す（カートはそのまま残して再注文できるようにする）
+		uc.rejectPayment(ctx, order)
+		return nil, err
+	}
+
 	// カートをクリア
 	cart.Clear()
 	if err := uc.cartRepo.Save(ctx, cart); err != nil {
@@ -77,13 +116,13 @@ func (uc *OrderUseCase) CreateOrder(ctx context.Context, cartID string) (*entity
 		fmt.Printf("warning: failed to clear cart after order creation: %v\n", err)
 	}
 
-	// 決済処理のシミュレーション（非同期処理を模擬）
-	go uc.processPayment(context.Background(), order)
-
 	return order, nil
 }
 
 func (uc *OrderUseCase) GetOrder(ctx context.Context, orderID string) (*entity.Order, error) {
+	ctx, span := startSpan(ctx, "OrderUseCase.GetOrder")
+	defer span.End()
+
 	if orderID == "" {
 		return nil, entity.ErrInvalidInput
 	}
@@ -97,6 +136,9 @@ func (uc *OrderUseCase) GetOrder(ctx context.Context, orderID string) (*entity.O
 }
 
 func (uc *OrderUseCase) GetAllOrders(ctx context.Context) ([]*entity.Order, error) {
+	ctx, span := startSpan(ctx, "OrderUseCase.GetAllOrders")
+	defer span.End()
+
 	orders, err := uc.orderRepo.GetAll(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get orders: %w", err)
@@ -105,87 +147,403 @@ func (uc *OrderUseCase) GetAllOrders(ctx context.Context) ([]*entity.Order, erro
 	return orders, nil
 }
 
-// 決済処理のシミュレーション
-func (uc *OrderUseCase) processPayment(ctx context.Context, order *entity.Order) {
-	// 決済処理時間をシミュレート
-	processingTime := 2 + rand.Intn(8) // 2-10秒
-	time.Sleep(time.Duration(processingTime) * time.Second)
+// PaymentDeadLetters は再試行を使い切った決済ジョブを返す
+func (uc *OrderUseCase) PaymentDeadLetters(ctx context.Context) []PaymentDeadLetter {
+	return uc.queue.DeadLetters()
+}
+
+// RequeuePayment はデッドレターの注文の決済を再実行する（決済待ちで売上未確定の注文のみ）
+func (uc *OrderUseCase) RequeuePayment(ctx context.Context, orderID string) (*entity.Order, error) {
+	ctx, span := startSpan(ctx, "OrderUseCase.RequeuePayment")
+	defer span.End()
+
+	if orderID == "" {
+		return nil, entity.ErrInvalidInput
+	}
+
+	_, unlock := uc.locks.lockIdle(orderID)
+	defer unlock()
+
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if !order.IsPending() {
+		return nil, fmt.Errorf("cannot requeue payment for %s order: %w", order.Status, entity.ErrInvalidOrderStatus)
+	}
+	// 売上確定後に注文の保存に失敗した注文を再実行すると二重に請求するため受け付けない
+	if order.Payment.CapturedAt != nil {
+		return nil, fmt.Errorf("payment %s is already captured: %w", order.Payment.ProviderReference, entity.ErrInvalidOrderStatus)
+	}
+
+	if err := uc.queue.Requeue(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CancelOrder は出荷前の注文をキャンセルし、在庫を戻す
+func (uc *OrderUseCase) CancelOrder(ctx context.Context, orderID string, reason string) (*entity.Order, error) {
+	ctx, span := startSpan(ctx, "OrderUseCase.CancelOrder")
+	defer span.End()
+
+	if reason == "" {
+		reason = "canceled by customer"
+	}
+	return uc.transitionOrder(ctx, orderID, entity.OrderStatusCanceled, reason)
+}
+
+// TransitionOrder は注文のステータスを遷移表に従って変更する（出荷・配達・返金などの管理操作用）
+func (uc *OrderUseCase) TransitionOrder(ctx context.Context, orderID string, status entity.OrderStatus, reason string) (*entity.Order, error) {
+	ctx, span := startSpan(ctx, "OrderUseCase.TransitionOrder")
+	defer span.End()
+
+	if !status.IsValid() {
+		return nil, entity.ErrInvalidInput
+	}
+	return uc.transitionOrder(ctx, orderID, status, reason)
+}
+
+func (uc *OrderUseCase) transitionOrder(ctx context.Context, orderID string, status entity.OrderStatus, reason string) (*entity.Order, error) {
+	if orderID == "" {
+		return nil, entity.ErrInvalidInput
+	}
+
+	lock, unlock := uc.locks.lock(orderID)
+
+	order, err := uc.loadForTransition(ctx, lock, orderID, status)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	// 売上確定済みの注文のキャンセル・返金は、決済を返金できた場合のみ受け付ける
+	// 返金（決済ゲートウェイの呼び出し）の間はロックを解放し、同じ注文の他のステータス変更は返金中として受け付けない
+	if (status == entity.OrderStatusCanceled || status == entity.OrderStatusRefunded) && order.Payment.IsCaptured() {
+		reference, amount := order.Payment.ProviderReference, order.TotalAmount
+		lock.setBusy(true)
+		stopRetaining := uc.locks.retain(orderID, lock)
+		unlock()
+
+		refund, refundErr := uc.payments.Refund(ctx, reference, amount)
+
+		lock, unlock = uc.locks.lock(orderID)
+		lock.setBusy(false)
+		stopRetaining()
+		if refundErr != nil {
+			unlock()
+			return nil, fmt.Errorf("failed to refund payment: %w", refundErr)
+		}
+
+		// 返金中は他のステータス変更を受け付けていないため、返金前と同じ状態の注文に結果を反映する
+		if order, err = uc.loadForTransition(ctx, lock, orderID, status); err != nil {
+			unlock()
+			fmt.Printf("error: refunded payment %s but could not update order %s: %v\n", reference, orderID, err)
+			return nil, err
+		}
+		order.Payment.RecordResult(refund)
+	}
+
+	from := order.Status
+	if err := order.TransitionTo(status, reason); err != nil {
+		unlock()
+		return nil, fmt.Errorf("cannot change order status from %s to %s: %w", from, status, err)
+	}
+
+	// 決済待ちでなくなった注文は決済の再試行・デッドレターから取り除く
+	uc.queue.Forget(ctx, order.ID)
+
+	// キャンセルした注文の在庫を戻す（決済前は引き当ての解放、決済後は確定した在庫の戻し入れ）
+	if status == entity.OrderStatusCanceled {
+		uc.restoreStock(ctx, order)
+		if err := uc.inventory.Restock(ctx, order); err != nil {
+			fmt.Printf("error: failed to restock canceled order %s: %v\n", order.ID, err)
+		}
+	}
+
+	if err := uc.saveOrder(ctx, order, lock, unlock); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// loadForTransition は注文のロックを保持した状態で注文を読み込み、status に遷移できるか確認する
+func (uc *OrderUseCase) loadForTransition(ctx context.Context, lock *orderLock, orderID string, status entity.OrderStatus) (*entity.Order, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if lock.busy {
+		return nil, fmt.Errorf("order %s is being updated: %w", orderID, entity.ErrInvalidOrderStatus)
+	}
+	if !order.Status.CanTransitionTo(status) {
+		return nil, fmt.Errorf("cannot change order status from %s to %s: %w", order.Status, status, entity.ErrInvalidOrderStatus)
+	}
+	return order, nil
+}
+
+// ProcessPayment は与信を取得して売上を確定し、結果を注文に反映する（決済キューのワーカーから呼び出す）
+// 決済ゲートウェイの一時的な障害では注文を決済待ちのまま残し、再試行できるエラー（IsRetryable）を返す
+// 注文の保存に失敗した場合は Retryer に従ってその場で保存を再試行する（再試行の待機中は注文のロックを保持しない）
+func (uc *OrderUseCase) ProcessPayment(ctx context.Context, order *entity.Order) error {
+	ctx, span := startSpan(ctx, "OrderUseCase.ProcessPayment")
+	defer span.End()
+
+	order, ok, err := uc.startPaymentAttempt(ctx, order)
+	if err != nil || !ok {
+		return err
+	}
+
+	start := time.Now()
+	authorization, captured, err := uc.chargePayment(ctx, order)
+	duration := time.Since(start)
+	success := err == nil && captured != nil
+	span.SetAttributes(
+		attribute.String("order.id", order.ID),
+		attribute.Int("payment.attempts", order.Payment.Attempts),
+		attribute.Int64("payment.processingTimeMs", duration.Milliseconds()),
+		attribute.Bool("payment.success", success),
+	)
+	if result := lastPaymentResult(authorization, captured); result != nil {
+		span.SetAttributes(
+			attribute.String("payment.transactionId", result.TransactionID),
+			attribute.String("payment.status", string(result.Status)),
+			attribute.String("payment.declineCode", result.DeclineCode),
+		)
+	}
+	if err != nil {
+		span.RecordError(err)
+		fmt.Printf("error: payment for order %s failed: %v\n", order.ID, err)
+	}
+
+	// 決済処理中にキャンセル・返金中の注文は、その保存が終わってから読み込む
+	lock, unlock := uc.locks.lockIdle(order.ID)
+
+	// 決済処理中にキャンセルされた注文には結果を反映せず、確定した売上を返金する（返金はロックを解放してから）
+	if current, err := uc.orderRepo.GetByID(ctx, order.ID); err == nil && current != nil {
+		order = current
+	}
+	if !order.IsPending() {
+		unlock()
+		fmt.Printf("warning: order %s is %s, skipping payment result\n", order.ID, order.Status)
+		uc.reversePayment(ctx, captured)
+		return nil
+	}
+
+	// 決済ゲートウェイの応答と失敗理由を注文に記録
+	for _, result := range []*entity.PaymentResult{authorization, captured} {
+		if result != nil {
+			order.Payment.RecordResult(result)
+		}
+	}
+	if err != nil {
+		order.Payment.RecordFailure(paymentFailureReason(err), time.Now())
+		span.SetAttributes(
+			attribute.String("payment.failureReason", order.Payment.FailureReason),
+			attribute.Bool("payment.retryable", IsRetryable(err)),
+		)
+	}
+
+	// 一時的な障害は注文を決済待ちのまま残して再試行する（失敗理由は payment に記録）
+	if IsRetryable(err) {
+		if saveErr := uc.saveOrder(ctx, order, lock, unlock); saveErr != nil {
+			return saveErr
+		}
+		return err
+	}
 
-	// ランダムに決済成功/失敗を決定
-	paymentSuccessRate := 0.9 // 90%の成功率
-	if rand.Float64() < paymentSuccessRate {
-		order.Complete()
+	if success {
+		order.Pay()
+		// 引き当てた在庫を確定
+		if err := uc.inventory.Commit(ctx, order); err != nil {
+			fmt.Printf("error: failed to commit stock for order %s: %v\n", order.ID, err)
+		}
 	} else {
-		order.Fail()
-		// 在庫を戻す処理（実際の実装では必要）
+		order.FailPayment()
+		// 引き当てた在庫を戻す
 		uc.restoreStock(ctx, order)
 	}
 
+	if uc.onPayment != nil {
+		uc.onPayment(ctx, PaymentOutcome{Order: order, Success: success, Duration: duration})
+	}
+
 	// 注文状態を更新
-	if err := uc.orderRepo.Update(ctx, order); err != nil {
+	return uc.saveOrder(ctx, order, lock, unlock)
+}
+
+// rejectPayment は決済キューに追加できなかった注文を失敗にして在庫の引き当てを解放する
+func (uc *OrderUseCase) rejectPayment(ctx context.Context, order *entity.Order) {
+	lock, unlock := uc.locks.lockIdle(order.ID)
+
+	order.Payment.RecordFailure(entity.PaymentFailureUnavailable, time.Now())
+	order.FailPayment()
+	uc.restoreStock(ctx, order)
+	if err := uc.saveOrder(ctx, order, lock, unlock); err != nil {
 		fmt.Printf("error: failed to update order status: %v\n", err)
 	}
 }
 
-func (uc *OrderUseCase) restoreStock(ctx context.Context, order *entity.Order) {
-	for _, item := range order.Items {
-		if err := uc.productRepo.IncreaseStock(ctx, item.ProductID, item.Quantity); err != nil {
-			fmt.Printf("error: failed to restore stock for product %s: %v\n", item.ProductID, err)
-		}
+// startPaymentAttempt は決済の試行回数と日時を注文に記録する（決済前にキャンセルされた注文は決済しない）
+func (uc *OrderUseCase) startPaymentAttempt(ctx context.Context, order *entity.Order) (*entity.Order, bool, error) {
+	lock, unlock := uc.locks.lockIdle(order.ID)
+
+	if current, err := uc.orderRepo.GetByID(ctx, order.ID); err == nil && current != nil {
+		order = current
+	}
+	if !order.IsPending() {
+		unlock()
+		fmt.Printf("warning: order %s is %s, skipping payment\n", order.ID, order.Status)
+		return order, false, nil
+	}
+
+	order.Payment.StartAttempt(time.Now())
+	if err := uc.saveOrder(ctx, order, lock, unlock); err != nil {
+		return order, false, err
 	}
+	return order, true, nil
 }
 
-// SLMデモ用のレスポンス時間シミュレーション
-func (uc *OrderUseCase) simulateResponseTime() {
-	minTime := uc.getEnvInt("RESPONSE_TIME_MIN", 50)
-	maxTime := uc.getEnvInt("RESPONSE_TIME_MAX", 500)
+// saveOrder は Retryer に従って注文の保存を再試行する
+// 呼び出し元が保持している注文のロックを解放してから保存し（再試行の待機中もロックを保持しない）、
+// 保存が終わるまで同じ注文のキャンセルなどのステータス変更は保存中として受け付けず、決済の処理（lockIdle）は保存の完了を待つ
+func (uc *OrderUseCase) saveOrder(ctx context.Context, order *entity.Order, lock *orderLock, unlock func()) error {
+	lock.setBusy(true)
+	stopRetaining := uc.locks.retain(order.ID, lock)
+	unlock()
 
-	// 注文処理は通常より時間がかかる
-	maxTime = maxTime * 2
+	err := uc.retryer.Do(ctx, func() error {
+		return uc.orderRepo.Update(ctx, order)
+	})
 
-	// 一定確率で遅いレスポンスを生成
-	slowEndpointRate := uc.getEnvFloat("SLOW_ENDPOINT_RATE", 0.2)
-	if rand.Float64() < slowEndpointRate {
-		maxTime = maxTime * (2 + rand.Intn(2))
-	}
+	lock, unlock = uc.locks.lock(order.ID)
+	lock.setBusy(false)
+	unlock()
+	stopRetaining()
 
-	if maxTime > minTime {
-		responseTime := minTime + rand.Intn(maxTime-minTime)
-		time.Sleep(time.Duration(responseTime) * time.Millisecond)
+	if err != nil {
+		return fmt.Errorf("failed to update order %s: %w", order.ID, err)
 	}
+	return nil
 }
 
-// SLMデモ用のランダムエラー生成
-func (uc *OrderUseCase) shouldSimulateError() bool {
-	errorRate := uc.getEnvFloat("ERROR_RATE", 0.1)
-	// 注文処理はより高いエラー率
-	return rand.Float64() < (errorRate * 1.5)
+// chargePayment は与信を取得して売上を確定する
+// 与信が拒否された場合は拒否の結果のみを返す（captured は nil）
+// 前回の試行の与信が残っている場合は新たに与信を取得せず、売上確定済みであればその結果を返す
+func (uc *OrderUseCase) chargePayment(ctx context.Context, order *entity.Order) (authorization, captured *entity.PaymentResult, err error) {
+	authorization, err = uc.existingAuthorization(ctx, order)
+	if err != nil {
+		return nil, nil, err
+	}
+	if authorization != nil && authorization.Status == entity.PaymentStatusCaptured {
+		return nil, authorization, nil
+	}
+
+	if authorization == nil {
+		authorization, err = uc.payments.Authorize(ctx, entity.NewPaymentRequest(order))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to authorize payment: %w", err)
+		}
+		if authorization.IsDeclined() {
+			return authorization, nil, nil
+		}
+
+		// 売上確定の結果が不明になった場合に次の試行で確認できるよう、与信の取引IDを先に保存する
+		if err := uc.recordAuthorization(ctx, order.ID, authorization); err != nil {
+			if _, voidErr := uc.payments.Void(ctx, authorization.TransactionID); voidErr != nil {
+				fmt.Printf("error: failed to void payment %s: %v\n", authorization.TransactionID, voidErr)
+			}
+			return authorization, nil, err
+		}
+	}
+
+	captured, err = uc.payments.Capture(ctx, authorization.TransactionID, authorization.Amount)
+	if IsRetryable(err) {
+		// タイムアウトなどでは売上が確定したか不明なため与信を取り消さず、次の試行で取引IDから状態を確認する
+		return authorization, nil, fmt.Errorf("capture outcome unknown for payment %s: %w", authorization.TransactionID, err)
+	}
+	if err != nil {
+		// 売上を確定できなかった与信は取り消す
+		if _, voidErr := uc.payments.Void(ctx, authorization.TransactionID); voidErr != nil {
+			fmt.Printf("error: failed to void payment %s: %v\n", authorization.TransactionID, voidErr)
+		}
+		return authorization, nil, fmt.Errorf("failed to capture payment: %w", err)
+	}
+	return authorization, captured, nil
 }
 
-func (uc *OrderUseCase) getEnvInt(key string, defaultValue int) int {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// existingAuthorization は前回の試行で取得した与信の現在の状態を決済ゲートウェイに確認する
+// 与信済み・売上確定済みの結果のみを返し、再利用できる与信がない場合は nil を返す
+func (uc *OrderUseCase) existingAuthorization(ctx context.Context, order *entity.Order) (*entity.PaymentResult, error) {
+	reference := order.Payment.ProviderReference
+	if reference == "" {
+		return nil, nil
 	}
 
-	intValue, err := strconv.Atoi(value)
+	result, err := uc.payments.Get(ctx, reference)
+	if errors.Is(err, entity.ErrPaymentNotFound) {
+		return nil, nil
+	}
 	if err != nil {
-		return defaultValue
+		return nil, fmt.Errorf("failed to check payment %s: %w", reference, err)
+	}
+	if result.Status != entity.PaymentStatusAuthorized && result.Status != entity.PaymentStatusCaptured {
+		return nil, nil
 	}
+	return result, nil
+}
+
+// recordAuthorization は売上確定の前に与信の取引IDを注文に保存する（決済待ちでなくなった注文は保存しない）
+func (uc *OrderUseCase) recordAuthorization(ctx context.Context, orderID string, authorization *entity.PaymentResult) error {
+	lock, unlock := uc.locks.lockIdle(orderID)
 
-	return intValue
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		unlock()
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	if !order.IsPending() {
+		unlock()
+		return nil
+	}
+
+	order.Payment.RecordResult(authorization)
+	return uc.saveOrder(ctx, order, lock, unlock)
 }
 
-func (uc *OrderUseCase) getEnvFloat(key string, defaultValue float64) float64 {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// reversePayment は注文に反映しなかった売上確定済みの決済を返金する
+func (uc *OrderUseCase) reversePayment(ctx context.Context, result *entity.PaymentResult) {
+	if result == nil || result.Status != entity.PaymentStatusCaptured {
+		return
 	}
+	if _, err := uc.payments.Refund(ctx, result.TransactionID, result.Amount); err != nil {
+		fmt.Printf("error: failed to refund payment %s: %v\n", result.TransactionID, err)
+	}
+}
 
-	floatValue, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		return defaultValue
+// lastPaymentResult は決済ゲートウェイの最後の応答を返す
+func lastPaymentResult(results ...*entity.PaymentResult) *entity.PaymentResult {
+	var last *entity.PaymentResult
+	for _, result := range results {
+		if result != nil {
+			last = result
+		}
+	}
+	return last
+}
+
+// paymentFailureReason は決済ゲートウェイのエラーを失敗理由に変換する
+func paymentFailureReason(err error) string {
+	if errors.Is(err, entity.ErrPaymentUnavailable) {
+		return entity.PaymentFailureUnavailable
 	}
+	return entity.PaymentFailureError
+}
 
-	return floatValue
+// restoreStock は決済に失敗した注文の在庫の引き当てを解放する
+func (uc *OrderUseCase) restoreStock(ctx context.Context, order *entity.Order) {
+	if err := uc.inventory.Release(ctx, order); err != nil {
+		fmt.Printf("error: failed to restore stock for order %s: %v\n", order.ID, err)
+	}
 }
@@ -3,24 +3,14 @@ package usecase
 import (
 	"context"
 	"errors"
-	"os"
 	"testing"
+	"time"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase/mocks"
 )
 
 func TestOrderUseCase_CreateOrder(t *testing.T) {
-	// エラーシミュレーションを無効化
-	os.Setenv("ERROR_RATE", "0")
-	os.Setenv("RESPONSE_TIME_MIN", "0")
-	os.Setenv("RESPONSE_TIME_MAX", "0")
-	defer func() {
-		os.Unsetenv("ERROR_RATE")
-		os.Unsetenv("RESPONSE_TIME_MIN")
-		os.Unsetenv("RESPONSE_TIME_MAX")
-	}()
-
 	tests := []struct {
 		name             string
 		cartID           string
@@ -58,6 +48,11 @@ func TestOrderUseCase_CreateOrder(t *testing.T) {
 			},
 			setupProductMock: func() *mocks.MockProductRepository {
 				mock := &mocks.MockProductRepository{}
+				mock.GetByIDFunc = func(ctx context.Context, id string) (*entity.Product, error) {
+					product := entity.NewProduct("テスト商品", "説明", 1500, "image.jpg", 10)
+					product.ID = id
+					return product, nil
+				}
 				mock.DecreaseStockFunc = func(ctx context.Context, id string, quantity int) error {
 					return nil
 				}
@@ -123,7 +118,41 @@ func TestOrderUseCase_CreateOrder(t *testing.T) {
 				}
 			},
 		},
-		// SLMハンズオン用に在庫チェックと在庫減少が無効化されたため、在庫関連エラーテストケースは削除
+		{
+			name:   "カート追加後に価格が変わった場合はエラー",
+			cartID: "cart-123",
+			setupOrderMock: func() *mocks.MockOrderRepository {
+				return &mocks.MockOrderRepository{}
+			},
+			setupCartMock: func() *mocks.MockCartRepository {
+				mock := &mocks.MockCartRepository{}
+				mock.GetByIDFunc = func(ctx context.Context, id string) (*entity.Cart, error) {
+					cart := entity.NewCart()
+					cart.ID = id
+					product := entity.NewProduct("テスト商品", "説明", 1500, "image.jpg", 10)
+					product.ID = "product-123"
+					cart.AddItem(product, 2)
+					return cart, nil
+				}
+				return mock
+			},
+			setupProductMock: func() *mocks.MockProductRepository {
+				mock := &mocks.MockProductRepository{}
+				mock.GetByIDFunc = func(ctx context.Context, id string) (*entity.Product, error) {
+					product := entity.NewProduct("テスト商品", "説明", 1800, "image.jpg", 10)
+					product.ID = id
+					return product, nil
+				}
+				return mock
+			},
+			expectError: true,
+			checkResult: func(t *testing.T, order *entity.Order) {
+				if order != nil {
+					t.Error("注文がnilであるべきです")
+				}
+			},
+		},
+		// 在庫関連エラー（INVENTORY_MODE=tracked）のテストは inventory_test.go
 		{
 			name:   "注文保存でエラー",
 			cartID: "cart-123",
@@ -148,6 +177,11 @@ func TestOrderUseCase_CreateOrder(t *testing.T) {
 			},
 			setupProductMock: func() *mocks.MockProductRepository {
 				mock := &mocks.MockProductRepository{}
+				mock.GetByIDFunc = func(ctx context.Context, id string) (*entity.Product, error) {
+					product := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 10)
+					product.ID = id
+					return product, nil
+				}
 				mock.DecreaseStockFunc = func(ctx context.Context, id string, quantity int) error {
 					return nil
 				}
@@ -167,7 +201,7 @@ func TestOrderUseCase_CreateOrder(t *testing.T) {
 			mockOrderRepo := tt.setupOrderMock()
 			mockCartRepo := tt.setupCartMock()
 			mockProductRepo := tt.setupProductMock()
-			uc := NewOrderUseCase(mockOrderRepo, mockCartRepo, mockProductRepo)
+			uc := NewOrderUseCase(mockOrderRepo, mockCartRepo, mockProductRepo, NewUnlimitedInventory(), &mocks.MockPaymentGateway{}, newTestPaymentQueue(10), newTestRetryer(), nil)
 			ctx := context.Background()
 
 			order, err := uc.CreateOrder(ctx, tt.cartID)
@@ -260,7 +294,7 @@ func TestOrderUseCase_GetOrder(t *testing.T) {
 			mockOrderRepo := tt.setupMock()
 			mockCartRepo := &mocks.MockCartRepository{}
 			mockProductRepo := &mocks.MockProductRepository{}
-			uc := NewOrderUseCase(mockOrderRepo, mockCartRepo, mockProductRepo)
+			uc := NewOrderUseCase(mockOrderRepo, mockCartRepo, mockProductRepo, NewUnlimitedInventory(), &mocks.MockPaymentGateway{}, newTestPaymentQueue(10), newTestRetryer(), nil)
 			ctx := context.Background()
 
 			order, err := uc.GetOrder(ctx, tt.orderID)
@@ -350,7 +384,7 @@ func TestOrderUseCase_GetAllOrders(t *testing.T) {
 			mockOrderRepo := tt.setupMock()
 			mockCartRepo := &mocks.MockCartRepository{}
 			mockProductRepo := &mocks.MockProductRepository{}
-			uc := NewOrderUseCase(mockOrderRepo, mockCartRepo, mockProductRepo)
+			uc := NewOrderUseCase(mockOrderRepo, mockCartRepo, mockProductRepo, NewUnlimitedInventory(), &mocks.MockPaymentGateway{}, newTestPaymentQueue(10), newTestRetryer(), nil)
 			ctx := context.Background()
 
 			orders, err := uc.GetAllOrders(ctx)
@@ -375,3 +409,668 @@ func TestOrderUseCase_GetAllOrders(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderUseCase_ProcessPayment(t *testing.T) {
+	tests := []struct {
+		name                  string
+		setupGateway          func(gateway *mocks.MockPaymentGateway)
+		cancelAt              int // GetByID の何回目の呼び出しでキャンセル済みにするか（0 はキャンセルしない）
+		updateFailures        int // Update が失敗する回数
+		expectedErr           error
+		expectedStatus        entity.OrderStatus
+		expectedPayment       entity.PaymentStatus
+		expectedFailureReason string
+		expectedDeclineCode   string
+		expectedAuthorize     int
+		expectedCapture       int
+		expectedVoid          int
+		expectedRefund        int
+		expectedUpdates       int
+		expectedOutcomes      int
+	}{
+		{
+			name:              "与信と売上確定に成功して決済完了",
+			setupGateway:      func(gateway *mocks.MockPaymentGateway) {},
+			expectedStatus:    entity.OrderStatusPaid,
+			expectedPayment:   entity.PaymentStatusCaptured,
+			expectedAuthorize: 1,
+			expectedCapture:   1,
+			expectedUpdates:   3,
+			expectedOutcomes:  1,
+		},
+		{
+			name: "与信を拒否されて決済失敗",
+			setupGateway: func(gateway *mocks.MockPaymentGateway) {
+				gateway.AuthorizeFunc = func(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error) {
+					return &entity.PaymentResult{TransactionID: "txn-1", Status: entity.PaymentStatusDeclined, DeclineCode: entity.DeclineInsufficientFunds}, nil
+				}
+			},
+			expectedStatus:        entity.OrderStatusFailed,
+			expectedPayment:       entity.PaymentStatusDeclined,
+			expectedFailureReason: entity.PaymentFailureDeclined,
+			expectedDeclineCode:   entity.DeclineInsufficientFunds,
+			expectedAuthorize:     1,
+			expectedUpdates:       2,
+			expectedOutcomes:      1,
+		},
+		{
+			name: "決済ゲートウェイの障害は注文を決済待ちのまま残して再試行",
+			setupGateway: func(gateway *mocks.MockPaymentGateway) {
+				gateway.AuthorizeFunc = func(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error) {
+					return nil, entity.ErrPaymentUnavailable
+				}
+			},
+			expectedErr:           entity.ErrPaymentUnavailable,
+			expectedStatus:        entity.OrderStatusPending,
+			expectedPayment:       entity.PaymentStatusFailed,
+			expectedFailureReason: entity.PaymentFailureUnavailable,
+			expectedAuthorize:     1,
+			expectedUpdates:       2,
+		},
+		{
+			name: "再試行できない決済ゲートウェイのエラーで決済失敗",
+			setupGateway: func(gateway *mocks.MockPaymentGateway) {
+				gateway.AuthorizeFunc = func(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error) {
+					return nil, entity.ErrInvalidInput
+				}
+			},
+			expectedStatus:        entity.OrderStatusFailed,
+			expectedPayment:       entity.PaymentStatusFailed,
+			expectedFailureReason: entity.PaymentFailureError,
+			expectedAuthorize:     1,
+			expectedUpdates:       2,
+			expectedOutcomes:      1,
+		},
+		{
+			name:              "注文の保存に失敗した場合は決済をやり直さずに保存を再試行",
+			setupGateway:      func(gateway *mocks.MockPaymentGateway) {},
+			updateFailures:    1,
+			expectedStatus:    entity.OrderStatusPaid,
+			expectedPayment:   entity.PaymentStatusCaptured,
+			expectedAuthorize: 1,
+			expectedCapture:   1,
+			expectedUpdates:   4,
+			expectedOutcomes:  1,
+		},
+		{
+			name: "売上確定に失敗した与信は取り消す",
+			setupGateway: func(gateway *mocks.MockPaymentGateway) {
+				gateway.CaptureFunc = func(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+					return nil, errors.New("unexpected response")
+				}
+			},
+			expectedStatus:        entity.OrderStatusFailed,
+			expectedPayment:       entity.PaymentStatusFailed,
+			expectedFailureReason: entity.PaymentFailureError,
+			expectedAuthorize:     1,
+			expectedCapture:       1,
+			expectedVoid:          1,
+			expectedUpdates:       3,
+			expectedOutcomes:      1,
+		},
+		{
+			name: "売上確定の結果が不明な場合は与信を取り消さずに再試行",
+			setupGateway: func(gateway *mocks.MockPaymentGateway) {
+				gateway.CaptureFunc = func(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+					return nil, entity.ErrPaymentUnavailable
+				}
+			},
+			expectedErr:           entity.ErrPaymentUnavailable,
+			expectedStatus:        entity.OrderStatusPending,
+			expectedPayment:       entity.PaymentStatusFailed,
+			expectedFailureReason: entity.PaymentFailureUnavailable,
+			expectedAuthorize:     1,
+			expectedCapture:       1,
+			expectedUpdates:       3,
+		},
+		{
+			name:              "決済前にキャンセルされた注文は決済しない",
+			setupGateway:      func(gateway *mocks.MockPaymentGateway) {},
+			cancelAt:          1,
+			expectedStatus:    entity.OrderStatusCanceled,
+			expectedPayment:   entity.PaymentStatusPending,
+			expectedAuthorize: 0,
+		},
+		{
+			name:              "決済中にキャンセルされた注文は返金して結果を反映しない",
+			setupGateway:      func(gateway *mocks.MockPaymentGateway) {},
+			cancelAt:          2,
+			expectedStatus:    entity.OrderStatusCanceled,
+			expectedPayment:   entity.PaymentStatusPending,
+			expectedAuthorize: 1,
+			expectedCapture:   1,
+			expectedRefund:    1,
+			expectedUpdates:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := entity.NewCart()
+			cart.AddItem(entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 10), 2)
+			order, _ := entity.NewOrder(cart)
+
+			getCalls := 0
+			orderRepo := &mocks.MockOrderRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Order, error) {
+					getCalls++
+					if getCalls == tt.cancelAt {
+						order.Cancel("")
+					}
+					return order, nil
+				},
+			}
+			orderRepo.UpdateFunc = func(ctx context.Context, order *entity.Order) error {
+				if len(orderRepo.UpdateCalls) <= tt.updateFailures {
+					return errors.New("database error")
+				}
+				return nil
+			}
+			gateway := &mocks.MockPaymentGateway{}
+			tt.setupGateway(gateway)
+			var outcomes []PaymentOutcome
+			uc := NewOrderUseCase(orderRepo, &mocks.MockCartRepository{}, &mocks.MockProductRepository{}, NewUnlimitedInventory(), gateway, newTestPaymentQueue(10), newTestRetryer(), func(ctx context.Context, outcome PaymentOutcome) {
+				outcomes = append(outcomes, outcome)
+			})
+
+			err := uc.ProcessPayment(context.Background(), order)
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("ProcessPayment() error = %v, want %v", err, tt.expectedErr)
+			}
+			if order.Status != tt.expectedStatus {
+				t.Errorf("Status = %v, want %v", order.Status, tt.expectedStatus)
+			}
+			if order.Payment.Status != tt.expectedPayment {
+				t.Errorf("Payment.Status = %v, want %v", order.Payment.Status, tt.expectedPayment)
+			}
+			if order.Payment.FailureReason != tt.expectedFailureReason {
+				t.Errorf("Payment.FailureReason = %q, want %q", order.Payment.FailureReason, tt.expectedFailureReason)
+			}
+			if order.Payment.DeclineCode != tt.expectedDeclineCode {
+				t.Errorf("Payment.DeclineCode = %q, want %q", order.Payment.DeclineCode, tt.expectedDeclineCode)
+			}
+			if order.Payment.Attempts != tt.expectedAuthorize {
+				t.Errorf("Payment.Attempts = %v, want %v", order.Payment.Attempts, tt.expectedAuthorize)
+			}
+			if len(gateway.AuthorizeCalls) != tt.expectedAuthorize {
+				t.Fatalf("Authorize 呼び出し回数 = %v, want %v", len(gateway.AuthorizeCalls), tt.expectedAuthorize)
+			}
+			if tt.expectedAuthorize > 0 && (gateway.AuthorizeCalls[0].Amount != 2000 || gateway.AuthorizeCalls[0].Currency != entity.PaymentCurrency) {
+				t.Errorf("Authorize 呼び出し = %+v", gateway.AuthorizeCalls)
+			}
+			if len(gateway.CaptureCalls) != tt.expectedCapture {
+				t.Errorf("Capture 呼び出し回数 = %v, want %v", len(gateway.CaptureCalls), tt.expectedCapture)
+			}
+			if len(gateway.VoidCalls) != tt.expectedVoid {
+				t.Errorf("Void 呼び出し回数 = %v, want %v", len(gateway.VoidCalls), tt.expectedVoid)
+			}
+			if len(gateway.RefundCalls) != tt.expectedRefund {
+				t.Errorf("Refund 呼び出し回数 = %v, want %v", len(gateway.RefundCalls), tt.expectedRefund)
+			}
+			if len(orderRepo.UpdateCalls) != tt.expectedUpdates {
+				t.Errorf("Update 呼び出し回数 = %v, want %v", len(orderRepo.UpdateCalls), tt.expectedUpdates)
+			}
+			if len(outcomes) != tt.expectedOutcomes {
+				t.Fatalf("決済結果の通知回数 = %v, want %v", len(outcomes), tt.expectedOutcomes)
+			}
+			if tt.expectedOutcomes > 0 && outcomes[0].Success != (tt.expectedStatus == entity.OrderStatusPaid) {
+				t.Errorf("Success = %v, want %v", outcomes[0].Success, tt.expectedStatus == entity.OrderStatusPaid)
+			}
+		})
+	}
+}
+
+// TestOrderUseCase_ProcessPayment_CaptureOutcomeUnknown は売上確定の結果が不明なまま再試行した場合に、
+// 保存した取引IDで決済の状態を確認して二重に与信・売上確定しないことを確認する
+func TestOrderUseCase_ProcessPayment_CaptureOutcomeUnknown(t *testing.T) {
+	cart := entity.NewCart()
+	cart.AddItem(&entity.Product{ID: "product-123", Price: 1000}, 2)
+	order, _ := entity.NewOrder(cart)
+
+	orderRepo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*entity.Order, error) {
+			return order, nil
+		},
+	}
+	gateway := &mocks.MockPaymentGateway{
+		// 売上は確定したが応答がタイムアウトした
+		CaptureFunc: func(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+			return nil, entity.ErrPaymentUnavailable
+		},
+		GetFunc: func(ctx context.Context, transactionID string) (*entity.PaymentResult, error) {
+			return &entity.PaymentResult{TransactionID: transactionID, Status: entity.PaymentStatusCaptured, Amount: 2000}, nil
+		},
+	}
+	uc := NewOrderUseCase(orderRepo, &mocks.MockCartRepository{}, &mocks.MockProductRepository{}, NewUnlimitedInventory(), gateway, newTestPaymentQueue(10), newTestRetryer(), nil)
+
+	if err := uc.ProcessPayment(context.Background(), order); !errors.Is(err, entity.ErrPaymentUnavailable) {
+		t.Fatalf("1回目の ProcessPayment() error = %v, want %v", err, entity.ErrPaymentUnavailable)
+	}
+	if order.Payment.ProviderReference != "txn-"+order.ID {
+		t.Errorf("ProviderReference = %q, want %q", order.Payment.ProviderReference, "txn-"+order.ID)
+	}
+	if len(gateway.VoidCalls) != 0 {
+		t.Errorf("Void 呼び出し回数 = %v, want 0", len(gateway.VoidCalls))
+	}
+
+	if err := uc.ProcessPayment(context.Background(), order); err != nil {
+		t.Fatalf("2回目の ProcessPayment() error = %v", err)
+	}
+	if order.Status != entity.OrderStatusPaid || order.Payment.Status != entity.PaymentStatusCaptured {
+		t.Errorf("Status = %v, Payment.Status = %v", order.Status, order.Payment.Status)
+	}
+	if len(gateway.GetCalls) != 1 || gateway.GetCalls[0] != "txn-"+order.ID {
+		t.Errorf("Get 呼び出し = %v", gateway.GetCalls)
+	}
+	if len(gateway.AuthorizeCalls) != 1 || len(gateway.CaptureCalls) != 1 {
+		t.Errorf("Authorize 呼び出し回数 = %v, Capture 呼び出し回数 = %v, want 1, 1", len(gateway.AuthorizeCalls), len(gateway.CaptureCalls))
+	}
+}
+
+// TestOrderUseCase_RequeuePayment は売上確定済み・決済待ちでない注文の決済を再実行しないことを確認する
+func TestOrderUseCase_RequeuePayment(t *testing.T) {
+	capturedAt := time.Now()
+	tests := []struct {
+		name        string
+		setupOrder  func(order *entity.Order)
+		expectedErr error
+	}{
+		{
+			name: "売上確定済みの注文は再実行しない",
+			setupOrder: func(order *entity.Order) {
+				order.Payment.ProviderReference = "txn-1"
+				order.Payment.CapturedAt = &capturedAt
+			},
+			expectedErr: entity.ErrInvalidOrderStatus,
+		},
+		{
+			name: "決済済みの注文は再実行しない",
+			setupOrder: func(order *entity.Order) {
+				order.Pay()
+			},
+			expectedErr: entity.ErrInvalidOrderStatus,
+		},
+		{
+			name:        "デッドレターにない注文",
+			setupOrder:  func(order *entity.Order) {},
+			expectedErr: entity.ErrOrderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := entity.NewCart()
+			cart.AddItem(&entity.Product{ID: "product-123", Price: 1000}, 2)
+			order, _ := entity.NewOrder(cart)
+			tt.setupOrder(order)
+
+			orderRepo := &mocks.MockOrderRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Order, error) {
+					return order, nil
+				},
+			}
+			uc := NewOrderUseCase(orderRepo, &mocks.MockCartRepository{}, &mocks.MockProductRepository{}, NewUnlimitedInventory(), &mocks.MockPaymentGateway{}, newTestPaymentQueue(10), newTestRetryer(), nil)
+
+			if _, err := uc.RequeuePayment(context.Background(), order.ID); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("RequeuePayment() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+// TestOrderUseCase_ProcessPayment_CancelSaveInFlight はキャンセルの保存中に届いた決済結果が保存の完了を待って反映されることを確認する
+func TestOrderUseCase_ProcessPayment_CancelSaveInFlight(t *testing.T) {
+	cart := entity.NewCart()
+	cart.AddItem(&entity.Product{ID: "product-123", Price: 1000}, 2)
+	order, _ := entity.NewOrder(cart)
+
+	stored := order.Clone()
+	saving := make(chan struct{})
+	releaseSave := make(chan struct{})
+	paidWhileSaving := false
+	orderRepo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*entity.Order, error) {
+			return stored.Clone(), nil
+		},
+		UpdateFunc: func(ctx context.Context, order *entity.Order) error {
+			switch order.Status {
+			case entity.OrderStatusCanceled:
+				close(saving)
+				<-releaseSave
+			case entity.OrderStatusPaid:
+				paidWhileSaving = stored.Status != entity.OrderStatusCanceled
+			}
+			stored = order.Clone()
+			return nil
+		},
+	}
+	capturing := make(chan struct{})
+	releaseCapture := make(chan struct{})
+	gateway := &mocks.MockPaymentGateway{
+		CaptureFunc: func(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+			close(capturing)
+			<-releaseCapture
+			return &entity.PaymentResult{TransactionID: transactionID, Status: entity.PaymentStatusCaptured, Amount: amount}, nil
+		},
+	}
+	uc := NewOrderUseCase(orderRepo, &mocks.MockCartRepository{}, &mocks.MockProductRepository{}, NewUnlimitedInventory(), gateway, newTestPaymentQueue(10), newTestRetryer(), nil)
+
+	done := make(chan error)
+	go func() { done <- uc.ProcessPayment(context.Background(), order) }()
+	<-capturing
+
+	// 売上確定の応答を待っている間にキャンセルし、その保存中に決済結果を返す
+	canceled := make(chan error)
+	go func() {
+		_, err := uc.CancelOrder(context.Background(), order.ID, "")
+		canceled <- err
+	}()
+	<-saving
+	close(releaseCapture)
+	time.Sleep(10 * time.Millisecond) // 決済結果の反映がキャンセルの保存を追い越す時間を与える
+	close(releaseSave)
+
+	if err := <-canceled; err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessPayment() error = %v", err)
+	}
+	if paidWhileSaving {
+		t.Error("キャンセルの保存中に決済完了を保存した")
+	}
+	if stored.Status != entity.OrderStatusCanceled {
+		t.Errorf("Status = %v, want %v", stored.Status, entity.OrderStatusCanceled)
+	}
+	// キャンセル済みの注文に反映しなかった売上は返金する
+	if len(gateway.RefundCalls) != 1 {
+		t.Errorf("Refund 呼び出し回数 = %v, want 1", len(gateway.RefundCalls))
+	}
+}
+
+func TestOrderUseCase_CancelOrder(t *testing.T) {
+	tests := []struct {
+		name             string
+		orderID          string
+		setupOrder       func(order *entity.Order)
+		setupGateway     func(gateway *mocks.MockPaymentGateway)
+		expectedErr      error
+		expectedReleases int
+		expectedRestocks int
+		expectedRefunds  int
+	}{
+		{
+			name:             "決済待ちの注文をキャンセルして引き当てを解放",
+			orderID:          "order-123",
+			setupOrder:       func(order *entity.Order) { order.Reservation = entity.StockReserved },
+			expectedReleases: 1,
+		},
+		{
+			name:    "決済済みの注文をキャンセルして在庫を戻す",
+			orderID: "order-123",
+			setupOrder: func(order *entity.Order) {
+				order.Pay()
+				order.Reservation = entity.StockCommitted
+			},
+			expectedRestocks: 1,
+		},
+		{
+			name:    "売上確定済みの注文は返金してからキャンセル",
+			orderID: "order-123",
+			setupOrder: func(order *entity.Order) {
+				order.Payment.RecordResult(&entity.PaymentResult{TransactionID: "txn-1", Status: entity.PaymentStatusCaptured, Amount: 2000})
+				order.Pay()
+				order.Reservation = entity.StockCommitted
+			},
+			expectedRestocks: 1,
+			expectedRefunds:  1,
+		},
+		{
+			name:    "返金できない場合はキャンセルしない",
+			orderID: "order-123",
+			setupOrder: func(order *entity.Order) {
+				order.Payment.RecordResult(&entity.PaymentResult{TransactionID: "txn-1", Status: entity.PaymentStatusCaptured, Amount: 2000})
+				order.Pay()
+				order.Reservation = entity.StockCommitted
+			},
+			setupGateway: func(gateway *mocks.MockPaymentGateway) {
+				gateway.RefundFunc = func(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+					return nil, entity.ErrPaymentUnavailable
+				}
+			},
+			expectedErr:     entity.ErrPaymentUnavailable,
+			expectedRefunds: 1,
+		},
+		{
+			name:    "出荷済みの注文はキャンセルできない",
+			orderID: "order-123",
+			setupOrder: func(order *entity.Order) {
+				order.Pay()
+				order.Ship()
+				order.Reservation = entity.StockCommitted
+			},
+			expectedErr: entity.ErrInvalidOrderStatus,
+		},
+		{
+			name:        "存在しない注文",
+			orderID:     "nonexistent",
+			setupOrder:  func(order *entity.Order) {},
+			expectedErr: entity.ErrOrderNotFound,
+		},
+		{
+			name:        "空のIDでエラー",
+			orderID:     "",
+			setupOrder:  func(order *entity.Order) {},
+			expectedErr: entity.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 10)
+			product.ID = "product-123"
+			cart := entity.NewCart()
+			cart.AddItem(product, 2)
+			stored, _ := entity.NewOrder(cart)
+			stored.ID = "order-123"
+			tt.setupOrder(stored)
+
+			orderRepo := &mocks.MockOrderRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Order, error) {
+					if id == stored.ID {
+						return stored, nil
+					}
+					return nil, entity.ErrOrderNotFound
+				},
+			}
+			productRepo := &mocks.MockProductRepository{}
+			gateway := &mocks.MockPaymentGateway{}
+			if tt.setupGateway != nil {
+				tt.setupGateway(gateway)
+			}
+			uc := NewOrderUseCase(orderRepo, &mocks.MockCartRepository{}, productRepo, NewTrackedInventory(productRepo), gateway, newTestPaymentQueue(10), newTestRetryer(), nil)
+
+			order, err := uc.CancelOrder(context.Background(), tt.orderID, "")
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("CancelOrder() error = %v, want %v", err, tt.expectedErr)
+				}
+				if len(orderRepo.UpdateCalls) != 0 {
+					t.Errorf("Update が呼ばれるべきではありません")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("予期しないエラー: %v", err)
+				}
+				if order.Status != entity.OrderStatusCanceled {
+					t.Errorf("Status = %v, want %v", order.Status, entity.OrderStatusCanceled)
+				}
+				if order.Reservation != entity.StockReleased {
+					t.Errorf("Reservation = %v, want %v", order.Reservation, entity.StockReleased)
+				}
+				if last := order.StatusHistory[len(order.StatusHistory)-1]; last.Reason != "canceled by customer" {
+					t.Errorf("Reason = %q, want %q", last.Reason, "canceled by customer")
+				}
+				if len(orderRepo.UpdateCalls) != 1 {
+					t.Errorf("Update の呼び出し回数 = %v, want 1", len(orderRepo.UpdateCalls))
+				}
+				if tt.expectedRefunds > 0 && order.Payment.Status != entity.PaymentStatusRefunded {
+					t.Errorf("Payment.Status = %v, want %v", order.Payment.Status, entity.PaymentStatusRefunded)
+				}
+			}
+			if len(gateway.RefundCalls) != tt.expectedRefunds {
+				t.Errorf("Refund 呼び出し回数 = %v, want %v", len(gateway.RefundCalls), tt.expectedRefunds)
+			}
+			if len(productRepo.ReleaseStockCalls) != tt.expectedReleases {
+				t.Errorf("ReleaseStock 呼び出し回数 = %v, want %v", len(productRepo.ReleaseStockCalls), tt.expectedReleases)
+			}
+			if len(productRepo.AdjustStockCalls) != tt.expectedRestocks {
+				t.Errorf("AdjustStock 呼び出し回数 = %v, want %v", len(productRepo.AdjustStockCalls), tt.expectedRestocks)
+			}
+		})
+	}
+}
+
+func TestOrderUseCase_TransitionOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      entity.OrderStatus
+		expectedErr error
+	}{
+		{name: "決済済みの注文を出荷", status: entity.OrderStatusShipped},
+		{name: "決済済みの注文を返金", status: entity.OrderStatusRefunded},
+		{name: "配達前に完了はできない", status: entity.OrderStatusCompleted, expectedErr: entity.ErrInvalidOrderStatus},
+		{name: "未知のステータス", status: entity.OrderStatus("lost"), expectedErr: entity.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := entity.NewCart()
+			cart.AddItem(entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 10), 1)
+			stored, _ := entity.NewOrder(cart)
+			stored.Pay()
+
+			orderRepo := &mocks.MockOrderRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*entity.Order, error) { return stored, nil },
+			}
+			uc := NewOrderUseCase(orderRepo, &mocks.MockCartRepository{}, &mocks.MockProductRepository{}, NewUnlimitedInventory(), &mocks.MockPaymentGateway{}, newTestPaymentQueue(10), newTestRetryer(), nil)
+
+			order, err := uc.TransitionOrder(context.Background(), stored.ID, tt.status, "admin")
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("TransitionOrder() error = %v, want %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr == nil && order.Status != tt.status {
+				t.Errorf("Status = %v, want %v", order.Status, tt.status)
+			}
+		})
+	}
+}
+
+// TestOrderUseCase_CancelOrder_RefundOutsideLock は返金中にロックを保持しないことを確認する
+func TestOrderUseCase_CancelOrder_RefundOutsideLock(t *testing.T) {
+	newOrder := func(id string) *entity.Order {
+		cart := entity.NewCart()
+		cart.AddItem(&entity.Product{ID: "product-123", Price: 1000}, 2)
+		order, _ := entity.NewOrder(cart)
+		order.ID = id
+		order.Payment.RecordResult(&entity.PaymentResult{TransactionID: "txn-" + id, Status: entity.PaymentStatusCaptured, Amount: 2000})
+		order.Pay()
+		return order
+	}
+	orders := map[string]*entity.Order{"order-1": newOrder("order-1"), "order-2": newOrder("order-2")}
+
+	orderRepo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*entity.Order, error) {
+			return orders[id], nil
+		},
+	}
+	refunding := make(chan struct{})
+	release := make(chan struct{})
+	gateway := &mocks.MockPaymentGateway{
+		RefundFunc: func(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error) {
+			if transactionID == "txn-order-1" {
+				close(refunding)
+				<-release
+			}
+			return &entity.PaymentResult{TransactionID: transactionID, Status: entity.PaymentStatusRefunded, Amount: amount}, nil
+		},
+	}
+	uc := NewOrderUseCase(orderRepo, &mocks.MockCartRepository{}, &mocks.MockProductRepository{}, NewUnlimitedInventory(), gateway, newTestPaymentQueue(10), newTestRetryer(), nil)
+
+	done := make(chan error)
+	go func() {
+		_, err := uc.CancelOrder(context.Background(), "order-1", "")
+		done <- err
+	}()
+	<-refunding
+
+	// 返金中の注文の他のステータス変更は受け付けない（二重返金の防止）
+	if _, err := uc.TransitionOrder(context.Background(), "order-1", entity.OrderStatusRefunded, "admin"); !errors.Is(err, entity.ErrInvalidOrderStatus) {
+		t.Errorf("返金中の注文: error = %v, want %v", err, entity.ErrInvalidOrderStatus)
+	}
+	// 別の注文は返金の完了を待たずに処理できる
+	if _, err := uc.CancelOrder(context.Background(), "order-2", ""); err != nil {
+		t.Errorf("別の注文: error = %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+	if orders["order-1"].Status != entity.OrderStatusCanceled || orders["order-1"].Payment.Status != entity.PaymentStatusRefunded {
+		t.Errorf("Status = %v, Payment.Status = %v", orders["order-1"].Status, orders["order-1"].Payment.Status)
+	}
+	if len(gateway.RefundCalls) != 2 {
+		t.Errorf("Refund 呼び出し回数 = %v, want 2", len(gateway.RefundCalls))
+	}
+}
+
+// TestOrderUseCase_ProcessPayment_SaveOutsideLock は注文の保存の再試行中にロックを保持しないことを確認する
+func TestOrderUseCase_ProcessPayment_SaveOutsideLock(t *testing.T) {
+	cart := entity.NewCart()
+	cart.AddItem(&entity.Product{ID: "product-123", Price: 1000}, 2)
+	order, _ := entity.NewOrder(cart)
+
+	orderRepo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*entity.Order, error) {
+			return order, nil
+		},
+	}
+	saving := make(chan struct{})
+	release := make(chan struct{})
+	orderRepo.UpdateFunc = func(ctx context.Context, order *entity.Order) error {
+		// 決済結果の保存（3回目の Update）を失敗させて再試行させる
+		if len(orderRepo.UpdateCalls) == 3 {
+			close(saving)
+			<-release
+			return errors.New("database error")
+		}
+		return nil
+	}
+	uc := NewOrderUseCase(orderRepo, &mocks.MockCartRepository{}, &mocks.MockProductRepository{}, NewUnlimitedInventory(), &mocks.MockPaymentGateway{}, newTestPaymentQueue(10), newTestRetryer(), nil)
+
+	done := make(chan error)
+	go func() { done <- uc.ProcessPayment(context.Background(), order) }()
+	<-saving
+
+	// 保存中の注文の他のステータス変更はロックを待たずに拒否する
+	if _, err := uc.CancelOrder(context.Background(), order.ID, ""); !errors.Is(err, entity.ErrInvalidOrderStatus) {
+		t.Errorf("保存中の注文: error = %v, want %v", err, entity.ErrInvalidOrderStatus)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessPayment() error = %v", err)
+	}
+	if len(orderRepo.UpdateCalls) != 4 {
+		t.Errorf("Update 呼び出し回数 = %v, want 4", len(orderRepo.UpdateCalls))
+	}
+
+	// 保存が終わった注文はステータスを変更できる
+	if _, err := uc.TransitionOrder(context.Background(), order.ID, entity.OrderStatusShipped, "admin"); err != nil {
+		t.Errorf("保存後の注文: error = %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+)
+
+// PaymentGateway は外部の決済サービス（PAYMENT_PROVIDER で fake / http を切り替える）
+// 与信の拒否は Status が declined の結果として返し、エラーは通信障害などゲートウェイ側の失敗に限る
+type PaymentGateway interface {
+	// Authorize は注文金額の与信を取得する
+	Authorize(ctx context.Context, req entity.PaymentRequest) (*entity.PaymentResult, error)
+	// Capture は与信済みの決済の売上を確定する
+	Capture(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error)
+	// Refund は売上確定済みの決済を返金する
+	Refund(ctx context.Context, transactionID string, amount int) (*entity.PaymentResult, error)
+	// Void は売上確定前の与信を取り消す
+	Void(ctx context.Context, transactionID string) (*entity.PaymentResult, error)
+	// Get は決済の現在の状態を返す（応答がなく結果が不明になった売上確定の確認用。見つからない場合は ErrPaymentNotFound）
+	Get(ctx context.Context, transactionID string) (*entity.PaymentResult, error)
+}
@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// PaymentQueueMonitor はキューの滞留数とジョブの実行・再試行を計測する
+type PaymentQueueMonitor interface {
+	// StartJob はジョブの実行を計測するコンテキストと終了時に呼び出す関数を返す（New Relic のバックグラウンドトランザクションなど）
+	StartJob(ctx context.Context, orderID string, wait time.Duration) (context.Context, func())
+
+	// ObserveDepth はキューで待機中のジョブ数・実行中のワーカー数・デッドレターの件数を記録する
+	ObserveDepth(ctx context.Context, depth int, busy int, deadLetters int)
+
+	// ObserveRetry は一時的な障害で失敗したジョブの再試行を記録する
+	ObserveRetry(ctx context.Context, orderID string, attempt int, backoff time.Duration, err error)
+
+	// ObserveDeadLetter は再試行を使い切った（または再試行できない障害で失敗した）ジョブを記録する
+	ObserveDeadLetter(ctx context.Context, orderID string, attempts int, err error)
+}
+
+// PaymentDeadLetter は再試行を使い切った、または再試行できない障害で失敗した決済ジョブ
+// 注文は決済待ち（pending）のまま残り、管理APIから再実行できる
+type PaymentDeadLetter struct {
+	OrderID   string    `json:"orderId"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError"`
+	FailedAt  time.Time `json:"failedAt"`
+}
+
+type paymentJob struct {
+	ctx        context.Context // 注文作成リクエストのトレースを引き継ぐ（キャンセルは引き継がない）
+	order      *entity.Order
+	attempt    int // 実行済みの試行回数
+	enqueuedAt time.Time
+}
+
+// PaymentQueue は決済処理を一定数のワーカーで順に実行するキュー
+// 一時的な障害で失敗したジョブは RetryPolicy に従って再試行し、再試行を使い切ったジョブはデッドレターに移す
+// キューが満杯の場合や停止後は新しいジョブを受け付けない
+type PaymentQueue struct {
+	workers int
+	retry   RetryPolicy
+	random  utils.Random
+	monitor PaymentQueueMonitor
+
+	jobs chan paymentJob
+
+	mutex       sync.Mutex
+	started     bool
+	closed      bool
+	busy        int
+	retries     map[string]*scheduledRetry // 注文ID → 待ち時間の経過後に再試行するジョブ
+	deadLetters []PaymentDeadLetter
+	wg          sync.WaitGroup
+}
+
+type scheduledRetry struct {
+	job   paymentJob
+	timer *time.Timer
+}
+
+// NewPaymentQueue は決済キューを作成する。random はジッターに使い、並行アクセス安全である必要がある（utils.NewSeededRand）
+func NewPaymentQueue(workers int, capacity int, retry RetryPolicy, random utils.Random, monitor PaymentQueueMonitor) *PaymentQueue {
+	return &PaymentQueue{
+		workers: workers,
+		retry:   retry,
+		random:  random,
+		monitor: monitor,
+		jobs:    make(chan paymentJob, capacity),
+		retries: make(map[string]*scheduledRetry),
+	}
+}
+
+// Enqueue は注文の決済をキューに追加する
+// キューが満杯、または停止中の場合は ErrPaymentUnavailable を返す
+func (q *PaymentQueue) Enqueue(ctx context.Context, order *entity.Order) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.push(paymentJob{ctx: context.WithoutCancel(ctx), order: order})
+}
+
+// Requeue はデッドレターの注文の決済を試行回数をリセットしてキューに戻す
+// デッドレターにない注文は ErrOrderNotFound を返す
+func (q *PaymentQueue) Requeue(ctx context.Context, order *entity.Order) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	index := q.deadLetterIndex(order.ID)
+	if index < 0 {
+		return fmt.Errorf("order %s is not in the payment dead-letter list: %w", order.ID, entity.ErrOrderNotFound)
+	}
+	if err := q.push(paymentJob{ctx: context.WithoutCancel(ctx), order: order}); err != nil {
+		return err
+	}
+
+	q.deadLetters = append(q.deadLetters[:index], q.deadLetters[index+1:]...)
+	q.observeDepth(ctx)
+	return nil
+}
+
+// Forget はデッドレターと再試行の待機から注文を取り除く（キャンセルされた注文など、決済が不要になった場合）
+func (q *PaymentQueue) Forget(ctx context.Context, orderID string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if retry, ok := q.retries[orderID]; ok && retry.timer.Stop() {
+		delete(q.retries, orderID)
+	}
+	if index := q.deadLetterIndex(orderID); index >= 0 {
+		q.deadLetters = append(q.deadLetters[:index], q.deadLetters[index+1:]...)
+		q.observeDepth(ctx)
+	}
+}
+
+// DeadLetters はデッドレターの決済ジョブを古い順に返す
+func (q *PaymentQueue) DeadLetters() []PaymentDeadLetter {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return append([]PaymentDeadLetter{}, q.deadLetters...)
+}
+
+// Start はワーカーを起動して process でジョブを実行する（起動済みの場合は何もしない）
+// process が IsRetryable なエラーを返したジョブは再試行し、その他のエラーはデッドレターに移す
+func (q *PaymentQueue) Start(process func(ctx context.Context, order *entity.Order) error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.started || q.closed {
+		return
+	}
+	q.started = true
+
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(process)
+	}
+}
+
+// Drain は新しいジョブの受け付けを停止し、キューに残ったジョブの完了を待つ
+// 再試行の待ち時間中のジョブはデッドレターに移す
+// ctx の期限までに完了しなかった場合は未処理のジョブ数とともにエラーを返す
+func (q *PaymentQueue) Drain(ctx context.Context) error {
+	q.mutex.Lock()
+	if !q.closed {
+		q.closed = true
+		for orderID, retry := range q.retries {
+			if retry.timer.Stop() {
+				q.addDeadLetter(retry.job, fmt.Errorf("payment queue is shutting down: %w", entity.ErrPaymentUnavailable))
+			}
+			delete(q.retries, orderID)
+		}
+		close(q.jobs)
+	}
+	q.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("payment queue drain interrupted with %d pending jobs: %w", q.Depth(), ctx.Err())
+	}
+}
+
+// Depth はキューで待機中のジョブ数を返す
+func (q *PaymentQueue) Depth() int {
+	return len(q.jobs)
+}
+
+// Capacity はキューに待機できるジョブ数の上限を返す
+func (q *PaymentQueue) Capacity() int {
+	return cap(q.jobs)
+}
+
+func (q *PaymentQueue) work(process func(ctx context.Context, order *entity.Order) error) {
+	defer q.wg.Done()
+
+	for job := range q.jobs {
+		job.attempt++
+		if err := q.run(job, process); err != nil {
+			q.fail(job, err)
+		}
+	}
+}
+
+func (q *PaymentQueue) run(job paymentJob, process func(ctx context.Context, order *entity.Order) error) (err error) {
+	ctx := job.ctx
+	q.setBusy(ctx, 1)
+	defer q.setBusy(ctx, -1)
+
+	if q.monitor != nil {
+		var end func()
+		ctx, end = q.monitor.StartJob(ctx, job.order.ID, time.Since(job.enqueuedAt))
+		defer end()
+	}
+
+	// ジョブのパニックでワーカーを停止させない（再試行せずにデッドレターに移す）
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("payment job panicked: %v", recovered)
+		}
+	}()
+
+	return process(ctx, job.order)
+}
+
+// fail は失敗したジョブを再試行するか、デッドレターに移す
+func (q *PaymentQueue) fail(job paymentJob, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed || !IsRetryable(err) || !q.retry.CanRetry(job.attempt) {
+		q.addDeadLetter(job, err)
+		return
+	}
+
+	backoff := q.retry.Backoff(job.attempt, q.random)
+	log.Printf("Payment for order %s failed (attempt %d/%d), retrying in %s: %v", job.order.ID, job.attempt, q.retry.MaxAttempts, backoff, err)
+	if q.monitor != nil {
+		q.monitor.ObserveRetry(job.ctx, job.order.ID, job.attempt, backoff, err)
+	}
+
+	q.retries[job.order.ID] = &scheduledRetry{
+		job: job,
+		timer: time.AfterFunc(backoff, func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+
+			delete(q.retries, job.order.ID)
+			if err := q.push(job); err != nil {
+				q.addDeadLetter(job, err)
+			}
+		}),
+	}
+}
+
+// push は mutex を保持した状態で呼び出す
+func (q *PaymentQueue) push(job paymentJob) error {
+	if q.closed {
+		return fmt.Errorf("payment queue is shutting down: %w", entity.ErrPaymentUnavailable)
+	}
+
+	job.enqueuedAt = time.Now()
+	select {
+	case q.jobs <- job:
+	default:
+		return fmt.Errorf("payment queue is full (%d jobs): %w", cap(q.jobs), entity.ErrPaymentUnavailable)
+	}
+
+	q.observeDepth(job.ctx)
+	return nil
+}
+
+// addDeadLetter は mutex を保持した状態で呼び出す
+func (q *PaymentQueue) addDeadLetter(job paymentJob, err error) {
+	log.Printf("Payment for order %s moved to dead letters after %d attempts: %v", job.order.ID, job.attempt, err)
+
+	if index := q.deadLetterIndex(job.order.ID); index >= 0 {
+		q.deadLetters = append(q.deadLetters[:index], q.deadLetters[index+1:]...)
+	}
+	q.deadLetters = append(q.deadLetters, PaymentDeadLetter{
+		OrderID:   job.order.ID,
+		Attempts:  job.attempt,
+		LastError: err.Error(),
+		FailedAt:  time.Now(),
+	})
+
+	if q.monitor != nil {
+		q.monitor.ObserveDeadLetter(job.ctx, job.order.ID, job.attempt, err)
+	}
+	q.observeDepth(job.ctx)
+}
+
+// deadLetterIndex は mutex を保持した状態で呼び出す
+func (q *PaymentQueue) deadLetterIndex(orderID string) int {
+	for i, letter := range q.deadLetters {
+		if letter.OrderID == orderID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *PaymentQueue) setBusy(ctx context.Context, delta int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.busy += delta
+	q.observeDepth(ctx)
+}
+
+// observeDepth は mutex を保持した状態で呼び出す
+func (q *PaymentQueue) observeDepth(ctx context.Context) {
+	if q.monitor != nil {
+		q.monitor.ObserveDepth(ctx, len(q.jobs), q.busy, len(q.deadLetters))
+	}
+}
@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// newTestPaymentQueue は待ち時間なしで3回まで試行するテスト用のキューを作成する（ワーカーは起動しない）
+func newTestPaymentQueue(capacity int) *PaymentQueue {
+	return NewPaymentQueue(1, capacity, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, utils.NewSeededRand(1), nil)
+}
+
+func newTestRetryer() *Retryer {
+	return NewRetryer(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, utils.NewSeededRand(1))
+}
+
+// recordingQueueMonitor は計測したジョブと滞留数を記録する
+type recordingQueueMonitor struct {
+	mutex       sync.Mutex
+	jobs        []string
+	ended       int
+	maxDepth    int
+	maxBusy     int
+	retries     int
+	deadLetters int
+}
+
+func (m *recordingQueueMonitor) StartJob(ctx context.Context, orderID string, wait time.Duration) (context.Context, func()) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.jobs = append(m.jobs, orderID)
+	return ctx, func() {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		m.ended++
+	}
+}
+
+func (m *recordingQueueMonitor) ObserveRetry(ctx context.Context, orderID string, attempt int, backoff time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.retries++
+}
+
+func (m *recordingQueueMonitor) ObserveDeadLetter(ctx context.Context, orderID string, attempts int, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.deadLetters++
+}
+
+func (m *recordingQueueMonitor) ObserveDepth(ctx context.Context, depth int, busy int, deadLetters int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if depth > m.maxDepth {
+		m.maxDepth = depth
+	}
+	if busy > m.maxBusy {
+		m.maxBusy = busy
+	}
+}
+
+func newQueuedOrder(id string) *entity.Order {
+	return &entity.Order{ID: id, Status: entity.OrderStatusPending}
+}
+
+func TestPaymentQueue_Workers(t *testing.T) {
+	monitor := &recordingQueueMonitor{}
+	queue := NewPaymentQueue(2, 10, RetryPolicy{}, nil, monitor)
+
+	release := make(chan struct{})
+	var (
+		mutex     sync.Mutex
+		running   int
+		processed []string
+	)
+	for _, id := range []string{"order-1", "order-2", "order-3", "order-4", "order-5"} {
+		if err := queue.Enqueue(context.Background(), newQueuedOrder(id)); err != nil {
+			t.Fatalf("Enqueue(%s) エラー: %v", id, err)
+		}
+	}
+	if queue.Depth() != 5 {
+		t.Fatalf("Depth() = %v, want 5", queue.Depth())
+	}
+
+	queue.Start(func(ctx context.Context, order *entity.Order) error {
+		mutex.Lock()
+		running++
+		if running > 2 {
+			t.Errorf("同時実行数 = %v, want <= 2", running)
+		}
+		mutex.Unlock()
+
+		<-release
+
+		mutex.Lock()
+		running--
+		processed = append(processed, order.ID)
+		mutex.Unlock()
+		return nil
+	})
+	close(release)
+
+	// 停止前に追加されたジョブは全て実行してから終了する
+	if err := queue.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() エラー: %v", err)
+	}
+	if len(processed) != 5 {
+		t.Errorf("実行したジョブ数 = %v, want 5", len(processed))
+	}
+	if len(monitor.jobs) != 5 || monitor.ended != 5 {
+		t.Errorf("計測したジョブ数 = %v（終了 %v）, want 5", len(monitor.jobs), monitor.ended)
+	}
+	if monitor.maxDepth != 5 || monitor.maxBusy > 2 {
+		t.Errorf("最大滞留数 = %v, 最大実行数 = %v", monitor.maxDepth, monitor.maxBusy)
+	}
+}
+
+func TestPaymentQueue_Enqueue(t *testing.T) {
+	tests := []struct {
+		name        string
+		capacity    int
+		queued      int
+		drained     bool
+		expectedErr error
+	}{
+		{name: "空きがあれば追加できる", capacity: 2, queued: 1},
+		{name: "満杯の場合は受け付けない", capacity: 2, queued: 2, expectedErr: entity.ErrPaymentUnavailable},
+		{name: "停止後は受け付けない", capacity: 2, drained: true, expectedErr: entity.ErrPaymentUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := NewPaymentQueue(1, tt.capacity, RetryPolicy{}, nil, nil)
+			for i := 0; i < tt.queued; i++ {
+				if err := queue.Enqueue(context.Background(), newQueuedOrder("order-queued")); err != nil {
+					t.Fatalf("Enqueue() エラー: %v", err)
+				}
+			}
+			if tt.drained {
+				if err := queue.Drain(context.Background()); err != nil {
+					t.Fatalf("Drain() エラー: %v", err)
+				}
+			}
+
+			err := queue.Enqueue(context.Background(), newQueuedOrder("order-new"))
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Enqueue() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestPaymentQueue_DrainTimeout(t *testing.T) {
+	queue := NewPaymentQueue(1, 10, RetryPolicy{}, nil, nil)
+	release := make(chan struct{})
+	defer close(release)
+	queue.Start(func(ctx context.Context, order *entity.Order) error {
+		<-release
+		return nil
+	})
+
+	queue.Enqueue(context.Background(), newQueuedOrder("order-1"))
+	queue.Enqueue(context.Background(), newQueuedOrder("order-2"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// 期限までに終わらないジョブは待たずに終了する
+	if err := queue.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestPaymentQueue_Retry(t *testing.T) {
+	tests := []struct {
+		name                string
+		errs                []error // 試行ごとのエラー（足りない分は成功）
+		expectedCalls       int
+		expectedRetries     int
+		expectedDeadLetters int
+	}{
+		{
+			name:          "成功したジョブは再試行しない",
+			expectedCalls: 1,
+		},
+		{
+			name:            "一時的な障害は再試行して成功",
+			errs:            []error{entity.ErrPaymentUnavailable, entity.ErrPaymentUnavailable},
+			expectedCalls:   3,
+			expectedRetries: 2,
+		},
+		{
+			name:                "再試行を使い切ったジョブはデッドレターに移す",
+			errs:                []error{entity.ErrPaymentUnavailable, entity.ErrPaymentUnavailable, entity.ErrPaymentUnavailable},
+			expectedCalls:       3,
+			expectedRetries:     2,
+			expectedDeadLetters: 1,
+		},
+		{
+			name:                "再試行できないエラーはすぐにデッドレターに移す",
+			errs:                []error{errors.New("failed to update order")},
+			expectedCalls:       1,
+			expectedDeadLetters: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := &recordingQueueMonitor{}
+			queue := NewPaymentQueue(1, 10, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, utils.NewSeededRand(1), monitor)
+
+			done := make(chan struct{})
+			calls := 0
+			queue.Start(func(ctx context.Context, order *entity.Order) error {
+				calls++
+				var err error
+				if calls <= len(tt.errs) {
+					err = tt.errs[calls-1]
+				}
+				if calls == tt.expectedCalls {
+					defer close(done)
+				}
+				return err
+			})
+			queue.Enqueue(context.Background(), newQueuedOrder("order-1"))
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("ジョブが実行されない")
+			}
+			if err := queue.Drain(context.Background()); err != nil {
+				t.Fatalf("Drain() エラー: %v", err)
+			}
+
+			if calls != tt.expectedCalls {
+				t.Errorf("実行回数 = %v, want %v", calls, tt.expectedCalls)
+			}
+			if monitor.retries != tt.expectedRetries {
+				t.Errorf("再試行回数 = %v, want %v", monitor.retries, tt.expectedRetries)
+			}
+			deadLetters := queue.DeadLetters()
+			if len(deadLetters) != tt.expectedDeadLetters || monitor.deadLetters != tt.expectedDeadLetters {
+				t.Fatalf("デッドレター = %+v, want %v 件", deadLetters, tt.expectedDeadLetters)
+			}
+			if tt.expectedDeadLetters > 0 && (deadLetters[0].OrderID != "order-1" || deadLetters[0].Attempts != tt.expectedCalls) {
+				t.Errorf("デッドレター = %+v", deadLetters[0])
+			}
+		})
+	}
+}
+
+func TestPaymentQueue_Requeue(t *testing.T) {
+	queue := NewPaymentQueue(1, 10, RetryPolicy{MaxAttempts: 1}, nil, nil)
+	order := newQueuedOrder("order-1")
+
+	// 初回は障害で失敗し、再実行では成功する
+	processed := make(chan error, 2)
+	calls := 0
+	queue.Start(func(ctx context.Context, order *entity.Order) error {
+		calls++
+		var err error
+		if calls == 1 {
+			err = entity.ErrPaymentUnavailable
+		}
+		processed <- err
+		return err
+	})
+
+	// デッドレターにない注文は再実行できない
+	if err := queue.Requeue(context.Background(), order); !errors.Is(err, entity.ErrOrderNotFound) {
+		t.Fatalf("Requeue() error = %v, want %v", err, entity.ErrOrderNotFound)
+	}
+
+	queue.Enqueue(context.Background(), order)
+	<-processed
+	waitFor(t, func() bool { return len(queue.DeadLetters()) == 1 })
+
+	if err := queue.Requeue(context.Background(), order); err != nil {
+		t.Fatalf("Requeue() エラー: %v", err)
+	}
+	if err := <-processed; err != nil {
+		t.Errorf("再実行の結果 = %v, want nil", err)
+	}
+	if err := queue.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() エラー: %v", err)
+	}
+	if deadLetters := queue.DeadLetters(); len(deadLetters) != 0 {
+		t.Errorf("デッドレター = %+v, want 0 件", deadLetters)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	tests := []struct {
+		name     string
+		attempt  int
+		expected time.Duration
+	}{
+		{name: "1回目の失敗", attempt: 1, expected: time.Second},
+		{name: "2回目の失敗は2倍", attempt: 2, expected: 2 * time.Second},
+		{name: "3回目の失敗は4倍", attempt: 3, expected: 4 * time.Second},
+		{name: "上限を超えない", attempt: 4, expected: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if backoff := policy.Backoff(tt.attempt, nil); backoff != tt.expected {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, backoff, tt.expected)
+			}
+
+			// ジッターは ±Jitter の範囲でばらつかせる
+			jittered := policy
+			jittered.Jitter = 0.2
+			backoff := jittered.Backoff(tt.attempt, utils.NewSeededRand(int64(tt.attempt)))
+			if backoff < time.Duration(float64(tt.expected)*0.8) || backoff > time.Duration(float64(tt.expected)*1.2) {
+				t.Errorf("Backoff(%d) with jitter = %v, want %v ±20%%", tt.attempt, backoff, tt.expected)
+			}
+		})
+	}
+
+	if policy.CanRetry(5) {
+		t.Error("CanRetry(5) = true, want false")
+	}
+}
+
+// waitFor は条件が満たされるまで待つ（1秒でタイムアウト）
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("条件が満たされない")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -3,10 +3,6 @@ package usecase
 import (
 	"context"
 	"fmt"
-	"math/rand"
-	"os"
-	"strconv"
-	"time"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
@@ -23,13 +19,8 @@ func NewProductUseCase(productRepo repository.ProductRepository) *ProductUseCase
 }
 
 func (uc *ProductUseCase) GetAllProducts(ctx context.Context) ([]*entity.Product, error) {
-	// SLMデモ用のレスポンス時間調整
-	uc.simulateResponseTime()
-
-	// SLMデモ用のランダムエラー生成
-	if uc.shouldSimulateError() {
-		return nil, fmt.Errorf("simulated product service error")
-	}
+	ctx, span := startSpan(ctx, "ProductUseCase.GetAllProducts")
+	defer span.End()
 
 	products, err := uc.productRepo.GetAll(ctx)
 	if err != nil {
@@ -40,13 +31,8 @@ func (uc *ProductUseCase) GetAllProducts(ctx context.Context) ([]*entity.Product
 }
 
 func (uc *ProductUseCase) GetProductByID(ctx context.Context, id string) (*entity.Product, error) {
-	// SLMデモ用のレスポンス時間調整
-	uc.simulateResponseTime()
-
-	// SLMデモ用のランダムエラー生成
-	if uc.shouldSimulateError() {
-		return nil, fmt.Errorf("simulated product service error")
-	}
+	ctx, span := startSpan(ctx, "ProductUseCase.GetProductByID")
+	defer span.End()
 
 	if id == "" {
 		return nil, entity.ErrInvalidInput
@@ -61,6 +47,9 @@ func (uc *ProductUseCase) GetProductByID(ctx context.Context, id string) (*entit
 }
 
 func (uc *ProductUseCase) CreateProduct(ctx context.Context, name, description string, price int, imageURL string, stock int) (*entity.Product, error) {
+	ctx, span := startSpan(ctx, "ProductUseCase.CreateProduct")
+	defer span.End()
+
 	// バリデーション
 	if name == "" || description == "" || price <= 0 || stock < 0 {
 		return nil, entity.ErrInvalidInput
@@ -76,6 +65,9 @@ func (uc *ProductUseCase) CreateProduct(ctx context.Context, name, description s
 }
 
 func (uc *ProductUseCase) UpdateProduct(ctx context.Context, product *entity.Product) error {
+	ctx, span := startSpan(ctx, "ProductUseCase.UpdateProduct")
+	defer span.End()
+
 	// バリデーション
 	if product == nil || product.ID == "" {
 		return entity.ErrInvalidInput
@@ -89,6 +81,9 @@ func (uc *ProductUseCase) UpdateProduct(ctx context.Context, product *entity.Pro
 }
 
 func (uc *ProductUseCase) DeleteProduct(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "ProductUseCase.DeleteProduct")
+	defer span.End()
+
 	if id == "" {
 		return entity.ErrInvalidInput
 	}
@@ -100,54 +95,61 @@ func (uc *ProductUseCase) DeleteProduct(ctx context.Context, id string) error {
 	return nil
 }
 
-// SLMデモ用のレスポンス時間シミュレーション
-func (uc *ProductUseCase) simulateResponseTime() {
-	minTime := uc.getEnvInt("RESPONSE_TIME_MIN", 50)
-	maxTime := uc.getEnvInt("RESPONSE_TIME_MAX", 500)
-
-	// 一定確率で遅いレスポンスを生成
-	slowEndpointRate := uc.getEnvFloat("SLOW_ENDPOINT_RATE", 0.2)
-	if rand.Float64() < slowEndpointRate {
-		// 遅いエンドポイントの場合は最大時間の2-3倍にする
-		maxTime = maxTime * (2 + rand.Intn(2))
-	}
+// UpdateStock は商品の在庫数・在庫僅少の閾値をまとめて設定する（在庫切れの障害シナリオ用。閾値は 0 で既定の閾値に戻す）
+// どちらかを適用できない場合はどちらも変更しない
+func (uc *ProductUseCase) UpdateStock(ctx context.Context, id string, settings entity.StockSettings) (*entity.Product, error) {
+	ctx, span := startSpan(ctx, "ProductUseCase.UpdateStock")
+	defer span.End()
 
-	if maxTime > minTime {
-		responseTime := minTime + rand.Intn(maxTime-minTime)
-		time.Sleep(time.Duration(responseTime) * time.Millisecond)
+	if id == "" || !settings.IsValid() {
+		return nil, entity.ErrInvalidInput
 	}
-}
-
-// SLMデモ用のランダムエラー生成
-func (uc *ProductUseCase) shouldSimulateError() bool {
-	errorRate := uc.getEnvFloat("ERROR_RATE", 0.1)
-	return rand.Float64() < errorRate
-}
 
-func (uc *ProductUseCase) getEnvInt(key string, defaultValue int) int {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+	if err := uc.productRepo.UpdateStockSettings(ctx, id, settings); err != nil {
+		return nil, fmt.Errorf("failed to update stock: %w", err)
 	}
 
-	intValue, err := strconv.Atoi(value)
+	product, err := uc.productRepo.GetByID(ctx, id)
 	if err != nil {
-		return defaultValue
+		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 
-	return intValue
+	return product, nil
 }
 
-func (uc *ProductUseCase) getEnvFloat(key string, defaultValue float64) float64 {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// AdjustStock は複数商品の在庫数をまとめて増減する（1件でも適用できない場合は何も変更しない）
+func (uc *ProductUseCase) AdjustStock(ctx context.Context, changes []entity.StockChange) ([]*entity.Product, error) {
+	ctx, span := startSpan(ctx, "ProductUseCase.AdjustStock")
+	defer span.End()
+
+	if len(changes) == 0 {
+		return nil, entity.ErrInvalidInput
+	}
+	for _, change := range changes {
+		if change.ProductID == "" {
+			return nil, entity.ErrInvalidInput
+		}
+	}
+
+	if err := uc.productRepo.AdjustStock(ctx, changes); err != nil {
+		return nil, fmt.Errorf("failed to adjust stock: %w", err)
 	}
 
-	floatValue, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		return defaultValue
+	// 変更後の商品を返す（同じ商品は1件にまとめる）
+	products := make([]*entity.Product, 0, len(changes))
+	seen := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		if seen[change.ProductID] {
+			continue
+		}
+		seen[change.ProductID] = true
+
+		product, err := uc.productRepo.GetByID(ctx, change.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product: %w", err)
+		}
+		products = append(products, product)
 	}
 
-	return floatValue
+	return products, nil
 }
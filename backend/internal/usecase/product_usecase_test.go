@@ -3,7 +3,6 @@ package usecase
 import (
 	"context"
 	"errors"
-	"os"
 	"testing"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
@@ -11,16 +10,6 @@ import (
 )
 
 func TestProductUseCase_GetAllProducts(t *testing.T) {
-	// テスト用に環境変数を設定（エラーとレスポンス時間のシミュレーションを無効化）
-	os.Setenv("ERROR_RATE", "0")
-	os.Setenv("RESPONSE_TIME_MIN", "0")
-	os.Setenv("RESPONSE_TIME_MAX", "0")
-	defer func() {
-		os.Unsetenv("ERROR_RATE")
-		os.Unsetenv("RESPONSE_TIME_MIN")
-		os.Unsetenv("RESPONSE_TIME_MAX")
-	}()
-
 	tests := []struct {
 		name          string
 		setupMock     func() *mocks.MockProductRepository
@@ -98,16 +87,6 @@ func TestProductUseCase_GetAllProducts(t *testing.T) {
 }
 
 func TestProductUseCase_GetProductByID(t *testing.T) {
-	// エラーシミュレーションを無効化
-	os.Setenv("ERROR_RATE", "0")
-	os.Setenv("RESPONSE_TIME_MIN", "0")
-	os.Setenv("RESPONSE_TIME_MAX", "0")
-	defer func() {
-		os.Unsetenv("ERROR_RATE")
-		os.Unsetenv("RESPONSE_TIME_MIN")
-		os.Unsetenv("RESPONSE_TIME_MAX")
-	}()
-
 	tests := []struct {
 		name        string
 		productID   string
@@ -482,3 +461,163 @@ func TestProductUseCase_DeleteProduct(t *testing.T) {
 		})
 	}
 }
+
+func TestProductUseCase_UpdateStock(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name          string
+		productID     string
+		settings      entity.StockSettings
+		updateErr     error
+		expectedErr   error
+		expectedCalls int
+	}{
+		{
+			name:          "在庫数を設定",
+			productID:     "product-123",
+			settings:      entity.StockSettings{Stock: intPtr(0)},
+			expectedCalls: 1,
+		},
+		{
+			name:          "閾値を設定",
+			productID:     "product-123",
+			settings:      entity.StockSettings{LowStockThreshold: intPtr(20)},
+			expectedCalls: 1,
+		},
+		{
+			name:          "在庫数と閾値をまとめて設定",
+			productID:     "product-123",
+			settings:      entity.StockSettings{Stock: intPtr(3), LowStockThreshold: intPtr(0)},
+			expectedCalls: 1,
+		},
+		{
+			name:        "負の在庫数はエラー",
+			productID:   "product-123",
+			settings:    entity.StockSettings{Stock: intPtr(-1), LowStockThreshold: intPtr(5)},
+			expectedErr: entity.ErrInvalidInput,
+		},
+		{
+			name:        "負の閾値はエラー（在庫数も変更しない）",
+			productID:   "product-123",
+			settings:    entity.StockSettings{Stock: intPtr(5), LowStockThreshold: intPtr(-1)},
+			expectedErr: entity.ErrInvalidInput,
+		},
+		{
+			name:        "どちらも指定しない場合はエラー",
+			productID:   "product-123",
+			expectedErr: entity.ErrInvalidInput,
+		},
+		{
+			name:        "空のIDでエラー",
+			productID:   "",
+			settings:    entity.StockSettings{Stock: intPtr(5)},
+			expectedErr: entity.ErrInvalidInput,
+		},
+		{
+			name:          "存在しない商品",
+			productID:     "nonexistent",
+			settings:      entity.StockSettings{Stock: intPtr(5)},
+			updateErr:     entity.ErrProductNotFound,
+			expectedErr:   entity.ErrProductNotFound,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 10)
+			stored.LowStockThreshold = 5
+			mockRepo := &mocks.MockProductRepository{}
+			mockRepo.UpdateStockSettingsFunc = func(ctx context.Context, id string, settings entity.StockSettings) error {
+				if tt.updateErr != nil {
+					return tt.updateErr
+				}
+				stored.ApplyStockSettings(settings)
+				return nil
+			}
+			mockRepo.GetByIDFunc = func(ctx context.Context, id string) (*entity.Product, error) {
+				return stored.Clone(), nil
+			}
+			uc := NewProductUseCase(mockRepo)
+
+			product, err := uc.UpdateStock(context.Background(), tt.productID, tt.settings)
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("UpdateStock() error = %v, want %v", err, tt.expectedErr)
+			}
+			if len(mockRepo.UpdateStockSettingsCalls) != tt.expectedCalls {
+				t.Errorf("UpdateStockSettingsの呼び出し回数 = %v, want %v", len(mockRepo.UpdateStockSettingsCalls), tt.expectedCalls)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+			if tt.settings.Stock != nil && product.Stock != *tt.settings.Stock {
+				t.Errorf("Stock = %v, want %v", product.Stock, *tt.settings.Stock)
+			}
+			if tt.settings.LowStockThreshold != nil && product.LowStockThreshold != *tt.settings.LowStockThreshold {
+				t.Errorf("LowStockThreshold = %v, want %v", product.LowStockThreshold, *tt.settings.LowStockThreshold)
+			}
+		})
+	}
+}
+
+func TestProductUseCase_AdjustStock(t *testing.T) {
+	tests := []struct {
+		name             string
+		changes          []entity.StockChange
+		adjustErr        error
+		expectedErr      error
+		expectedProducts int
+	}{
+		{
+			name:             "複数商品の在庫を増減",
+			changes:          []entity.StockChange{{ProductID: "product-1", Delta: 5}, {ProductID: "product-2", Delta: -1}, {ProductID: "product-1", Delta: 1}},
+			expectedProducts: 2,
+		},
+		{
+			name:        "空の変更はエラー",
+			changes:     nil,
+			expectedErr: entity.ErrInvalidInput,
+		},
+		{
+			name:        "商品IDが空の場合はエラー",
+			changes:     []entity.StockChange{{ProductID: "", Delta: 1}},
+			expectedErr: entity.ErrInvalidInput,
+		},
+		{
+			name:        "在庫不足",
+			changes:     []entity.StockChange{{ProductID: "product-1", Delta: -100}},
+			adjustErr:   entity.ErrInsufficientStock,
+			expectedErr: entity.ErrInsufficientStock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mocks.MockProductRepository{}
+			mockRepo.AdjustStockFunc = func(ctx context.Context, changes []entity.StockChange) error {
+				return tt.adjustErr
+			}
+			mockRepo.GetByIDFunc = func(ctx context.Context, id string) (*entity.Product, error) {
+				product := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 10)
+				product.ID = id
+				return product, nil
+			}
+			uc := NewProductUseCase(mockRepo)
+
+			products, err := uc.AdjustStock(context.Background(), tt.changes)
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("AdjustStock() error = %v, want %v", err, tt.expectedErr)
+			}
+			if len(products) != tt.expectedProducts {
+				t.Errorf("商品数 = %v, want %v", len(products), tt.expectedProducts)
+			}
+			// 入力が不正な場合はリポジトリを呼ばない
+			if errors.Is(tt.expectedErr, entity.ErrInvalidInput) && len(mockRepo.AdjustStockCalls) != 0 {
+				t.Error("AdjustStockが呼ばれるべきではありません")
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// RetryPolicy は一時的な障害の再試行の回数と間隔（指数バックオフとジッター）
+// ゼロ値は再試行しない
+type RetryPolicy struct {
+	MaxAttempts    int           // 初回を含む最大試行回数
+	InitialBackoff time.Duration // 1回目の再試行までの待ち時間（以降は2倍ずつ増やす）
+	MaxBackoff     time.Duration // 待ち時間の上限
+	Jitter         float64       // 待ち時間のばらつき（0.2 で ±20%）
+}
+
+// CanRetry は attempt 回目の試行が失敗した後に再試行できるか判定する
+func (p RetryPolicy) CanRetry(attempt int) bool {
+	return attempt < p.MaxAttempts
+}
+
+// Backoff は attempt 回目の試行が失敗してから次の試行までの待ち時間を返す
+func (p RetryPolicy) Backoff(attempt int, random utils.Random) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	// 同時に失敗したジョブの再試行が重ならないようにばらつかせる
+	if p.Jitter > 0 && random != nil {
+		backoff = time.Duration(float64(backoff) * utils.Uniform(random, 1-p.Jitter, 1+p.Jitter))
+	}
+	return backoff
+}
+
+// Do は fn が成功するまで最大 MaxAttempts 回実行する（ctx がキャンセルされた場合は最後のエラーを返す）
+func (p RetryPolicy) Do(ctx context.Context, random utils.Random, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !p.CanRetry(attempt) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(p.Backoff(attempt, random)):
+		}
+	}
+}
+
+// Retryer は RetryPolicy とジッターの乱数をまとめて、処理をその場で再試行する
+type Retryer struct {
+	policy RetryPolicy
+	random utils.Random
+}
+
+// NewRetryer は Retryer を作成する。random はジッターに使い、並行アクセス安全である必要がある（utils.NewSeededRand）
+func NewRetryer(policy RetryPolicy, random utils.Random) *Retryer {
+	return &Retryer{policy: policy, random: random}
+}
+
+// Do は fn が成功するまで RetryPolicy に従って再試行する
+func (r *Retryer) Do(ctx context.Context, fn func() error) error {
+	return r.policy.Do(ctx, r.random, fn)
+}
+
+// IsRetryable は再試行で解消する可能性のある一時的な障害（決済ゲートウェイの障害・タイムアウト）か判定する
+// 与信の拒否や不正なリクエストなど、再試行しても結果が変わらない失敗は再試行しない
+func IsRetryable(err error) bool {
+	return errors.Is(err, entity.ErrPaymentUnavailable)
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/repository"
+)
+
+// StockEventSink は在庫イベントの通知先
+type StockEventSink interface {
+	Notify(ctx context.Context, event entity.StockEvent) error
+}
+
+// StockEventSinkFunc は関数を StockEventSink として使うためのアダプター
+type StockEventSinkFunc func(ctx context.Context, event entity.StockEvent) error
+
+func (f StockEventSinkFunc) Notify(ctx context.Context, event entity.StockEvent) error {
+	return f(ctx, event)
+}
+
+// StockNotifier は ProductRepository の在庫変更（引き当て・引き当ての取り消しを含む）を監視し、
+// 販売可能な在庫数が閾値をまたいだときに在庫イベントを通知する
+// 閾値は商品ごとの LowStockThreshold（未設定の場合は defaultThreshold）。閾値の変更だけでは通知しない
+type StockNotifier struct {
+	repository.ProductRepository
+
+	defaultThreshold int
+	sinks            []StockEventSink
+	now              func() time.Time
+
+	// 在庫の変更を直列化し、変更前後の在庫数を正確に比較する
+	mutex sync.Mutex
+	// 通知の送信中のもの（テストで完了を待つ）
+	pending sync.WaitGroup
+}
+
+type stockSnapshot struct {
+	stock     int
+	threshold int
+}
+
+func NewStockNotifier(productRepo repository.ProductRepository, defaultThreshold int, sinks ...StockEventSink) *StockNotifier {
+	return &StockNotifier{
+		ProductRepository: productRepo,
+		defaultThreshold:  defaultThreshold,
+		sinks:             sinks,
+		now:               time.Now,
+	}
+}
+
+func (n *StockNotifier) UpdateStock(ctx context.Context, id string, newStock int) error {
+	return n.watch(ctx, []string{id}, func() error {
+		return n.ProductRepository.UpdateStock(ctx, id, newStock)
+	})
+}
+
+// UpdateStockSettings は在庫数を変更する場合のみ通知する（閾値の変更だけでは通知しない）
+func (n *StockNotifier) UpdateStockSettings(ctx context.Context, id string, settings entity.StockSettings) error {
+	if settings.Stock == nil {
+		return n.ProductRepository.UpdateStockSettings(ctx, id, settings)
+	}
+	return n.watch(ctx, []string{id}, func() error {
+		return n.ProductRepository.UpdateStockSettings(ctx, id, settings)
+	})
+}
+
+func (n *StockNotifier) DecreaseStock(ctx context.Context, id string, quantity int) error {
+	return n.watch(ctx, []string{id}, func() error {
+		return n.ProductRepository.DecreaseStock(ctx, id, quantity)
+	})
+}
+
+func (n *StockNotifier) IncreaseStock(ctx context.Context, id string, quantity int) error {
+	return n.watch(ctx, []string{id}, func() error {
+		return n.ProductRepository.IncreaseStock(ctx, id, quantity)
+	})
+}
+
+func (n *StockNotifier) ReserveStock(ctx context.Context, items []entity.StockQuantity) error {
+	return n.watch(ctx, stockQuantityIDs(items), func() error {
+		return n.ProductRepository.ReserveStock(ctx, items)
+	})
+}
+
+func (n *StockNotifier) CommitStock(ctx context.Context, items []entity.StockQuantity) error {
+	return n.watch(ctx, stockQuantityIDs(items), func() error {
+		return n.ProductRepository.CommitStock(ctx, items)
+	})
+}
+
+func (n *StockNotifier) ReleaseStock(ctx context.Context, items []entity.StockQuantity) error {
+	return n.watch(ctx, stockQuantityIDs(items), func() error {
+		return n.ProductRepository.ReleaseStock(ctx, items)
+	})
+}
+
+func (n *StockNotifier) AdjustStock(ctx context.Context, changes []entity.StockChange) error {
+	ids := make([]string, len(changes))
+	for i, change := range changes {
+		ids[i] = change.ProductID
+	}
+	return n.watch(ctx, ids, func() error {
+		return n.ProductRepository.AdjustStock(ctx, changes)
+	})
+}
+
+// Wait は送信中の通知の完了を待つ
+func (n *StockNotifier) Wait() {
+	n.pending.Wait()
+}
+
+// watch は在庫の変更前後の販売可能な在庫数を比較し、閾値をまたいだ商品のイベントを通知する
+func (n *StockNotifier) watch(ctx context.Context, ids []string, change func() error) error {
+	n.mutex.Lock()
+
+	ids = uniqueIDs(ids)
+	before := make(map[string]stockSnapshot, len(ids))
+	for _, id := range ids {
+		if product, err := n.ProductRepository.GetByID(ctx, id); err == nil {
+			before[id] = stockSnapshot{stock: product.AvailableStock(), threshold: n.threshold(product)}
+		}
+	}
+
+	if err := change(); err != nil {
+		n.mutex.Unlock()
+		return err
+	}
+
+	events := []entity.StockEvent{}
+	for _, id := range ids {
+		previous, exists := before[id]
+		if !exists {
+			continue
+		}
+		product, err := n.ProductRepository.GetByID(ctx, id)
+		if err != nil {
+			continue
+		}
+		if event, crossed := entity.NewStockEvent(product, previous.stock, previous.threshold, n.threshold(product), n.now()); crossed {
+			events = append(events, event)
+		}
+	}
+	n.mutex.Unlock()
+
+	if len(events) > 0 {
+		// Webhook の応答を待ってリクエストが遅くならないよう、通知はバックグラウンドで送る
+		n.pending.Add(1)
+		go n.notify(context.WithoutCancel(ctx), events)
+	}
+	return nil
+}
+
+func (n *StockNotifier) notify(ctx context.Context, events []entity.StockEvent) {
+	defer n.pending.Done()
+
+	for _, event := range events {
+		for _, sink := range n.sinks {
+			if err := sink.Notify(ctx, event); err != nil {
+				log.Printf("Failed to notify stock event %s for product %s: %v", event.Type, event.ProductID, err)
+			}
+		}
+	}
+}
+
+func (n *StockNotifier) threshold(product *entity.Product) int {
+	if product.LowStockThreshold > 0 {
+		return product.LowStockThreshold
+	}
+	return n.defaultThreshold
+}
+
+func stockQuantityIDs(items []entity.StockQuantity) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	return ids
+}
+
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/NRUG-SRE/slm-handson/backend/internal/domain/entity"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase/mocks"
+)
+
+// stockRepository は在庫数を保持するモック（StockNotifier の変更前後の比較用）
+func stockRepository(products ...*entity.Product) *mocks.MockProductRepository {
+	byID := map[string]*entity.Product{}
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	return &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*entity.Product, error) {
+			if product, exists := byID[id]; exists {
+				return product, nil
+			}
+			return nil, entity.ErrProductNotFound
+		},
+		UpdateStockFunc: func(ctx context.Context, id string, newStock int) error {
+			byID[id].UpdateStock(newStock)
+			return nil
+		},
+		DecreaseStockFunc: func(ctx context.Context, id string, quantity int) error {
+			return byID[id].DecreaseStock(quantity)
+		},
+		IncreaseStockFunc: func(ctx context.Context, id string, quantity int) error {
+			byID[id].IncreaseStock(quantity)
+			return nil
+		},
+		ReserveStockFunc: func(ctx context.Context, items []entity.StockQuantity) error {
+			for _, item := range items {
+				if err := byID[item.ProductID].Reserve(item.Quantity); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		ReleaseStockFunc: func(ctx context.Context, items []entity.StockQuantity) error {
+			for _, item := range items {
+				byID[item.ProductID].ReleaseReservation(item.Quantity)
+			}
+			return nil
+		},
+		AdjustStockFunc: func(ctx context.Context, changes []entity.StockChange) error {
+			for _, change := range changes {
+				byID[change.ProductID].AdjustStock(change.Delta)
+			}
+			return nil
+		},
+	}
+}
+
+func TestStockNotifier(t *testing.T) {
+	product := entity.NewProduct("テスト商品", "説明", 1000, "image.jpg", 12)
+	product.ID = "product-1"
+	custom := entity.NewProduct("閾値設定済みの商品", "説明", 1000, "image.jpg", 60)
+	custom.ID = "product-2"
+	custom.LowStockThreshold = 50
+
+	var (
+		mutex  sync.Mutex
+		events []entity.StockEvent
+	)
+	sink := StockEventSinkFunc(func(ctx context.Context, event entity.StockEvent) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, event)
+		return nil
+	})
+	failing := StockEventSinkFunc(func(ctx context.Context, event entity.StockEvent) error {
+		return errors.New("webhook unavailable")
+	})
+	notifier := NewStockNotifier(stockRepository(product, custom), 10, failing, sink)
+	ctx := context.Background()
+
+	steps := []struct {
+		name     string
+		change   func() error
+		expected []string
+	}{
+		{name: "閾値をまたがない", change: func() error { return notifier.DecreaseStock(ctx, product.ID, 1) }},
+		{name: "閾値以下になる", change: func() error { return notifier.DecreaseStock(ctx, product.ID, 1) }, expected: []string{entity.StockEventLowStock}},
+		{name: "在庫切れ", change: func() error { return notifier.UpdateStock(ctx, product.ID, 0) }, expected: []string{entity.StockEventOutOfStock}},
+		{name: "在庫不足のエラーは通知しない", change: func() error { return notifier.DecreaseStock(ctx, product.ID, 1) }},
+		{name: "入荷", change: func() error { return notifier.IncreaseStock(ctx, product.ID, 30) }, expected: []string{entity.StockEventRestocked}},
+		{name: "引き当てで販売可能な在庫が閾値以下になる", change: func() error {
+			return notifier.ReserveStock(ctx, []entity.StockQuantity{{ProductID: product.ID, Quantity: 25}})
+		}, expected: []string{entity.StockEventLowStock}},
+		{name: "引き当ての取り消しで回復", change: func() error {
+			return notifier.ReleaseStock(ctx, []entity.StockQuantity{{ProductID: product.ID, Quantity: 25}})
+		}, expected: []string{entity.StockEventRestocked}},
+		{
+			name: "複数商品の変更と商品ごとの閾値",
+			change: func() error {
+				return notifier.AdjustStock(ctx, []entity.StockChange{{ProductID: product.ID, Delta: -25}, {ProductID: custom.ID, Delta: -10}})
+			},
+			expected: []string{entity.StockEventLowStock, entity.StockEventLowStock},
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			mutex.Lock()
+			events = nil
+			mutex.Unlock()
+
+			step.change()
+			notifier.Wait()
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if len(events) != len(step.expected) {
+				t.Fatalf("イベント数 = %v (%+v), want %v", len(events), events, step.expected)
+			}
+			for i, event := range events {
+				if event.Type != step.expected[i] {
+					t.Errorf("events[%d].Type = %v, want %v", i, event.Type, step.expected[i])
+				}
+			}
+		})
+	}
+
+	// 商品ごとの閾値が使われる
+	if len(events) == 2 && (events[1].ProductID != custom.ID || events[1].Threshold != 50) {
+		t.Errorf("events[1] = %+v, want product-2 の閾値50", events[1])
+	}
+}
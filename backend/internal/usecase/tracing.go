@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/NRUG-SRE/slm-handson/backend/internal/usecase")
+
+// startSpan はユースケース処理の子スパンを開始する（呼び出し元で span.End() すること）
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
@@ -1,15 +1,24 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Server      ServerConfig
 	NewRelic    NewRelicConfig
+	Telemetry   TelemetryConfig
 	Performance PerformanceConfig
+	SLO         SLOConfig
+	Cart        CartConfig
+	Inventory   InventoryConfig
+	Payment     PaymentConfig
+	Idempotency IdempotencyConfig
 }
 
 type ServerConfig struct {
@@ -22,11 +31,120 @@ type NewRelicConfig struct {
 	AppName string
 }
 
+type TelemetryConfig struct {
+	// テレメトリの送信先（newrelic, otel, log, memory）
+	Backend string
+
+	// OpenTelemetry のトレース・メトリクスの送信先（OTLP/HTTP のコレクター）
+	OTLPEndpoint string
+
+	// OpenTelemetry のサービス名
+	ServiceName string
+}
+
 type PerformanceConfig struct {
 	ErrorRate        float64
 	ResponseTimeMin  int
 	ResponseTimeMax  int
 	SlowEndpointRate float64
+
+	// ルート単位の障害注入プロファイル（JSON/YAML）。指定時は上記の値より優先
+	FaultProfilesFile string
+
+	// 起動時に開始する障害シナリオ（JSON/YAML）
+	FaultScenarioFile string
+
+	// 乱数シード（0 は起動時刻）。同じ値を指定すると遅延・エラーの発生系列を再現できる
+	RandomSeed int64
+}
+
+type SLOConfig struct {
+	// SLO定義ファイル（JSON/YAML）。未指定の場合は既定のSLOを使用
+	ObjectivesFile string
+
+	// バーンレートアラートのルール定義ファイル（JSON/YAML）。未指定の場合は各SLOに推奨ルールを適用
+	AlertRulesFile string
+
+	// アラートの発報・解消を通知するWebhook URL
+	AlertWebhookURL string
+
+	// アラートの評価間隔
+	AlertInterval time.Duration
+}
+
+type CartConfig struct {
+	// 認証済みユーザー・X-Session-ID・Cookie のいずれもない場合の扱い
+	// cookie: 新しいカートIDを発行してCookieに保存、shared: 共有カート（従来の振る舞い）、reject: 400エラー
+	Fallback string
+
+	// X-User-ID ヘッダーを認証済みのユーザーIDとして扱う
+	// アプリケーションはヘッダーを検証しないため、認証プロキシがヘッダーを付与・上書きする環境でのみ有効にする
+	TrustUserHeader bool
+
+	// 一定時間更新されていないカートを削除するまでの時間
+	TTL time.Duration
+
+	// 期限切れカートの削除間隔
+	SweepInterval time.Duration
+}
+
+type InventoryConfig struct {
+	// 在庫の扱い
+	// unlimited: 在庫を確認・消費しない（従来の振る舞い）、tracked: 注文時に在庫を引き当て、決済完了で確定・失敗で解放
+	Mode string
+
+	// 在庫僅少とする在庫数（商品ごとの閾値が未設定の場合）
+	LowStockThreshold int
+
+	// 在庫イベント（LowStock / OutOfStock / Restocked）の通知先（log, webhook のカンマ区切り。テレメトリには常に記録）
+	EventSinks []string
+
+	// 在庫イベントを通知するWebhook URL（EventSinks に webhook を含む場合）
+	WebhookURL string
+}
+
+type PaymentConfig struct {
+	// 決済ゲートウェイ
+	// fake: プロセス内の擬似決済、http: GatewayURL の決済API（ローカルでは cmd/payment-stub）を呼び出す
+	Provider string
+
+	// 擬似決済の与信の承認率（0.0-1.0）
+	SuccessRate float64
+
+	// 擬似決済の与信の処理時間
+	LatencyMin time.Duration
+	LatencyMax time.Duration
+
+	// 擬似決済で与信を拒否するときの理由（カンマ区切りからランダムに選ぶ）
+	DeclineCodes []string
+
+	// 擬似決済が最後の操作から決済を保持する期間（期限切れの決済は返金できない）
+	Retention time.Duration
+
+	// 決済APIのベースURL（Provider が http の場合）
+	GatewayURL string
+
+	// 決済APIの呼び出しのタイムアウト
+	Timeout time.Duration
+
+	// 決済処理を並行して実行するワーカー数
+	Workers int
+
+	// 決済待ちのジョブをキューに保持できる件数（満杯の場合は注文を受け付けない）
+	QueueSize int
+
+	// 決済ゲートウェイの一時的な障害・注文の保存の失敗の再試行（初回を含む最大試行回数と指数バックオフの間隔）
+	RetryMaxAttempts    int
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+
+	// 再試行の間隔のばらつき（0.2 で ±20%）
+	RetryJitter float64
+}
+
+type IdempotencyConfig struct {
+	// Idempotency-Key ごとに最初のレスポンスを保持する時間（この間の再送には同じレスポンスを返す）
+	TTL time.Duration
 }
 
 func Load() *Config {
@@ -39,11 +157,58 @@ func Load() *Config {
 			APIKey:  getEnv("NEW_RELIC_API_KEY", ""),
 			AppName: getEnv("NEW_RELIC_APP_NAME", "slm-handson-api"),
 		},
+		Telemetry: TelemetryConfig{
+			Backend:      getEnv("TELEMETRY_BACKEND", "newrelic"),
+			OTLPEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+			ServiceName:  getEnv("OTEL_SERVICE_NAME", "slm-handson-api"),
+		},
 		Performance: PerformanceConfig{
 			ErrorRate:        getEnvFloat("ERROR_RATE", 0.0),
 			ResponseTimeMin:  getEnvInt("RESPONSE_TIME_MIN", 50),
 			ResponseTimeMax:  getEnvInt("RESPONSE_TIME_MAX", 500),
 			SlowEndpointRate: getEnvFloat("SLOW_ENDPOINT_RATE", 0.0),
+
+			FaultProfilesFile: getEnv("FAULT_PROFILES_FILE", ""),
+			FaultScenarioFile: getEnv("FAULT_SCENARIO_FILE", ""),
+			RandomSeed:        getEnvInt64("RANDOM_SEED", 0),
+		},
+		SLO: SLOConfig{
+			ObjectivesFile:  getEnv("SLO_FILE", ""),
+			AlertRulesFile:  getEnv("SLO_ALERT_RULES_FILE", ""),
+			AlertWebhookURL: getEnv("SLO_ALERT_WEBHOOK_URL", ""),
+			AlertInterval:   getEnvDuration("SLO_ALERT_INTERVAL", 30*time.Second),
+		},
+		Cart: CartConfig{
+			Fallback:        getEnv("CART_FALLBACK", "cookie"),
+			TrustUserHeader: getEnvBool("CART_TRUST_USER_HEADER", false),
+			TTL:             getEnvDuration("CART_TTL", 30*time.Minute),
+			SweepInterval:   getEnvDuration("CART_SWEEP_INTERVAL", time.Minute),
+		},
+		Inventory: InventoryConfig{
+			Mode:              getEnv("INVENTORY_MODE", "unlimited"),
+			LowStockThreshold: getEnvInt("LOW_STOCK_THRESHOLD", 10),
+			EventSinks:        getEnvList("STOCK_EVENT_SINKS", "log"),
+			WebhookURL:        getEnv("STOCK_WEBHOOK_URL", ""),
+		},
+		Payment: PaymentConfig{
+			Provider:     getEnv("PAYMENT_PROVIDER", "fake"),
+			SuccessRate:  getEnvFloat("PAYMENT_SUCCESS_RATE", 0.9),
+			LatencyMin:   getEnvDuration("PAYMENT_LATENCY_MIN", 2*time.Second),
+			LatencyMax:   getEnvDuration("PAYMENT_LATENCY_MAX", 9*time.Second),
+			DeclineCodes: getEnvList("PAYMENT_DECLINE_CODES", "insufficient_funds,card_expired,card_declined"),
+			Retention:    getEnvDuration("PAYMENT_RETENTION", 24*time.Hour),
+			GatewayURL:   getEnv("PAYMENT_GATEWAY_URL", "http://localhost:8090"),
+			Timeout:      getEnvDuration("PAYMENT_TIMEOUT", 15*time.Second),
+			Workers:      getEnvInt("PAYMENT_WORKERS", 4),
+			QueueSize:    getEnvInt("PAYMENT_QUEUE_SIZE", 100),
+
+			RetryMaxAttempts:    getEnvInt("PAYMENT_RETRY_MAX_ATTEMPTS", 5),
+			RetryInitialBackoff: getEnvDuration("PAYMENT_RETRY_INITIAL_BACKOFF", time.Second),
+			RetryMaxBackoff:     getEnvDuration("PAYMENT_RETRY_MAX_BACKOFF", 30*time.Second),
+			RetryJitter:         getEnvFloat("PAYMENT_RETRY_JITTER", 0.2),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
 		},
 	}
 }
@@ -57,6 +222,64 @@ func (c *Config) Validate() error {
 		log.Println("Warning: NEW_RELIC_API_KEY not set, New Relic monitoring will be disabled")
 	}
 
+	switch c.Cart.Fallback {
+	case "cookie", "shared", "reject":
+	default:
+		return fmt.Errorf("invalid CART_FALLBACK %q (want cookie, shared or reject)", c.Cart.Fallback)
+	}
+
+	switch c.Inventory.Mode {
+	case "unlimited", "tracked":
+	default:
+		return fmt.Errorf("invalid INVENTORY_MODE %q (want unlimited or tracked)", c.Inventory.Mode)
+	}
+
+	if c.Inventory.LowStockThreshold < 0 {
+		return fmt.Errorf("invalid LOW_STOCK_THRESHOLD %d (want 0 or greater)", c.Inventory.LowStockThreshold)
+	}
+	for _, sink := range c.Inventory.EventSinks {
+		switch sink {
+		case "log":
+		case "webhook":
+			if c.Inventory.WebhookURL == "" {
+				return fmt.Errorf("STOCK_WEBHOOK_URL is required when STOCK_EVENT_SINKS includes webhook")
+			}
+		default:
+			return fmt.Errorf("invalid STOCK_EVENT_SINKS entry %q (want log or webhook)", sink)
+		}
+	}
+
+	switch c.Payment.Provider {
+	case "fake", "http":
+	default:
+		return fmt.Errorf("invalid PAYMENT_PROVIDER %q (want fake or http)", c.Payment.Provider)
+	}
+	if c.Payment.SuccessRate < 0 || c.Payment.SuccessRate > 1 {
+		return fmt.Errorf("invalid PAYMENT_SUCCESS_RATE %v (want 0.0-1.0)", c.Payment.SuccessRate)
+	}
+	if c.Payment.LatencyMin > c.Payment.LatencyMax {
+		return fmt.Errorf("PAYMENT_LATENCY_MIN %s is greater than PAYMENT_LATENCY_MAX %s", c.Payment.LatencyMin, c.Payment.LatencyMax)
+	}
+	if c.Payment.Workers < 1 {
+		return fmt.Errorf("invalid PAYMENT_WORKERS %d (want 1 or more)", c.Payment.Workers)
+	}
+	if c.Payment.QueueSize < 1 {
+		return fmt.Errorf("invalid PAYMENT_QUEUE_SIZE %d (want 1 or more)", c.Payment.QueueSize)
+	}
+	if c.Payment.RetryMaxAttempts < 1 {
+		return fmt.Errorf("invalid PAYMENT_RETRY_MAX_ATTEMPTS %d (want 1 or more)", c.Payment.RetryMaxAttempts)
+	}
+	if c.Payment.RetryInitialBackoff > c.Payment.RetryMaxBackoff {
+		return fmt.Errorf("PAYMENT_RETRY_INITIAL_BACKOFF %s is greater than PAYMENT_RETRY_MAX_BACKOFF %s", c.Payment.RetryInitialBackoff, c.Payment.RetryMaxBackoff)
+	}
+	if c.Payment.RetryJitter < 0 || c.Payment.RetryJitter > 1 {
+		return fmt.Errorf("invalid PAYMENT_RETRY_JITTER %v (want 0.0-1.0)", c.Payment.RetryJitter)
+	}
+
+	if c.Idempotency.TTL <= 0 {
+		return fmt.Errorf("invalid IDEMPOTENCY_TTL %s (want greater than 0)", c.Idempotency.TTL)
+	}
+
 	return nil
 }
 
@@ -67,6 +290,22 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList はカンマ区切りの値を返す（空の要素は除く。"none" を指定すると空）
+func getEnvList(key, defaultValue string) []string {
+	value := getEnv(key, defaultValue)
+	if value == "none" {
+		return nil
+	}
+
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -77,6 +316,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intValue
+		}
+		log.Printf("Warning: Invalid integer value for %s: %s, using default %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
 func getEnvFloat(key string, defaultValue float64) float64 {
 	if value := os.Getenv(key); value != "" {
 		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
@@ -86,3 +335,23 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+		log.Printf("Warning: Invalid boolean value for %s: %s, using default %t", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationValue, err := time.ParseDuration(value); err == nil && durationValue > 0 {
+			return durationValue
+		}
+		log.Printf("Warning: Invalid duration value for %s: %s, using default %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
@@ -0,0 +1,223 @@
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+var ErrInvalidProfile = errors.New("invalid fault profile")
+
+// Profile はルート単位の障害注入設定
+type Profile struct {
+	ErrorRate  float64 `json:"errorRate" yaml:"errorRate"`
+	StatusCode int     `json:"statusCode,omitempty" yaml:"statusCode,omitempty"`
+	ErrorCode  string  `json:"errorCode,omitempty" yaml:"errorCode,omitempty"`
+	Latency    Latency `json:"latency" yaml:"latency"`
+}
+
+// Profiles はルートキー（例: "POST /api/orders"）ごとのプロファイル
+type Profiles map[string]Profile
+
+// Settings は環境変数で指定する従来のグローバル設定
+type Settings struct {
+	ErrorRate        float64
+	ResponseTimeMin  int
+	ResponseTimeMax  int
+	SlowEndpointRate float64
+}
+
+// RouteKey はginのルートパターンからプロファイルのキーを生成する
+func RouteKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// DefaultProfiles は従来のハードコードされた振る舞いを再現するプロファイルを返す
+func DefaultProfiles(settings Settings) Profiles {
+	base := Profile{
+		ErrorRate: settings.ErrorRate,
+		Latency: Latency{
+			MinMs:    settings.ResponseTimeMin,
+			MaxMs:    settings.ResponseTimeMax,
+			SlowRate: settings.SlowEndpointRate,
+		},
+	}
+
+	// 注文処理はエラー率1.5倍、最大レスポンス時間2倍
+	order := base
+	order.ErrorRate = minFloat(settings.ErrorRate*1.5, 1.0)
+	order.Latency.MaxMs = settings.ResponseTimeMax * 2
+
+	return Profiles{
+		RouteKey("GET", "/api/products"):     base,
+		RouteKey("GET", "/api/products/:id"): base,
+		RouteKey("POST", "/api/orders"):      order,
+	}
+}
+
+// LoadProfiles はJSONまたはYAMLファイルからプロファイルを読み込む
+func LoadProfiles(path string) (Profiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fault profiles: %w", err)
+	}
+
+	profiles := Profiles{}
+	if err := utils.DecodeFile(path, data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse fault profiles: %w", err)
+	}
+
+	if err := profiles.Validate(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+func (p Profile) Validate() error {
+	if p.ErrorRate < 0 || p.ErrorRate > 1 {
+		return ErrInvalidProfile
+	}
+	if p.StatusCode != 0 && (p.StatusCode < 400 || p.StatusCode > 599) {
+		return ErrInvalidProfile
+	}
+	return p.Latency.Validate()
+}
+
+func (ps Profiles) Validate() error {
+	for route, profile := range ps {
+		parts := strings.SplitN(route, " ", 2)
+		if len(parts) != 2 || parts[0] == "" || !strings.HasPrefix(parts[1], "/") {
+			return ErrInvalidProfile
+		}
+		if err := profile.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clone はルートキーのメソッド部分を大文字に正規化したコピーを返す
+func (ps Profiles) clone() Profiles {
+	cloned := make(Profiles, len(ps))
+	for route, profile := range ps {
+		if parts := strings.SplitN(route, " ", 2); len(parts) == 2 {
+			route = RouteKey(parts[0], parts[1])
+		}
+		cloned[route] = profile
+	}
+	return cloned
+}
+
+// Config は実行中に変更可能な障害注入設定（並行アクセス安全）
+type Config struct {
+	profiles  Profiles
+	updatedAt time.Time
+	rng       utils.Random
+	mutex     sync.RWMutex
+}
+
+// Decision は1リクエストに対する障害注入の判定結果
+type Decision struct {
+	Route   string
+	Profile Profile
+	Delay   time.Duration
+	Fail    bool
+}
+
+// NewConfig は障害注入設定を作成する。rng は並行アクセス安全である必要がある（utils.NewSeededRand）
+func NewConfig(profiles Profiles, rng utils.Random) *Config {
+	if profiles == nil {
+		profiles = Profiles{}
+	}
+	return &Config{
+		profiles:  profiles.clone(),
+		updatedAt: time.Now(),
+		rng:       rng,
+	}
+}
+
+// Decide はルートのプロファイルに従って遅延時間とエラー発生有無を決定する
+func (c *Config) Decide(method, path string) (Decision, bool) {
+	profile, exists := c.Lookup(method, path)
+	if !exists {
+		return Decision{}, false
+	}
+
+	return Decision{
+		Route:   RouteKey(method, path),
+		Profile: profile,
+		Delay:   profile.Latency.Sample(c.rng),
+		Fail:    c.rng.Float64() < profile.ErrorRate,
+	}, true
+}
+
+// Lookup はルートに対応するプロファイルを返す
+func (c *Config) Lookup(method, path string) (Profile, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	profile, exists := c.profiles[RouteKey(method, path)]
+	return profile, exists
+}
+
+func (c *Config) Get() Profiles {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.profiles.clone()
+}
+
+func (c *Config) UpdatedAt() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.updatedAt
+}
+
+// Replace は全プロファイルを置き換え、変更前と変更後のプロファイルを返す
+// 変更後のプロファイルは同じロック内で複製するため、同時に行われた別の変更を含まない（監査ログ用）
+func (c *Config) Replace(profiles Profiles) (previous, current Profiles, err error) {
+	if err := profiles.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	previous = c.profiles
+	c.profiles = profiles.clone()
+	c.updatedAt = time.Now()
+	return previous, c.profiles.clone(), nil
+}
+
+// Merge は指定されたルートのプロファイルのみ更新し、変更前と変更後のプロファイルを返す（変更後は Replace と同じく同じロック内で複製する）
+func (c *Config) Merge(profiles Profiles) (previous, current Profiles, err error) {
+	if err := profiles.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	previous = c.profiles
+	merged := previous.clone()
+	for route, profile := range profiles.clone() {
+		merged[route] = profile
+	}
+	c.profiles = merged
+	c.updatedAt = time.Now()
+	return previous, merged.clone(), nil
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,231 @@
+package fault
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+func TestDefaultProfiles(t *testing.T) {
+	profiles := DefaultProfiles(Settings{
+		ErrorRate:        0.5,
+		ResponseTimeMin:  50,
+		ResponseTimeMax:  500,
+		SlowEndpointRate: 0.1,
+	})
+
+	tests := []struct {
+		name          string
+		route         string
+		expectedRate  float64
+		expectedMaxMs int
+	}{
+		{name: "商品一覧", route: "GET /api/products", expectedRate: 0.5, expectedMaxMs: 500},
+		{name: "商品詳細", route: "GET /api/products/:id", expectedRate: 0.5, expectedMaxMs: 500},
+		{name: "注文作成はエラー率1.5倍・遅延2倍", route: "POST /api/orders", expectedRate: 0.75, expectedMaxMs: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, exists := profiles[tt.route]
+			if !exists {
+				t.Fatalf("プロファイルが存在しません: %s", tt.route)
+			}
+			if profile.ErrorRate != tt.expectedRate {
+				t.Errorf("ErrorRate = %v, want %v", profile.ErrorRate, tt.expectedRate)
+			}
+			if profile.Latency.MaxMs != tt.expectedMaxMs {
+				t.Errorf("MaxMs = %v, want %v", profile.Latency.MaxMs, tt.expectedMaxMs)
+			}
+		})
+	}
+
+	// カートは障害注入の対象外
+	if _, exists := profiles["GET /api/cart"]; exists {
+		t.Error("カートのプロファイルは存在しないべきです")
+	}
+
+	// エラー率は1を超えない
+	capped := DefaultProfiles(Settings{ErrorRate: 0.9})
+	if capped["POST /api/orders"].ErrorRate != 1.0 {
+		t.Errorf("ErrorRate = %v, want 1.0", capped["POST /api/orders"].ErrorRate)
+	}
+}
+
+func TestProfiles_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		profiles    Profiles
+		expectError bool
+	}{
+		{
+			name:        "有効なプロファイル",
+			profiles:    Profiles{"GET /api/cart": {ErrorRate: 0.5, StatusCode: 503, ErrorCode: "SERVICE_UNAVAILABLE"}},
+			expectError: false,
+		},
+		{
+			name:        "空のプロファイル",
+			profiles:    Profiles{},
+			expectError: false,
+		},
+		{
+			name:        "エラー率が1を超える",
+			profiles:    Profiles{"GET /api/cart": {ErrorRate: 1.5}},
+			expectError: true,
+		},
+		{
+			name:        "エラー以外のステータスコード",
+			profiles:    Profiles{"GET /api/cart": {StatusCode: 200}},
+			expectError: true,
+		},
+		{
+			name:        "負の遅延",
+			profiles:    Profiles{"GET /api/cart": {Latency: Latency{MinMs: -1}}},
+			expectError: true,
+		},
+		{
+			name:        "未知の遅延モデル",
+			profiles:    Profiles{"GET /api/cart": {Latency: Latency{Model: "gamma"}}},
+			expectError: true,
+		},
+		{
+			name:        "メソッドのないルートキー",
+			profiles:    Profiles{"/api/cart": {}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profiles.Validate()
+			if tt.expectError && err != ErrInvalidProfile {
+				t.Errorf("Expected ErrInvalidProfile, got %v", err)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("予期しないエラー: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfig_ReplaceAndMerge(t *testing.T) {
+	config := NewConfig(Profiles{
+		"GET /api/products": {ErrorRate: 0.1},
+		"POST /api/orders":  {ErrorRate: 0.2},
+	}, utils.NewSeededRand(1))
+
+	// Mergeは指定ルートのみ更新（メソッドは大文字に正規化）
+	previous, current, err := config.Merge(Profiles{"post /api/orders": {ErrorRate: 0.5}})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if previous["POST /api/orders"].ErrorRate != 0.2 {
+		t.Errorf("previous ErrorRate = %v, want 0.2", previous["POST /api/orders"].ErrorRate)
+	}
+	if len(current) != 2 || current["POST /api/orders"].ErrorRate != 0.5 {
+		t.Errorf("current = %+v", current)
+	}
+	if profile, _ := config.Lookup("POST", "/api/orders"); profile.ErrorRate != 0.5 {
+		t.Errorf("ErrorRate = %v, want 0.5", profile.ErrorRate)
+	}
+	if _, exists := config.Lookup("GET", "/api/products"); !exists {
+		t.Error("Mergeで他のルートが削除されました")
+	}
+
+	// Replaceは全体を置き換え
+	if _, current, err := config.Replace(Profiles{"GET /api/cart": {ErrorRate: 1}}); err != nil || len(current) != 1 {
+		t.Fatalf("Replace() = %+v, %v", current, err)
+	}
+	if _, exists := config.Lookup("GET", "/api/products"); exists {
+		t.Error("Replace後に古いルートが残っています")
+	}
+	if profile, exists := config.Lookup("GET", "/api/cart"); !exists || profile.ErrorRate != 1 {
+		t.Errorf("Lookup = %+v, %v", profile, exists)
+	}
+
+	// 無効な設定は反映されない
+	if _, _, err := config.Replace(Profiles{"GET /api/cart": {ErrorRate: 2}}); err != ErrInvalidProfile {
+		t.Errorf("Expected ErrInvalidProfile, got %v", err)
+	}
+	if profile, _ := config.Lookup("GET", "/api/cart"); profile.ErrorRate != 1 {
+		t.Errorf("無効な設定で値が変更されました: %+v", profile)
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "faults.json")
+	os.WriteFile(jsonPath, []byte(`{"GET /api/cart": {"errorRate": 0.3, "statusCode": 503, "latency": {"minMs": 10, "maxMs": 100}}}`), 0o644)
+
+	yamlPath := filepath.Join(dir, "faults.yaml")
+	os.WriteFile(yamlPath, []byte("GET /api/cart:\n  errorRate: 0.3\n  statusCode: 503\n  latency:\n    minMs: 10\n    maxMs: 100\n"), 0o644)
+
+	for _, path := range []string{jsonPath, yamlPath} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			profiles, err := LoadProfiles(path)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			expected := Profile{ErrorRate: 0.3, StatusCode: 503, Latency: Latency{MinMs: 10, MaxMs: 100}}
+			if profiles["GET /api/cart"] != expected {
+				t.Errorf("profile = %+v, want %+v", profiles["GET /api/cart"], expected)
+			}
+		})
+	}
+
+	if _, err := LoadProfiles(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("存在しないファイルでエラーになるべきです")
+	}
+}
+
+func TestConfig_Decide(t *testing.T) {
+	profiles := Profiles{
+		"POST /api/orders": {ErrorRate: 0.3, Latency: Latency{Model: LatencyLogNormal, MedianMs: 100, Sigma: 0.5}},
+	}
+
+	// 同じシードなら同じ判定系列になる
+	first := NewConfig(profiles, utils.NewSeededRand(42))
+	second := NewConfig(profiles, utils.NewSeededRand(42))
+	for i := 0; i < 50; i++ {
+		a, _ := first.Decide("POST", "/api/orders")
+		b, _ := second.Decide("POST", "/api/orders")
+		if a != b {
+			t.Fatalf("%d回目の判定が一致しません: %+v != %+v", i, a, b)
+		}
+	}
+
+	if _, exists := first.Decide("GET", "/api/cart"); exists {
+		t.Error("プロファイルのないルートで判定が返されました")
+	}
+}
+
+// 並行処理の安全性をテスト
+func TestConfig_ConcurrentAccess(t *testing.T) {
+	config := NewConfig(nil, utils.NewSeededRand(1))
+
+	const numGoroutines = 10
+
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines * 2)
+
+	for i := 0; i < numGoroutines; i++ {
+		go func(id int) {
+			defer wg.Done()
+			if _, _, err := config.Merge(Profiles{"GET /api/products": {ErrorRate: float64(id) / numGoroutines}}); err != nil {
+				t.Errorf("並行Mergeでエラー: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if profile, _ := config.Lookup("GET", "/api/products"); profile.Validate() != nil {
+				t.Errorf("不正な設定が読み取られました: %+v", profile)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
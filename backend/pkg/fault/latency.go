@@ -0,0 +1,161 @@
+package fault
+
+import (
+	"math"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// 遅延分布モデル
+const (
+	LatencyUniform    = "uniform"    // minMs〜maxMs の一様分布（slowRate の確率で最大時間を2-3倍）
+	LatencyLogNormal  = "lognormal"  // 中央値 medianMs、ばらつき sigma の対数正規分布
+	LatencyPareto     = "pareto"     // 最小値 scaleMs、裾の重さ alpha のパレート分布（ロングテール）
+	LatencyBimodal    = "bimodal"    // minMs〜maxMs と slowMinMs〜slowMaxMs の二峰分布（slowRate が遅い側の確率）
+	LatencyPercentile = "percentile" // p50Ms と p90Ms/p95Ms/p99Ms を満たす対数正規分布
+)
+
+// defaultMaxLatencyMs は maxMs が0の場合の遅延の上限（ロングテールの分布で time.Duration が溢れないようにする）
+const defaultMaxLatencyMs = 30000
+
+// Latency はレスポンス遅延の分布設定
+// maxMs は uniform/bimodal 以外のモデルでは上限値（0 は既定の上限 defaultMaxLatencyMs）として扱う
+type Latency struct {
+	Model    string  `json:"model,omitempty" yaml:"model,omitempty"`
+	MinMs    int     `json:"minMs" yaml:"minMs"`
+	MaxMs    int     `json:"maxMs" yaml:"maxMs"`
+	SlowRate float64 `json:"slowRate" yaml:"slowRate"`
+
+	// lognormal
+	MedianMs float64 `json:"medianMs,omitempty" yaml:"medianMs,omitempty"`
+	Sigma    float64 `json:"sigma,omitempty" yaml:"sigma,omitempty"`
+
+	// pareto
+	ScaleMs float64 `json:"scaleMs,omitempty" yaml:"scaleMs,omitempty"`
+	Alpha   float64 `json:"alpha,omitempty" yaml:"alpha,omitempty"`
+
+	// bimodal
+	SlowMinMs int `json:"slowMinMs,omitempty" yaml:"slowMinMs,omitempty"`
+	SlowMaxMs int `json:"slowMaxMs,omitempty" yaml:"slowMaxMs,omitempty"`
+
+	// percentile
+	P50Ms float64 `json:"p50Ms,omitempty" yaml:"p50Ms,omitempty"`
+	P90Ms float64 `json:"p90Ms,omitempty" yaml:"p90Ms,omitempty"`
+	P95Ms float64 `json:"p95Ms,omitempty" yaml:"p95Ms,omitempty"`
+	P99Ms float64 `json:"p99Ms,omitempty" yaml:"p99Ms,omitempty"`
+}
+
+func (l Latency) Validate() error {
+	if l.MinMs < 0 || l.MaxMs < 0 || l.SlowRate < 0 || l.SlowRate > 1 {
+		return ErrInvalidProfile
+	}
+
+	switch l.Model {
+	case "", LatencyUniform:
+		if l.MaxMs < l.MinMs {
+			return ErrInvalidProfile
+		}
+	case LatencyLogNormal:
+		if l.MedianMs <= 0 || l.Sigma <= 0 {
+			return ErrInvalidProfile
+		}
+	case LatencyPareto:
+		if l.ScaleMs <= 0 || l.Alpha <= 0 {
+			return ErrInvalidProfile
+		}
+	case LatencyBimodal:
+		if l.MaxMs < l.MinMs || l.SlowMinMs < 0 || l.SlowMaxMs < l.SlowMinMs {
+			return ErrInvalidProfile
+		}
+	case LatencyPercentile:
+		if _, _, ok := l.percentileFit(); !ok {
+			return ErrInvalidProfile
+		}
+	default:
+		return ErrInvalidProfile
+	}
+	return nil
+}
+
+// Sample は分布モデルに従って遅延時間を決定する
+func (l Latency) Sample(r utils.Random) time.Duration {
+	var ms float64
+
+	switch l.Model {
+	case LatencyLogNormal:
+		ms = l.clamp(utils.LogNormal(r, math.Log(l.MedianMs), l.Sigma))
+	case LatencyPareto:
+		ms = l.clamp(utils.Pareto(r, l.ScaleMs, l.Alpha))
+	case LatencyBimodal:
+		if r.Float64() < l.SlowRate {
+			ms = utils.Uniform(r, float64(l.SlowMinMs), float64(l.SlowMaxMs))
+		} else {
+			ms = utils.Uniform(r, float64(l.MinMs), float64(l.MaxMs))
+		}
+	case LatencyPercentile:
+		mu, sigma, _ := l.percentileFit()
+		ms = l.clamp(utils.LogNormal(r, mu, sigma))
+	default:
+		ms = l.sampleUniform(r)
+	}
+
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// 従来の振る舞い（一定確率で遅いエンドポイントとして最大時間を2-3倍）
+func (l Latency) sampleUniform(r utils.Random) float64 {
+	maxTime := l.MaxMs
+
+	if r.Float64() < l.SlowRate {
+		maxTime = maxTime * (2 + r.Intn(2))
+	}
+
+	// 範囲が空の場合は固定の遅延（minMs）
+	if maxTime <= l.MinMs {
+		return float64(l.MinMs)
+	}
+
+	return float64(l.MinMs + r.Intn(maxTime-l.MinMs))
+}
+
+// percentileFit は p50 と上位パーセンタイルの目標値から対数正規分布のパラメータを求める
+func (l Latency) percentileFit() (mu, sigma float64, ok bool) {
+	if l.P50Ms <= 0 {
+		return 0, 0, false
+	}
+
+	var target, p float64
+	switch {
+	case l.P99Ms > 0:
+		target, p = l.P99Ms, 0.99
+	case l.P95Ms > 0:
+		target, p = l.P95Ms, 0.95
+	case l.P90Ms > 0:
+		target, p = l.P90Ms, 0.90
+	default:
+		return 0, 0, false
+	}
+	if target <= l.P50Ms {
+		return 0, 0, false
+	}
+
+	mu = math.Log(l.P50Ms)
+	sigma = (math.Log(target) - mu) / utils.NormalQuantile(p)
+	return mu, sigma, true
+}
+
+func (l Latency) clamp(ms float64) float64 {
+	maxMs := l.MaxMs
+	if maxMs == 0 {
+		maxMs = defaultMaxLatencyMs
+	}
+
+	if ms < float64(l.MinMs) {
+		return float64(l.MinMs)
+	}
+	if ms > float64(maxMs) {
+		return float64(maxMs)
+	}
+	return ms
+}
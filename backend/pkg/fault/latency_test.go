@@ -0,0 +1,131 @@
+package fault
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// percentile はサンプルからパーセンタイル値（ms）を求める
+func percentile(latency Latency, p float64) float64 {
+	r := utils.NewSeededRand(1)
+	samples := make([]float64, 20000)
+	for i := range samples {
+		samples[i] = float64(latency.Sample(r)) / float64(time.Millisecond)
+	}
+	sort.Float64s(samples)
+	return samples[int(p*float64(len(samples)-1))]
+}
+
+func TestLatency_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		latency     Latency
+		expectError bool
+	}{
+		{name: "従来の一様分布", latency: Latency{MinMs: 50, MaxMs: 500, SlowRate: 0.2}, expectError: false},
+		{name: "一様分布の固定値", latency: Latency{MinMs: 100, MaxMs: 100}, expectError: false},
+		{name: "一様分布の範囲が逆転", latency: Latency{MinMs: 500, MaxMs: 50}, expectError: true},
+		{name: "対数正規分布", latency: Latency{Model: LatencyLogNormal, MedianMs: 100, Sigma: 0.5}, expectError: false},
+		{name: "対数正規分布のsigma未指定", latency: Latency{Model: LatencyLogNormal, MedianMs: 100}, expectError: true},
+		{name: "パレート分布", latency: Latency{Model: LatencyPareto, ScaleMs: 50, Alpha: 1.5}, expectError: false},
+		{name: "パレート分布のalpha未指定", latency: Latency{Model: LatencyPareto, ScaleMs: 50}, expectError: true},
+		{name: "二峰分布", latency: Latency{Model: LatencyBimodal, MinMs: 20, MaxMs: 50, SlowMinMs: 800, SlowMaxMs: 1200, SlowRate: 0.1}, expectError: false},
+		{name: "二峰分布の範囲が逆転", latency: Latency{Model: LatencyBimodal, SlowMinMs: 800, SlowMaxMs: 100}, expectError: true},
+		{name: "二峰分布の速い側の範囲が逆転", latency: Latency{Model: LatencyBimodal, MinMs: 50, MaxMs: 20, SlowMinMs: 800, SlowMaxMs: 1200}, expectError: true},
+		{name: "パーセンタイル目標", latency: Latency{Model: LatencyPercentile, P50Ms: 200, P99Ms: 1200}, expectError: false},
+		{name: "パーセンタイル目標のp99がp50以下", latency: Latency{Model: LatencyPercentile, P50Ms: 200, P99Ms: 100}, expectError: true},
+		{name: "パーセンタイル目標の上位値なし", latency: Latency{Model: LatencyPercentile, P50Ms: 200}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.latency.Validate()
+			if tt.expectError && err != ErrInvalidProfile {
+				t.Errorf("Expected ErrInvalidProfile, got %v", err)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("予期しないエラー: %v", err)
+			}
+		})
+	}
+}
+
+func TestLatency_Sample(t *testing.T) {
+	r := utils.NewSeededRand(1)
+
+	t.Run("一様分布は範囲内", func(t *testing.T) {
+		latency := Latency{MinMs: 10, MaxMs: 20}
+		for i := 0; i < 100; i++ {
+			delay := latency.Sample(r)
+			if delay < 10*time.Millisecond || delay >= 20*time.Millisecond {
+				t.Fatalf("遅延 = %v, want [10ms, 20ms)", delay)
+			}
+		}
+		if delay := (Latency{}).Sample(r); delay != 0 {
+			t.Errorf("遅延 = %v, want 0", delay)
+		}
+		// 範囲が空の場合は最小値で固定
+		if delay := (Latency{MinMs: 100, MaxMs: 100}).Sample(r); delay != 100*time.Millisecond {
+			t.Errorf("遅延 = %v, want 100ms", delay)
+		}
+	})
+
+	t.Run("対数正規分布の中央値", func(t *testing.T) {
+		p50 := percentile(Latency{Model: LatencyLogNormal, MedianMs: 100, Sigma: 0.5}, 0.5)
+		if p50 < 95 || p50 > 105 {
+			t.Errorf("p50 = %v, want ≈100", p50)
+		}
+	})
+
+	t.Run("パレート分布は最小値以上で上限で打ち切り", func(t *testing.T) {
+		latency := Latency{Model: LatencyPareto, ScaleMs: 50, Alpha: 1.2, MaxMs: 3000}
+		for i := 0; i < 1000; i++ {
+			delay := latency.Sample(r)
+			if delay < 50*time.Millisecond || delay > 3000*time.Millisecond {
+				t.Fatalf("遅延 = %v, want [50ms, 3000ms]", delay)
+			}
+		}
+	})
+
+	t.Run("上限未指定のパレート分布は既定の上限で打ち切り", func(t *testing.T) {
+		// alpha が小さいと裾が極端に重く、打ち切らないと time.Duration が溢れる
+		latency := Latency{Model: LatencyPareto, ScaleMs: 50, Alpha: 0.01}
+		for i := 0; i < 1000; i++ {
+			delay := latency.Sample(r)
+			if delay < 50*time.Millisecond || delay > defaultMaxLatencyMs*time.Millisecond {
+				t.Fatalf("遅延 = %v, want [50ms, %dms]", delay, defaultMaxLatencyMs)
+			}
+		}
+	})
+
+	t.Run("二峰分布の遅い側の割合", func(t *testing.T) {
+		latency := Latency{Model: LatencyBimodal, MinMs: 10, MaxMs: 20, SlowMinMs: 500, SlowMaxMs: 600, SlowRate: 0.2}
+		slow := 0
+		for i := 0; i < 10000; i++ {
+			delay := latency.Sample(r)
+			if delay >= 500*time.Millisecond {
+				slow++
+			} else if delay >= 20*time.Millisecond {
+				t.Fatalf("遅延 = %v, どちらの山にも属しません", delay)
+			}
+		}
+		if slow < 1800 || slow > 2200 {
+			t.Errorf("遅い側の件数 = %v, want ≈2000", slow)
+		}
+	})
+
+	t.Run("パーセンタイル目標を満たす", func(t *testing.T) {
+		latency := Latency{Model: LatencyPercentile, P50Ms: 200, P99Ms: 1200}
+		p50 := percentile(latency, 0.5)
+		p99 := percentile(latency, 0.99)
+		if p50 < 190 || p50 > 210 {
+			t.Errorf("p50 = %v, want ≈200", p50)
+		}
+		if p99 < 1080 || p99 > 1320 {
+			t.Errorf("p99 = %v, want ≈1200", p99)
+		}
+	})
+}
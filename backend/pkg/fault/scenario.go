@@ -0,0 +1,290 @@
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+var (
+	ErrInvalidScenario   = errors.New("invalid fault scenario")
+	ErrScenarioNotActive = errors.New("no fault scenario is running")
+)
+
+// Step はシナリオ開始から At 経過後に適用するプロファイル変更
+// For を指定すると、その時間経過後に変更前のプロファイルへ戻す
+type Step struct {
+	Name     string         `json:"name" yaml:"name"`
+	At       utils.Duration `json:"at" yaml:"at"`
+	For      utils.Duration `json:"for,omitempty" yaml:"for,omitempty"`
+	Profiles Profiles       `json:"profiles" yaml:"profiles"`
+}
+
+// Scenario はインシデントのタイムライン
+type Scenario struct {
+	Name  string `json:"name" yaml:"name"`
+	Steps []Step `json:"steps" yaml:"steps"`
+}
+
+func (s *Scenario) Validate() error {
+	if s.Name == "" || len(s.Steps) == 0 {
+		return ErrInvalidScenario
+	}
+	for _, step := range s.Steps {
+		if step.Name == "" || step.At < 0 || step.For < 0 || len(step.Profiles) == 0 {
+			return ErrInvalidScenario
+		}
+		if err := step.Profiles.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadScenario はJSONまたはYAMLファイルからシナリオを読み込む
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fault scenario: %w", err)
+	}
+
+	scenario := &Scenario{}
+	if err := utils.DecodeFile(path, data, scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse fault scenario: %w", err)
+	}
+
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+
+	return scenario, nil
+}
+
+// Transition はシナリオによるプロファイル変更1件
+type Transition struct {
+	Scenario  string        `json:"scenario"`
+	Step      string        `json:"step"`
+	Index     int           `json:"index"`
+	Total     int           `json:"total"`
+	Recovery  bool          `json:"recovery"` // For 経過による復旧
+	At        time.Duration `json:"-"`
+	Profiles  Profiles      `json:"profiles"`
+	AppliedAt time.Time     `json:"appliedAt"`
+}
+
+// ScenarioStatus は実行中シナリオの現在・次のステップ
+type ScenarioStatus struct {
+	Scenario  string      `json:"scenario"`
+	Running   bool        `json:"running"`
+	StartedAt time.Time   `json:"startedAt"`
+	Elapsed   string      `json:"elapsed"`
+	Current   *Transition `json:"current"`
+	Next      *StepStatus `json:"next"`
+}
+
+type StepStatus struct {
+	Step     string    `json:"step"`
+	Index    int       `json:"index"`
+	Recovery bool      `json:"recovery"`
+	At       time.Time `json:"at"`
+}
+
+// ScenarioRunner はシナリオのステップをスケジュールどおりに障害注入設定へ適用する
+type ScenarioRunner struct {
+	faults       *Config
+	onTransition func(Transition)
+
+	scenario  *Scenario
+	timeline  []Transition
+	position  int
+	current   *Transition
+	startedAt time.Time
+	running   bool
+	stop      chan struct{}
+	done      chan struct{}
+	mutex     sync.Mutex
+
+	// lifecycle は Start と Stop を直列化する（停止から置き換えまでの間に別の Start が割り込んで、
+	// 停止できないシナリオが残らないようにする。実行中のシナリオは mutex のみを使う）
+	lifecycle sync.Mutex
+}
+
+func NewScenarioRunner(faults *Config, onTransition func(Transition)) *ScenarioRunner {
+	return &ScenarioRunner{
+		faults:       faults,
+		onTransition: onTransition,
+	}
+}
+
+// Start はシナリオを開始する（実行中のシナリオは停止して置き換える）
+func (r *ScenarioRunner) Start(scenario *Scenario) error {
+	if err := scenario.Validate(); err != nil {
+		return err
+	}
+
+	r.lifecycle.Lock()
+	defer r.lifecycle.Unlock()
+
+	r.stopRunning()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.scenario = scenario
+	r.timeline = buildTimeline(scenario)
+	r.position = 0
+	r.current = nil
+	r.startedAt = time.Now()
+	r.running = true
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go r.run(r.stop, r.done)
+	return nil
+}
+
+// Stop は実行中のシナリオを停止する。適用済みのプロファイルはそのまま残る
+func (r *ScenarioRunner) Stop() {
+	r.lifecycle.Lock()
+	defer r.lifecycle.Unlock()
+
+	r.stopRunning()
+}
+
+// stopRunning は実行中のシナリオを停止して終了を待つ（lifecycle を保持して呼び出す）
+func (r *ScenarioRunner) stopRunning() {
+	r.mutex.Lock()
+	if !r.running {
+		r.mutex.Unlock()
+		return
+	}
+	stop, done := r.stop, r.done
+	r.running = false
+	r.mutex.Unlock()
+
+	close(stop)
+	<-done
+}
+
+func (r *ScenarioRunner) Status() (ScenarioStatus, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.scenario == nil {
+		return ScenarioStatus{}, ErrScenarioNotActive
+	}
+
+	status := ScenarioStatus{
+		Scenario:  r.scenario.Name,
+		Running:   r.running,
+		StartedAt: r.startedAt,
+		Elapsed:   time.Since(r.startedAt).Truncate(time.Second).String(),
+		Current:   r.current,
+	}
+	if r.running && r.position < len(r.timeline) {
+		next := r.timeline[r.position]
+		status.Next = &StepStatus{
+			Step:     next.Step,
+			Index:    next.Index,
+			Recovery: next.Recovery,
+			At:       r.startedAt.Add(next.At),
+		}
+	}
+	return status, nil
+}
+
+func (r *ScenarioRunner) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		r.mutex.Lock()
+		if r.position >= len(r.timeline) {
+			r.running = false
+			r.mutex.Unlock()
+			return
+		}
+		next := r.timeline[r.position]
+		wait := time.Until(r.startedAt.Add(next.At))
+		r.mutex.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		r.apply(next)
+	}
+}
+
+func (r *ScenarioRunner) apply(transition Transition) {
+	r.mutex.Lock()
+
+	// 復旧の場合は対応するステップの適用時に保存した変更前の値が入っている
+	profiles := transition.Profiles
+	previous, _, err := r.faults.Merge(profiles)
+	if err == nil && !transition.Recovery {
+		r.saveRecovery(transition.Index, previous, profiles)
+	}
+
+	transition.AppliedAt = time.Now()
+	r.current = &transition
+	r.position++
+	r.mutex.Unlock()
+
+	if err == nil && r.onTransition != nil {
+		r.onTransition(transition)
+	}
+}
+
+// saveRecovery は復旧ステップに変更前のプロファイルを設定する
+func (r *ScenarioRunner) saveRecovery(index int, previous, applied Profiles) {
+	for i := r.position + 1; i < len(r.timeline); i++ {
+		if r.timeline[i].Recovery && r.timeline[i].Index == index {
+			restore := Profiles{}
+			for route := range applied.clone() {
+				// 変更前に存在しなかったルートは障害注入なしのプロファイルに戻す
+				restore[route] = previous[route]
+			}
+			r.timeline[i].Profiles = restore
+			return
+		}
+	}
+}
+
+// buildTimeline はステップと復旧を時刻順に並べる
+func buildTimeline(scenario *Scenario) []Transition {
+	timeline := make([]Transition, 0, len(scenario.Steps)*2)
+	for i, step := range scenario.Steps {
+		timeline = append(timeline, Transition{
+			Scenario: scenario.Name,
+			Step:     step.Name,
+			Index:    i,
+			Total:    len(scenario.Steps),
+			At:       time.Duration(step.At),
+			Profiles: step.Profiles,
+		})
+		if step.For > 0 {
+			timeline = append(timeline, Transition{
+				Scenario: scenario.Name,
+				Step:     step.Name,
+				Index:    i,
+				Total:    len(scenario.Steps),
+				Recovery: true,
+				At:       time.Duration(step.At + step.For),
+			})
+		}
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].At < timeline[j].At
+	})
+	return timeline
+}
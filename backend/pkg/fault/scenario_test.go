@@ -0,0 +1,282 @@
+package fault
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+func TestScenario_Validate(t *testing.T) {
+	profiles := Profiles{"POST /api/orders": {ErrorRate: 0.3}}
+
+	tests := []struct {
+		name     string
+		scenario Scenario
+		wantErr  bool
+	}{
+		{
+			name:     "正常なシナリオ",
+			scenario: Scenario{Name: "incident", Steps: []Step{{Name: "raise", At: utils.Duration(time.Minute), Profiles: profiles}}},
+		},
+		{
+			name:     "名前がない",
+			scenario: Scenario{Steps: []Step{{Name: "raise", Profiles: profiles}}},
+			wantErr:  true,
+		},
+		{
+			name:     "ステップがない",
+			scenario: Scenario{Name: "incident"},
+			wantErr:  true,
+		},
+		{
+			name:     "プロファイルがない",
+			scenario: Scenario{Name: "incident", Steps: []Step{{Name: "raise"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "負の継続時間",
+			scenario: Scenario{Name: "incident", Steps: []Step{{Name: "raise", For: utils.Duration(-time.Minute), Profiles: profiles}}},
+			wantErr:  true,
+		},
+		{
+			name: "不正なプロファイル",
+			scenario: Scenario{Name: "incident", Steps: []Step{{Name: "raise", Profiles: Profiles{
+				"POST /api/orders": {ErrorRate: 2},
+			}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scenario.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "scenario.yaml")
+	yamlData := `name: order-incident
+steps:
+  - name: raise-order-errors
+    at: 5m
+    for: 3m
+    profiles:
+      POST /api/orders:
+        errorRate: 0.3
+        statusCode: 503
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	scenario, err := LoadScenario(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadScenario() error = %v", err)
+	}
+	step := scenario.Steps[0]
+	if time.Duration(step.At) != 5*time.Minute || time.Duration(step.For) != 3*time.Minute {
+		t.Errorf("at/for = %v/%v, want 5m/3m", time.Duration(step.At), time.Duration(step.For))
+	}
+	if step.Profiles["POST /api/orders"].StatusCode != 503 {
+		t.Errorf("statusCode = %v, want 503", step.Profiles["POST /api/orders"].StatusCode)
+	}
+
+	jsonPath := filepath.Join(dir, "scenario.json")
+	jsonData, _ := json.Marshal(scenario)
+	if err := os.WriteFile(jsonPath, jsonData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := LoadScenario(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadScenario() error = %v", err)
+	}
+	if fromJSON.Steps[0].At != step.At {
+		t.Errorf("JSON at = %v, want %v", time.Duration(fromJSON.Steps[0].At), time.Duration(step.At))
+	}
+}
+
+func TestScenarioRunner_AppliesStepsAndRecovers(t *testing.T) {
+	faults := NewConfig(Profiles{
+		"GET /api/products": {ErrorRate: 0.1},
+	}, utils.NewSeededRand(1))
+
+	var (
+		mutex       sync.Mutex
+		transitions []Transition
+		finished    = make(chan struct{})
+	)
+	runner := NewScenarioRunner(faults, func(transition Transition) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		transitions = append(transitions, transition)
+		if len(transitions) == 4 {
+			close(finished)
+		}
+	})
+
+	err := runner.Start(&Scenario{
+		Name: "incident",
+		Steps: []Step{
+			{
+				Name:     "products-errors",
+				At:       utils.Duration(10 * time.Millisecond),
+				For:      utils.Duration(40 * time.Millisecond),
+				Profiles: Profiles{"GET /api/products": {ErrorRate: 0.5}},
+			},
+			{
+				Name:     "order-errors",
+				At:       utils.Duration(20 * time.Millisecond),
+				For:      utils.Duration(10 * time.Millisecond),
+				Profiles: Profiles{"POST /api/orders": {ErrorRate: 0.3}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("シナリオが時間内に完了しなかった")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	expected := []struct {
+		step     string
+		recovery bool
+	}{
+		{"products-errors", false},
+		{"order-errors", false},
+		{"order-errors", true},
+		{"products-errors", true},
+	}
+	for i, want := range expected {
+		if transitions[i].Step != want.step || transitions[i].Recovery != want.recovery {
+			t.Errorf("transition[%d] = %s (recovery=%t), want %s (recovery=%t)",
+				i, transitions[i].Step, transitions[i].Recovery, want.step, want.recovery)
+		}
+	}
+
+	// 変更前のプロファイルに戻り、元々なかったルートは障害注入なしになる
+	current := faults.Get()
+	if current["GET /api/products"].ErrorRate != 0.1 {
+		t.Errorf("GET /api/products errorRate = %v, want 0.1", current["GET /api/products"].ErrorRate)
+	}
+	if current["POST /api/orders"].ErrorRate != 0 {
+		t.Errorf("POST /api/orders errorRate = %v, want 0", current["POST /api/orders"].ErrorRate)
+	}
+}
+
+func TestScenarioRunner_Stop(t *testing.T) {
+	faults := NewConfig(Profiles{}, utils.NewSeededRand(1))
+	runner := NewScenarioRunner(faults, nil)
+
+	if _, err := runner.Status(); err != ErrScenarioNotActive {
+		t.Errorf("Status() error = %v, want %v", err, ErrScenarioNotActive)
+	}
+
+	err := runner.Start(&Scenario{
+		Name: "incident",
+		Steps: []Step{
+			{Name: "first", At: 0, Profiles: Profiles{"POST /api/orders": {ErrorRate: 0.3}}},
+			{Name: "second", At: utils.Duration(time.Hour), Profiles: Profiles{"POST /api/orders": {ErrorRate: 0.9}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// 最初のステップが適用されるまで待つ
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status, _ := runner.Status()
+		if status.Current != nil {
+			if status.Next == nil || status.Next.Step != "second" {
+				t.Errorf("next = %+v, want second", status.Next)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("最初のステップが適用されなかった")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	runner.Stop()
+
+	status, err := runner.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Running || status.Next != nil {
+		t.Errorf("status = %+v, want stopped", status)
+	}
+	if faults.Get()["POST /api/orders"].ErrorRate != 0.3 {
+		t.Errorf("errorRate = %v, want 0.3", faults.Get()["POST /api/orders"].ErrorRate)
+	}
+}
+
+func TestScenarioRunner_ConcurrentStart(t *testing.T) {
+	faults := NewConfig(Profiles{}, utils.NewSeededRand(1))
+
+	var (
+		mutex   sync.Mutex
+		stopped bool
+		late    []Transition
+	)
+	runner := NewScenarioRunner(faults, func(transition Transition) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if stopped {
+			late = append(late, transition)
+		}
+	})
+
+	scenario := &Scenario{Name: "incident"}
+	for i := 0; i < 10; i++ {
+		scenario.Steps = append(scenario.Steps, Step{
+			Name:     fmt.Sprintf("step-%d", i),
+			At:       utils.Duration(time.Duration(i) * 10 * time.Millisecond),
+			Profiles: Profiles{"POST /api/orders": {ErrorRate: 0.3}},
+		})
+	}
+
+	// 同時に開始しても実行中のシナリオは1つだけで、Stop ですべて停止する
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := runner.Start(scenario); err != nil {
+				t.Errorf("Start() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	runner.Stop()
+	mutex.Lock()
+	stopped = true
+	mutex.Unlock()
+
+	time.Sleep(150 * time.Millisecond)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(late) > 0 {
+		t.Errorf("停止後に適用されたステップ = %d件, want 0", len(late))
+	}
+}
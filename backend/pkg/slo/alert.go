@@ -0,0 +1,293 @@
+package slo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+var ErrInvalidAlertRule = errors.New("invalid burn rate alert rule")
+
+// アラートの重要度
+const (
+	SeverityPage   = "page"
+	SeverityTicket = "ticket"
+)
+
+// アラートの状態
+const (
+	AlertInactive = "inactive" // 一度も発報していない
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// AlertRule はマルチウィンドウ・マルチバーンレートのアラート条件
+// 長いウィンドウと短いウィンドウの両方でバーンレートが閾値以上の場合に発報する
+type AlertRule struct {
+	Name        string         `json:"name" yaml:"name"`
+	Objective   string         `json:"objective" yaml:"objective"`
+	Severity    string         `json:"severity" yaml:"severity"`
+	BurnRate    float64        `json:"burnRate" yaml:"burnRate"`
+	LongWindow  utils.Duration `json:"longWindow" yaml:"longWindow"`
+	ShortWindow utils.Duration `json:"shortWindow" yaml:"shortWindow"`
+}
+
+type AlertRules []AlertRule
+
+// DefaultAlertRules は Google SRE Workbook の推奨値（30日ウィンドウ基準）を各SLOに適用したルールを返す
+// SLOのウィンドウより長いウィンドウはSLOのウィンドウに切り詰める
+func DefaultAlertRules(objectives Objectives) AlertRules {
+	recommended := []struct {
+		suffix      string
+		severity    string
+		burnRate    float64
+		longWindow  time.Duration
+		shortWindow time.Duration
+	}{
+		{"fast-burn", SeverityPage, 14.4, time.Hour, 5 * time.Minute},      // 1時間でバジェットの2%を消費
+		{"slow-burn", SeverityPage, 6, 6 * time.Hour, 30 * time.Minute},    // 6時間でバジェットの5%を消費
+		{"budget-drain", SeverityTicket, 1, 72 * time.Hour, 6 * time.Hour}, // 3日でバジェットの10%を消費
+	}
+
+	rules := AlertRules{}
+	for _, objective := range objectives.withDefaults() {
+		window := time.Duration(objective.Window)
+		for _, r := range recommended {
+			rules = append(rules, AlertRule{
+				Name:        objective.Name + "-" + r.suffix,
+				Objective:   objective.Name,
+				Severity:    r.severity,
+				BurnRate:    r.burnRate,
+				LongWindow:  utils.Duration(minDuration(r.longWindow, window)),
+				ShortWindow: utils.Duration(minDuration(r.shortWindow, window)),
+			})
+		}
+	}
+	return rules
+}
+
+// LoadAlertRules はJSONまたはYAMLファイルからアラートルールを読み込む
+func LoadAlertRules(path string) (AlertRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+
+	rules := AlertRules{}
+	if err := utils.DecodeFile(path, data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse alert rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Validate はルールの値と、参照するSLOが存在しウィンドウがSLOのウィンドウ以内であることを検証する
+func (rules AlertRules) Validate(objectives Objectives) error {
+	windows := map[string]time.Duration{}
+	for _, objective := range objectives.withDefaults() {
+		windows[objective.Name] = time.Duration(objective.Window)
+	}
+
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		window, exists := windows[rule.Objective]
+		if !exists || rule.Name == "" || names[rule.Name] || rule.BurnRate <= 0 {
+			return ErrInvalidAlertRule
+		}
+		if rule.Severity != SeverityPage && rule.Severity != SeverityTicket {
+			return ErrInvalidAlertRule
+		}
+		if rule.ShortWindow <= 0 || rule.LongWindow < rule.ShortWindow || time.Duration(rule.LongWindow) > window {
+			return ErrInvalidAlertRule
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
+
+// Alert はアラートルール1件の現在の状態
+type Alert struct {
+	Rule          AlertRule  `json:"rule"`
+	State         string     `json:"state"`
+	LongBurnRate  float64    `json:"longBurnRate"`
+	ShortBurnRate float64    `json:"shortBurnRate"`
+	FiredAt       *time.Time `json:"firedAt,omitempty"`
+	ResolvedAt    *time.Time `json:"resolvedAt,omitempty"`
+	EvaluatedAt   time.Time  `json:"evaluatedAt"`
+}
+
+// AlertSink はアラートの発報・解消の通知先
+type AlertSink interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// AlertSinkFunc は関数を AlertSink として使うためのアダプター
+type AlertSinkFunc func(ctx context.Context, alert Alert) error
+
+func (f AlertSinkFunc) Notify(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// Alerter はアラートルールを定期的に評価し、状態が変わったときに通知する
+type Alerter struct {
+	evaluator  *Evaluator
+	objectives map[string]Objective
+	rules      AlertRules
+	sinks      []AlertSink
+	alerts     map[string]*Alert
+	now        func() time.Time
+
+	stop  chan struct{}
+	done  chan struct{}
+	mutex sync.RWMutex
+
+	// evaluating は Evaluate を直列化する（古い集計結果で新しい評価結果を上書きしないようにする）
+	evaluating sync.Mutex
+}
+
+func NewAlerter(evaluator *Evaluator, rules AlertRules, sinks ...AlertSink) *Alerter {
+	objectives := map[string]Objective{}
+	for _, objective := range evaluator.Objectives() {
+		objectives[objective.Name] = objective
+	}
+
+	alerts := make(map[string]*Alert, len(rules))
+	for _, rule := range rules {
+		alerts[rule.Name] = &Alert{Rule: rule, State: AlertInactive}
+	}
+
+	return &Alerter{
+		evaluator:  evaluator,
+		objectives: objectives,
+		rules:      rules,
+		sinks:      sinks,
+		alerts:     alerts,
+		now:        time.Now,
+	}
+}
+
+// Start は interval ごとの評価を開始する
+func (a *Alerter) Start(interval time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stop != nil {
+		return
+	}
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+
+	go a.run(interval, a.stop, a.done)
+}
+
+// Stop は定期評価を停止する
+func (a *Alerter) Stop() {
+	a.mutex.Lock()
+	stop, done := a.stop, a.done
+	a.stop, a.done = nil, nil
+	a.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (a *Alerter) run(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			a.Evaluate(context.Background())
+		}
+	}
+}
+
+// Evaluate は全ルールを評価し、発報・解消したアラートを通知先へ送る
+func (a *Alerter) Evaluate(ctx context.Context) {
+	a.evaluating.Lock()
+	defer a.evaluating.Unlock()
+
+	now := a.now()
+	changed := []Alert{}
+
+	// バーンレートの集計はロックの外で行う（評価中も Alerts で現在の状態を返せるようにする）
+	type burnRates struct{ long, short float64 }
+	rates := make([]burnRates, len(a.rules))
+	for i, rule := range a.rules {
+		objective := a.objectives[rule.Objective]
+		rates[i] = burnRates{
+			long:  a.evaluator.BurnRate(objective, time.Duration(rule.LongWindow)),
+			short: a.evaluator.BurnRate(objective, time.Duration(rule.ShortWindow)),
+		}
+	}
+
+	a.mutex.Lock()
+	for i, rule := range a.rules {
+		alert := a.alerts[rule.Name]
+
+		alert.LongBurnRate = rates[i].long
+		alert.ShortBurnRate = rates[i].short
+		alert.EvaluatedAt = now
+
+		firing := alert.LongBurnRate >= rule.BurnRate && alert.ShortBurnRate >= rule.BurnRate
+		switch {
+		case firing && alert.State != AlertFiring:
+			firedAt := now
+			alert.State = AlertFiring
+			alert.FiredAt = &firedAt
+			alert.ResolvedAt = nil
+		case !firing && alert.State == AlertFiring:
+			resolvedAt := now
+			alert.State = AlertResolved
+			alert.ResolvedAt = &resolvedAt
+		default:
+			continue
+		}
+		changed = append(changed, *alert)
+	}
+	a.mutex.Unlock()
+
+	for _, alert := range changed {
+		log.Printf("SLO alert %s: %s (objective=%s, burnRate long=%.2f short=%.2f, threshold=%.2f)",
+			alert.State, alert.Rule.Name, alert.Rule.Objective, alert.LongBurnRate, alert.ShortBurnRate, alert.Rule.BurnRate)
+
+		for _, sink := range a.sinks {
+			if err := sink.Notify(ctx, alert); err != nil {
+				log.Printf("Failed to notify SLO alert %s: %v", alert.Rule.Name, err)
+			}
+		}
+	}
+}
+
+// Alerts はルールの定義順に現在のアラート状態を返す
+func (a *Alerter) Alerts() []Alert {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	alerts := make([]Alert, 0, len(a.rules))
+	for _, rule := range a.rules {
+		alerts = append(alerts, *a.alerts[rule.Name])
+	}
+	return alerts
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,196 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+func TestDefaultAlertRules(t *testing.T) {
+	objectives := Objectives{
+		{Name: "short", SLI: SLIAvailability, Target: 0.99, Window: utils.Duration(24 * time.Hour)},
+	}
+
+	rules := DefaultAlertRules(objectives)
+	if len(rules) != 3 {
+		t.Fatalf("ルール数 = %v, want 3", len(rules))
+	}
+	if rules[0].Name != "short-fast-burn" || rules[0].BurnRate != 14.4 || rules[0].Severity != SeverityPage {
+		t.Errorf("rules[0] = %+v", rules[0])
+	}
+	// 3日のウィンドウはSLOのウィンドウ（1日）に切り詰められる
+	if time.Duration(rules[2].LongWindow) != 24*time.Hour {
+		t.Errorf("longWindow = %v, want 24h", rules[2].LongWindow)
+	}
+	if err := rules.Validate(objectives); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestAlertRules_Validate(t *testing.T) {
+	objectives := Objectives{
+		{Name: "availability", SLI: SLIAvailability, Target: 0.99, Window: utils.Duration(24 * time.Hour)},
+	}
+	valid := AlertRule{
+		Name:        "fast",
+		Objective:   "availability",
+		Severity:    SeverityPage,
+		BurnRate:    14.4,
+		LongWindow:  utils.Duration(time.Hour),
+		ShortWindow: utils.Duration(5 * time.Minute),
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(rule *AlertRule)
+		wantErr bool
+	}{
+		{name: "正常なルール", modify: func(rule *AlertRule) {}},
+		{name: "存在しないSLO", modify: func(rule *AlertRule) { rule.Objective = "latency" }, wantErr: true},
+		{name: "不明な重要度", modify: func(rule *AlertRule) { rule.Severity = "critical" }, wantErr: true},
+		{name: "バーンレートが0", modify: func(rule *AlertRule) { rule.BurnRate = 0 }, wantErr: true},
+		{name: "短いウィンドウの方が長い", modify: func(rule *AlertRule) { rule.ShortWindow = utils.Duration(2 * time.Hour) }, wantErr: true},
+		{name: "SLOのウィンドウを超える", modify: func(rule *AlertRule) { rule.LongWindow = utils.Duration(48 * time.Hour) }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := valid
+			tt.modify(&rule)
+			err := AlertRules{rule}.Validate(objectives)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAlerter_Evaluate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	objectives := Objectives{
+		{Name: "availability", SLI: SLIAvailability, Target: 0.9, Window: utils.Duration(24 * time.Hour)},
+	}
+	rules := AlertRules{{
+		Name:        "fast-burn",
+		Objective:   "availability",
+		Severity:    SeverityPage,
+		BurnRate:    5,
+		LongWindow:  utils.Duration(time.Hour),
+		ShortWindow: utils.Duration(5 * time.Minute),
+	}}
+
+	recorder := newTestRecorder(objectives, &now)
+	notified := []Alert{}
+	alerter := NewAlerter(NewEvaluator(objectives, recorder), rules, AlertSinkFunc(func(ctx context.Context, alert Alert) error {
+		notified = append(notified, alert)
+		return nil
+	}))
+	alerter.now = func() time.Time { return now }
+
+	record := func(ok, failed int) {
+		for i := 0; i < ok; i++ {
+			recorder.Record("GET /api/products", 200, 0)
+		}
+		for i := 0; i < failed; i++ {
+			recorder.Record("GET /api/products", 500, 0)
+		}
+	}
+
+	// 30分前の障害
+	record(0, 10)
+	now = now.Add(30 * time.Minute)
+
+	steps := []struct {
+		name          string
+		advance       time.Duration
+		ok, failed    int
+		expectedState string
+		notifications int
+	}{
+		{
+			// 長いウィンドウのみ閾値超過（過去の障害）では発報しない
+			name:          "短いウィンドウが閾値未満",
+			expectedState: AlertInactive,
+		},
+		{
+			name:          "両方のウィンドウで閾値超過",
+			ok:            0,
+			failed:        10,
+			expectedState: AlertFiring,
+			notifications: 1,
+		},
+		{
+			name:          "発報中は再通知しない",
+			ok:            1,
+			failed:        10,
+			expectedState: AlertFiring,
+			notifications: 1,
+		},
+		{
+			name:          "短いウィンドウが回復して解消",
+			advance:       10 * time.Minute,
+			ok:            100,
+			expectedState: AlertResolved,
+			notifications: 2,
+		},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		record(step.ok, step.failed)
+
+		alerter.Evaluate(context.Background())
+		alert := alerter.Alerts()[0]
+		if alert.State != step.expectedState {
+			t.Errorf("%s: state = %v, want %v (long=%.2f short=%.2f)",
+				step.name, alert.State, step.expectedState, alert.LongBurnRate, alert.ShortBurnRate)
+		}
+		if len(notified) != step.notifications {
+			t.Errorf("%s: 通知数 = %v, want %v", step.name, len(notified), step.notifications)
+		}
+	}
+
+	if notified[0].State != AlertFiring || notified[0].FiredAt == nil {
+		t.Errorf("notified[0] = %+v, want firing", notified[0])
+	}
+	if notified[1].State != AlertResolved || notified[1].ResolvedAt == nil {
+		t.Errorf("notified[1] = %+v, want resolved", notified[1])
+	}
+}
+
+func TestWebhookSink_Notify(t *testing.T) {
+	var received WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %v, want application/json", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("JSONパースエラー: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	alert := Alert{Rule: AlertRule{Name: "fast-burn", Objective: "availability"}, State: AlertFiring, LongBurnRate: 20}
+	if err := NewWebhookSink(server.URL).Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if received.Status != AlertFiring || received.Alert.Rule.Name != "fast-burn" || received.Alert.LongBurnRate != 20 {
+		t.Errorf("received = %+v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	if err := NewWebhookSink(failing.URL).Notify(context.Background(), alert); err == nil {
+		t.Error("エラーが期待されましたが、エラーが発生しませんでした")
+	}
+}
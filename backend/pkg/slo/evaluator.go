@@ -0,0 +1,141 @@
+package slo
+
+import (
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+// Status はSLO1件の評価結果
+type Status struct {
+	Name        string         `json:"name"`
+	SLI         string         `json:"sli"`
+	Target      float64        `json:"target"`
+	Window      utils.Duration `json:"window"`
+	ThresholdMs int            `json:"thresholdMs,omitempty"`
+
+	// ウィンドウ全体の集計
+	Total      int64   `json:"total"`
+	Good       int64   `json:"good"`
+	Bad        int64   `json:"bad"`
+	Attainment float64 `json:"attainment"`
+
+	// エラーバジェット（許容される Bad の数と、その残りの割合。使い切ると負になる）
+	ErrorBudget          float64 `json:"errorBudget"`
+	ErrorBudgetRemaining float64 `json:"errorBudgetRemaining"`
+
+	// 直近 burnRateWindow のエラーバジェット消費速度（1.0 でウィンドウ終了時にちょうど使い切る）
+	BurnRate       float64        `json:"burnRate"`
+	BurnRateWindow utils.Duration `json:"burnRateWindow"`
+
+	Routes []RouteStatus `json:"routes"`
+}
+
+// RouteStatus はルート単位のSLI
+type RouteStatus struct {
+	Route      string  `json:"route"`
+	Total      int64   `json:"total"`
+	Good       int64   `json:"good"`
+	Attainment float64 `json:"attainment"`
+}
+
+// Evaluator は Recorder の記録からSLOの達成状況を計算する
+type Evaluator struct {
+	objectives Objectives
+	recorder   *Recorder
+}
+
+func NewEvaluator(objectives Objectives, recorder *Recorder) *Evaluator {
+	return &Evaluator{
+		objectives: objectives.withDefaults(),
+		recorder:   recorder,
+	}
+}
+
+func (e *Evaluator) Objectives() Objectives {
+	return append(Objectives(nil), e.objectives...)
+}
+
+// Evaluate は全SLOの現在の達成状況を返す
+func (e *Evaluator) Evaluate() []Status {
+	statuses := make([]Status, 0, len(e.objectives))
+	for _, objective := range e.objectives {
+		statuses = append(statuses, e.evaluate(objective))
+	}
+	return statuses
+}
+
+func (e *Evaluator) evaluate(objective Objective) Status {
+	window := time.Duration(objective.Window)
+	total, good := e.sli(objective, objective.Routes, window)
+	bad := total - good
+
+	budget := float64(total) * (1 - objective.Target)
+	remaining := 1.0
+	if budget > 0 {
+		remaining = 1 - float64(bad)/budget
+	}
+
+	status := Status{
+		Name:                 objective.Name,
+		SLI:                  objective.SLI,
+		Target:               objective.Target,
+		Window:               objective.Window,
+		ThresholdMs:          objective.ThresholdMs,
+		Total:                total,
+		Good:                 good,
+		Bad:                  bad,
+		Attainment:           attainment(total, good),
+		ErrorBudget:          budget,
+		ErrorBudgetRemaining: remaining,
+		BurnRate:             e.BurnRate(objective, time.Duration(objective.BurnRateWindow)),
+		BurnRateWindow:       objective.BurnRateWindow,
+		Routes:               []RouteStatus{},
+	}
+
+	routes := objective.Routes
+	if len(routes) == 0 {
+		routes = e.recorder.Routes()
+	}
+	for _, route := range routes {
+		routeTotal, routeGood := e.sli(objective, []string{route}, window)
+		status.Routes = append(status.Routes, RouteStatus{
+			Route:      route,
+			Total:      routeTotal,
+			Good:       routeGood,
+			Attainment: attainment(routeTotal, routeGood),
+		})
+	}
+
+	return status
+}
+
+// BurnRate は直近 window のエラー率をエラーバジェットの割合（1 - target）で割った値を返す
+func (e *Evaluator) BurnRate(objective Objective, window time.Duration) float64 {
+	total, good := e.sli(objective, objective.Routes, window)
+	if total == 0 {
+		return 0
+	}
+	errorRate := float64(total-good) / float64(total)
+	return errorRate / (1 - objective.Target)
+}
+
+// sli は対象リクエスト数と、そのうちSLIを満たしたリクエスト数を返す
+func (e *Evaluator) sli(objective Objective, routes []string, window time.Duration) (total, good int64) {
+	counts := e.recorder.Count(routes, window, objective.threshold())
+
+	switch objective.SLI {
+	case SLILatency:
+		return counts.Total, counts.Fast
+	default:
+		return counts.Total, counts.Total - counts.Errors
+	}
+}
+
+// リクエストがない場合は達成率100%とみなす
+func attainment(total, good int64) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(good) / float64(total)
+}
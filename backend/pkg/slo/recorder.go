@@ -0,0 +1,175 @@
+package slo
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 集計バケットの幅
+const bucketResolution = 10 * time.Second
+
+// Counts はウィンドウ内のリクエスト数
+type Counts struct {
+	Total  int64 `json:"total"`
+	Errors int64 `json:"errors"` // 5xx
+	Fast   int64 `json:"fast"`   // 閾値以内
+}
+
+type bucket struct {
+	index  int64 // 時刻（bucketResolution 単位）
+	total  int64
+	errors int64
+	fast   []int64 // thresholds と同じ順序
+}
+
+// series はルートのバケットを時刻順に保持する（ウィンドウの開始位置は二分探索で求め、ウィンドウ内のバケットのみを集計する）
+type series struct {
+	buckets []*bucket
+}
+
+// search は index 以降の最初のバケットの位置を返す
+func (s *series) search(index int64) int {
+	return sort.Search(len(s.buckets), func(i int) bool {
+		return s.buckets[i].index >= index
+	})
+}
+
+// bucketAt は index のバケットを返す（存在しない場合は時刻順の位置に追加する）
+func (s *series) bucketAt(index int64, thresholds int) *bucket {
+	i := len(s.buckets)
+	if i > 0 && s.buckets[i-1].index >= index {
+		i = s.search(index)
+		if s.buckets[i].index == index {
+			return s.buckets[i]
+		}
+	}
+
+	b := &bucket{index: index, fast: make([]int64, thresholds)}
+	s.buckets = append(s.buckets, nil)
+	copy(s.buckets[i+1:], s.buckets[i:])
+	s.buckets[i] = b
+	return b
+}
+
+// Recorder はルート単位のリクエスト結果を時間バケットごとに記録する（並行アクセス安全）
+type Recorder struct {
+	retention  time.Duration
+	thresholds []time.Duration
+	routes     map[string]*series
+	now        func() time.Time
+	mutex      sync.RWMutex
+}
+
+// NewRecorder はSLOの評価に必要な期間・レイテンシ閾値を記録するRecorderを作成する
+func NewRecorder(objectives Objectives) *Recorder {
+	objectives = objectives.withDefaults()
+
+	var retention time.Duration
+	seen := map[time.Duration]bool{}
+	thresholds := []time.Duration{}
+	for _, objective := range objectives {
+		if window := time.Duration(objective.Window); window > retention {
+			retention = window
+		}
+		if objective.SLI == SLILatency && !seen[objective.threshold()] {
+			seen[objective.threshold()] = true
+			thresholds = append(thresholds, objective.threshold())
+		}
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+
+	return &Recorder{
+		retention:  retention,
+		thresholds: thresholds,
+		routes:     make(map[string]*series),
+		now:        time.Now,
+	}
+}
+
+// Record はリクエスト1件の結果を記録する
+func (r *Recorder) Record(route string, statusCode int, latency time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := r.now().UnixNano() / int64(bucketResolution)
+
+	s, exists := r.routes[route]
+	if !exists {
+		s = &series{}
+		r.routes[route] = s
+	}
+	if n := len(s.buckets); n == 0 || index > s.buckets[n-1].index {
+		r.prune(s, index)
+	}
+
+	b := s.bucketAt(index, len(r.thresholds))
+
+	b.total++
+	if statusCode >= 500 {
+		b.errors++
+	}
+	for i, threshold := range r.thresholds {
+		if latency <= threshold {
+			b.fast[i]++
+		}
+	}
+}
+
+// Count は指定ルート（空の場合は全ルート）の直近 window のリクエスト数を返す
+// threshold は NewRecorder に渡したSLOのレイテンシ閾値である必要がある（それ以外は Fast が0）
+func (r *Recorder) Count(routes []string, window, threshold time.Duration) Counts {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if len(routes) == 0 {
+		routes = r.routesLocked()
+	}
+
+	thresholdIndex := -1
+	for i, t := range r.thresholds {
+		if t == threshold {
+			thresholdIndex = i
+		}
+	}
+
+	oldest := r.now().Add(-window).UnixNano() / int64(bucketResolution)
+
+	var counts Counts
+	for _, route := range routes {
+		s, exists := r.routes[route]
+		if !exists {
+			continue
+		}
+		for _, b := range s.buckets[s.search(oldest+1):] {
+			counts.Total += b.total
+			counts.Errors += b.errors
+			if thresholdIndex >= 0 {
+				counts.Fast += b.fast[thresholdIndex]
+			}
+		}
+	}
+	return counts
+}
+
+// Routes は記録されているルートの一覧を返す
+func (r *Recorder) Routes() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.routesLocked()
+}
+
+func (r *Recorder) routesLocked() []string {
+	routes := make([]string, 0, len(r.routes))
+	for route := range r.routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// prune は保持期間を過ぎたバケットを削除する
+func (r *Recorder) prune(s *series, latest int64) {
+	oldest := latest - int64(r.retention/bucketResolution)
+	s.buckets = s.buckets[s.search(oldest+1):]
+}
@@ -0,0 +1,148 @@
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+var ErrInvalidObjective = errors.New("invalid service level objective")
+
+// SLIの種類
+const (
+	SLIAvailability = "availability" // 5xx 以外のレスポンスの割合
+	SLILatency      = "latency"      // thresholdMs 以内に返したレスポンスの割合
+)
+
+const (
+	defaultWindow         = 30 * 24 * time.Hour
+	defaultBurnRateWindow = time.Hour
+)
+
+// Objective はSLO（対象ルート・目標値・ローリングウィンドウ）
+// routes を省略すると記録されている全ルートが対象になる
+type Objective struct {
+	Name           string         `json:"name" yaml:"name"`
+	SLI            string         `json:"sli" yaml:"sli"`
+	Routes         []string       `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Target         float64        `json:"target" yaml:"target"`
+	Window         utils.Duration `json:"window" yaml:"window"`
+	ThresholdMs    int            `json:"thresholdMs,omitempty" yaml:"thresholdMs,omitempty"`
+	BurnRateWindow utils.Duration `json:"burnRateWindow,omitempty" yaml:"burnRateWindow,omitempty"`
+}
+
+type Objectives []Objective
+
+// DefaultObjectives はハンズオンの主要なユーザー操作を対象にしたSLOを返す
+func DefaultObjectives() Objectives {
+	routes := []string{
+		"GET /api/products",
+		"GET /api/products/:id",
+		"GET /api/cart",
+		"POST /api/cart/items",
+		"PUT /api/cart/items/:id",
+		"POST /api/orders",
+		"GET /api/orders/:id",
+	}
+
+	return Objectives{
+		{
+			Name:   "api-availability",
+			SLI:    SLIAvailability,
+			Routes: routes,
+			Target: 0.99,
+			Window: utils.Duration(defaultWindow),
+		},
+		{
+			Name:        "api-latency",
+			SLI:         SLILatency,
+			Routes:      routes,
+			Target:      0.95,
+			Window:      utils.Duration(defaultWindow),
+			ThresholdMs: 500,
+		},
+	}
+}
+
+// LoadObjectives はJSONまたはYAMLファイルからSLOを読み込む
+func LoadObjectives(path string) (Objectives, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service level objectives: %w", err)
+	}
+
+	objectives := Objectives{}
+	if err := utils.DecodeFile(path, data, &objectives); err != nil {
+		return nil, fmt.Errorf("failed to parse service level objectives: %w", err)
+	}
+
+	if err := objectives.Validate(); err != nil {
+		return nil, err
+	}
+
+	return objectives, nil
+}
+
+func (o Objective) Validate() error {
+	if o.Name == "" || o.Target <= 0 || o.Target >= 1 || o.Window < 0 || o.BurnRateWindow < 0 {
+		return ErrInvalidObjective
+	}
+
+	switch o.SLI {
+	case SLIAvailability:
+	case SLILatency:
+		if o.ThresholdMs <= 0 {
+			return ErrInvalidObjective
+		}
+	default:
+		return ErrInvalidObjective
+	}
+
+	for _, route := range o.Routes {
+		parts := strings.SplitN(route, " ", 2)
+		if len(parts) != 2 || parts[0] == "" || !strings.HasPrefix(parts[1], "/") {
+			return ErrInvalidObjective
+		}
+	}
+	return nil
+}
+
+func (objectives Objectives) Validate() error {
+	names := make(map[string]bool, len(objectives))
+	for _, objective := range objectives {
+		if err := objective.Validate(); err != nil {
+			return err
+		}
+		if names[objective.Name] {
+			return ErrInvalidObjective
+		}
+		names[objective.Name] = true
+	}
+	return nil
+}
+
+// withDefaults は省略されたウィンドウを既定値で補う
+func (objectives Objectives) withDefaults() Objectives {
+	filled := make(Objectives, len(objectives))
+	for i, objective := range objectives {
+		if objective.Window == 0 {
+			objective.Window = utils.Duration(defaultWindow)
+		}
+		if objective.BurnRateWindow == 0 {
+			objective.BurnRateWindow = utils.Duration(defaultBurnRateWindow)
+		}
+		if objective.BurnRateWindow > objective.Window {
+			objective.BurnRateWindow = objective.Window
+		}
+		filled[i] = objective
+	}
+	return filled
+}
+
+func (o Objective) threshold() time.Duration {
+	return time.Duration(o.ThresholdMs) * time.Millisecond
+}
@@ -0,0 +1,281 @@
+package slo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
+)
+
+func TestObjectives_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		objectives Objectives
+		wantErr    bool
+	}{
+		{
+			name:       "既定のSLO",
+			objectives: DefaultObjectives(),
+		},
+		{
+			name:       "目標値が範囲外",
+			objectives: Objectives{{Name: "a", SLI: SLIAvailability, Target: 1}},
+			wantErr:    true,
+		},
+		{
+			name:       "レイテンシSLOに閾値がない",
+			objectives: Objectives{{Name: "l", SLI: SLILatency, Target: 0.9}},
+			wantErr:    true,
+		},
+		{
+			name:       "不明なSLI",
+			objectives: Objectives{{Name: "x", SLI: "throughput", Target: 0.9}},
+			wantErr:    true,
+		},
+		{
+			name:       "不正なルートキー",
+			objectives: Objectives{{Name: "a", SLI: SLIAvailability, Target: 0.9, Routes: []string{"/api/products"}}},
+			wantErr:    true,
+		},
+		{
+			name: "名前の重複",
+			objectives: Objectives{
+				{Name: "a", SLI: SLIAvailability, Target: 0.9},
+				{Name: "a", SLI: SLIAvailability, Target: 0.99},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.objectives.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadObjectives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slo.yaml")
+	data := `- name: checkout-latency
+  sli: latency
+  routes:
+    - POST /api/orders
+  target: 0.95
+  window: 7d
+  thresholdMs: 1000
+  burnRateWindow: 5m
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	objectives, err := LoadObjectives(path)
+	if err != nil {
+		t.Fatalf("LoadObjectives() error = %v", err)
+	}
+	if len(objectives) != 1 {
+		t.Fatalf("SLO数 = %v, want 1", len(objectives))
+	}
+	objective := objectives[0]
+	if time.Duration(objective.Window) != 7*24*time.Hour {
+		t.Errorf("window = %v, want 168h", objective.Window)
+	}
+	if time.Duration(objective.BurnRateWindow) != 5*time.Minute {
+		t.Errorf("burnRateWindow = %v, want 5m", objective.BurnRateWindow)
+	}
+	if objective.ThresholdMs != 1000 {
+		t.Errorf("thresholdMs = %v, want 1000", objective.ThresholdMs)
+	}
+}
+
+// 固定時刻で記録するRecorderを作成する
+func newTestRecorder(objectives Objectives, now *time.Time) *Recorder {
+	recorder := NewRecorder(objectives)
+	recorder.now = func() time.Time { return *now }
+	return recorder
+}
+
+func TestRecorder_Count(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	recorder := newTestRecorder(Objectives{
+		{Name: "latency", SLI: SLILatency, Target: 0.9, Window: utils.Duration(time.Hour), ThresholdMs: 300},
+	}, &now)
+
+	// 2時間前（保持期間外）
+	now = now.Add(-2 * time.Hour)
+	recorder.Record("GET /api/products", 500, 100*time.Millisecond)
+
+	// 30分前
+	now = now.Add(90 * time.Minute)
+	recorder.Record("GET /api/products", 200, 100*time.Millisecond)
+	recorder.Record("GET /api/products", 503, 500*time.Millisecond)
+
+	// 現在
+	now = now.Add(30 * time.Minute)
+	recorder.Record("GET /api/products", 200, 300*time.Millisecond)
+	recorder.Record("POST /api/orders", 404, 50*time.Millisecond)
+
+	tests := []struct {
+		name     string
+		routes   []string
+		window   time.Duration
+		expected Counts
+	}{
+		{
+			name:     "1時間のウィンドウ",
+			routes:   []string{"GET /api/products"},
+			window:   time.Hour,
+			expected: Counts{Total: 3, Errors: 1, Fast: 2},
+		},
+		{
+			name:     "5分のウィンドウ",
+			routes:   []string{"GET /api/products"},
+			window:   5 * time.Minute,
+			expected: Counts{Total: 1, Errors: 0, Fast: 1},
+		},
+		{
+			name:     "全ルート（4xxはエラーに含めない）",
+			window:   time.Hour,
+			expected: Counts{Total: 4, Errors: 1, Fast: 3},
+		},
+		{
+			name:     "記録のないルート",
+			routes:   []string{"GET /api/cart"},
+			window:   time.Hour,
+			expected: Counts{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := recorder.Count(tt.routes, tt.window, 300*time.Millisecond)
+			if counts != tt.expected {
+				t.Errorf("Count() = %+v, want %+v", counts, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRecorder_Buckets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	recorder := newTestRecorder(Objectives{
+		{Name: "availability", SLI: SLIAvailability, Target: 0.9, Window: utils.Duration(time.Hour)},
+	}, &now)
+	start := now
+
+	// 1分ごとに記録し、時刻が前後した記録は時刻順の位置に追加する
+	for i := 0; i < 90; i++ {
+		now = start.Add(time.Duration(i) * time.Minute)
+		recorder.Record("GET /api/products", 200, 0)
+	}
+	now = start.Add(85*time.Minute + 30*time.Second)
+	recorder.Record("GET /api/products", 500, 0)
+	now = start.Add(89 * time.Minute)
+
+	// 保持期間（1時間）を過ぎたバケットは削除する
+	buckets := recorder.routes["GET /api/products"].buckets
+	if len(buckets) != 61 {
+		t.Errorf("バケット数 = %d, want 61", len(buckets))
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i-1].index >= buckets[i].index {
+			t.Fatalf("バケットが時刻順でない: %d, %d", buckets[i-1].index, buckets[i].index)
+		}
+	}
+
+	if counts := recorder.Count(nil, 5*time.Minute, 0); counts != (Counts{Total: 6, Errors: 1}) {
+		t.Errorf("Count() = %+v, want %+v", counts, Counts{Total: 6, Errors: 1})
+	}
+}
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	objectives := Objectives{
+		{
+			Name:           "availability",
+			SLI:            SLIAvailability,
+			Routes:         []string{"GET /api/products", "POST /api/orders"},
+			Target:         0.9,
+			Window:         utils.Duration(time.Hour),
+			BurnRateWindow: utils.Duration(5 * time.Minute),
+		},
+		{
+			Name:        "latency",
+			SLI:         SLILatency,
+			Routes:      []string{"POST /api/orders"},
+			Target:      0.5,
+			Window:      utils.Duration(time.Hour),
+			ThresholdMs: 200,
+		},
+	}
+	recorder := newTestRecorder(objectives, &now)
+	evaluator := NewEvaluator(objectives, recorder)
+
+	// 30分前: 商品一覧 18件成功
+	now = now.Add(-30 * time.Minute)
+	for i := 0; i < 18; i++ {
+		recorder.Record("GET /api/products", 200, 100*time.Millisecond)
+	}
+
+	// 現在: 注文 1件成功（遅い）、1件失敗（速い）
+	now = now.Add(30 * time.Minute)
+	recorder.Record("POST /api/orders", 201, 400*time.Millisecond)
+	recorder.Record("POST /api/orders", 500, 50*time.Millisecond)
+
+	statuses := evaluator.Evaluate()
+	if len(statuses) != 2 {
+		t.Fatalf("SLO数 = %v, want 2", len(statuses))
+	}
+
+	availability := statuses[0]
+	if availability.Total != 20 || availability.Good != 19 || availability.Bad != 1 {
+		t.Errorf("total/good/bad = %d/%d/%d, want 20/19/1", availability.Total, availability.Good, availability.Bad)
+	}
+	if !almostEqual(availability.Attainment, 0.95) {
+		t.Errorf("attainment = %v, want 0.95", availability.Attainment)
+	}
+	// 許容 2件のうち 1件消費
+	if !almostEqual(availability.ErrorBudget, 2) || !almostEqual(availability.ErrorBudgetRemaining, 0.5) {
+		t.Errorf("errorBudget = %v, remaining = %v, want 2, 0.5", availability.ErrorBudget, availability.ErrorBudgetRemaining)
+	}
+	// 直近5分は 2件中1件失敗 → エラー率 0.5 / 許容 0.1
+	if !almostEqual(availability.BurnRate, 5) {
+		t.Errorf("burnRate = %v, want 5", availability.BurnRate)
+	}
+	if len(availability.Routes) != 2 || availability.Routes[1].Route != "POST /api/orders" || !almostEqual(availability.Routes[1].Attainment, 0.5) {
+		t.Errorf("routes = %+v", availability.Routes)
+	}
+
+	latency := statuses[1]
+	if latency.Total != 2 || latency.Good != 1 {
+		t.Errorf("total/good = %d/%d, want 2/1", latency.Total, latency.Good)
+	}
+	// 省略した burnRateWindow は既定値（1時間、ウィンドウ以下）
+	if time.Duration(latency.BurnRateWindow) != time.Hour {
+		t.Errorf("burnRateWindow = %v, want 1h", latency.BurnRateWindow)
+	}
+	if !almostEqual(latency.ErrorBudgetRemaining, 0) {
+		t.Errorf("errorBudgetRemaining = %v, want 0", latency.ErrorBudgetRemaining)
+	}
+}
+
+func TestEvaluator_NoTraffic(t *testing.T) {
+	objectives := DefaultObjectives()
+	evaluator := NewEvaluator(objectives, NewRecorder(objectives))
+
+	for _, status := range evaluator.Evaluate() {
+		if status.Attainment != 1 || status.ErrorBudgetRemaining != 1 || status.BurnRate != 0 {
+			t.Errorf("%s = %+v, want attainment=1 remaining=1 burnRate=0", status.Name, status)
+		}
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	const epsilon = 1e-9
+	return a-b < epsilon && b-a < epsilon
+}
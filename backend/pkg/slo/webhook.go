@@ -0,0 +1,28 @@
+package slo
+
+import (
+	"context"
+
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/webhook"
+)
+
+// WebhookSink はアラートの発報・解消をJSONでPOSTする
+type WebhookSink struct {
+	poster *webhook.Poster
+}
+
+// WebhookPayload はWebhookに送信する内容
+type WebhookPayload struct {
+	Status string `json:"status"` // firing / resolved
+	Alert  Alert  `json:"alert"`
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		poster: webhook.NewPoster(url),
+	}
+}
+
+func (s *WebhookSink) Notify(ctx context.Context, alert Alert) error {
+	return s.poster.Post(ctx, WebhookPayload{Status: alert.State, Alert: alert})
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeFile decodes YAML (.yaml/.yml) or JSON (any other extension) depending on the file extension
+func DecodeFile(path string, data []byte, v interface{}) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, v)
+	default:
+		return json.Unmarshal(data, v)
+	}
+}
@@ -1,38 +1,89 @@
 package utils
 
 import (
+	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
-// Create a local random generator with a custom seed
-var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+// Random is the source of randomness for simulated latencies, errors and payment outcomes.
+// Inject a single instance created by NewSeededRand so that a whole run can be replayed with the same seed.
+type Random interface {
+	Float64() float64
+	Intn(n int) int
+	NormFloat64() float64
+}
 
 // RandomInt returns a random integer between min and max (inclusive)
-func RandomInt(min, max int) int {
+func RandomInt(r Random, min, max int) int {
 	if max <= min {
 		return min
 	}
-	return min + rng.Intn(max-min+1)
+	return min + r.Intn(max-min+1)
 }
 
-// RandomFloat returns a random float64 between 0.0 and 1.0
-func RandomFloat() float64 {
-	return rng.Float64()
+// RandomBool returns true with the given probability (0.0-1.0)
+func RandomBool(r Random, probability float64) bool {
+	return r.Float64() < probability
 }
 
-// RandomBool returns a random boolean value
-func RandomBool() bool {
-	return rng.Intn(2) == 1
+// RandomDuration returns a random duration between min and max units (inclusive)
+func RandomDuration(r Random, min, max int, unit time.Duration) time.Duration {
+	return time.Duration(RandomInt(r, min, max)) * unit
 }
 
-// RandomSleep sleeps for a random duration between min and max milliseconds
-func RandomSleep(minMs, maxMs int) {
-	if maxMs <= minMs {
-		time.Sleep(time.Duration(minMs) * time.Millisecond)
-		return
+// NewSeededRand returns a goroutine-safe generator. A seed of 0 uses the current time.
+func NewSeededRand(seed int64) Random {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+type lockedSource struct {
+	mutex sync.Mutex
+	src   rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.src.Seed(seed)
+}
+
+// Uniform returns a value drawn uniformly from [min, max)
+func Uniform(r Random, min, max float64) float64 {
+	if max <= min {
+		return min
+	}
+	return min + r.Float64()*(max-min)
+}
+
+// LogNormal returns a value whose logarithm is normally distributed with mean mu and standard deviation sigma
+func LogNormal(r Random, mu, sigma float64) float64 {
+	return math.Exp(mu + sigma*r.NormFloat64())
+}
+
+// Pareto returns a value from a Pareto distribution with minimum scale and tail index shape
+func Pareto(r Random, scale, shape float64) float64 {
+	// 1 - Float64() は (0, 1] の範囲になるためゼロ除算が起きない
+	return scale / math.Pow(1-r.Float64(), 1/shape)
+}
 
-	sleepTime := RandomInt(minMs, maxMs)
-	time.Sleep(time.Duration(sleepTime) * time.Millisecond)
+// NormalQuantile returns the z-score of the given percentile (0 < p < 1) of the standard normal distribution
+func NormalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
 }
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Poster は任意のペイロードをJSONでWebhookにPOSTする
+type Poster struct {
+	url    string
+	client *http.Client
+}
+
+func NewPoster(url string) *Poster {
+	return &Poster{
+		url:    url,
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// Post はペイロードをJSONで送信する（2xx 以外の応答はエラー）
+func (p *Poster) Post(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// 受信側でトレースを引き継げるよう W3C Trace Context を付与する
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
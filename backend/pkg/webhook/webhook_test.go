@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPoster_Post(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		name        string
+		status      int
+		expectError bool
+	}{
+		{name: "2xxは成功", status: http.StatusNoContent},
+		{name: "5xxはエラー", status: http.StatusInternalServerError, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				received    map[string]string
+				traceparent string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("Content-Type = %v, want application/json", r.Header.Get("Content-Type"))
+				}
+				traceparent = r.Header.Get("traceparent")
+				json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewPoster(server.URL).Post(ctx, map[string]string{"status": "firing"})
+
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Post() error = %v, expectError %v", err, tt.expectError)
+			}
+			if received["status"] != "firing" {
+				t.Errorf("received = %v", received)
+			}
+			if want := "00-" + traceID.String() + "-" + spanID.String() + "-01"; traceparent != want {
+				t.Errorf("traceparent = %v, want %v", traceparent, want)
+			}
+		})
+	}
+}
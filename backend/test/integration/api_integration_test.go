@@ -6,16 +6,25 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/monitoring"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/payment"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/infrastructure/persistence/memory"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/handler"
+	"github.com/NRUG-SRE/slm-handson/backend/internal/interface/api/middleware"
 	"github.com/NRUG-SRE/slm-handson/backend/internal/usecase"
+	"github.com/NRUG-SRE/slm-handson/backend/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
 // TestSetup はE2Eテスト用のセットアップを行う
 func setupTestApplication() *gin.Engine {
+	return setupTestApplicationWithInventory(usecase.InventoryModeUnlimited)
+}
+
+// setupTestApplicationWithInventory は在庫管理モードを指定してセットアップを行う
+func setupTestApplicationWithInventory(inventoryMode string) *gin.Engine {
 	// Ginをテストモードに設定
 	gin.SetMode(gin.TestMode)
 
@@ -28,18 +37,27 @@ func setupTestApplication() *gin.Engine {
 	// setupTestProducts(productRepo)
 
 	// ユースケースの初期化
+	inventory, err := usecase.NewInventory(inventoryMode, productRepo)
+	if err != nil {
+		panic(err)
+	}
 	productUseCase := usecase.NewProductUseCase(productRepo)
-	cartUseCase := usecase.NewCartUseCase(cartRepo, productRepo)
-	orderUseCase := usecase.NewOrderUseCase(orderRepo, cartRepo, productRepo)
+	cartUseCase := usecase.NewCartUseCase(cartRepo, productRepo, inventory)
+	// 擬似決済（処理時間を長くし、テスト中に注文のステータスが変わらないようにする）
+	paymentGateway := payment.NewFakeGateway(payment.FakeConfig{SuccessRate: 0.9, LatencyMin: 2 * time.Second, LatencyMax: 9 * time.Second}, utils.NewSeededRand(1))
+	paymentRetry := usecase.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 100 * time.Millisecond}
+	paymentQueue := usecase.NewPaymentQueue(4, 100, paymentRetry, utils.NewSeededRand(1), nil)
+	orderUseCase := usecase.NewOrderUseCase(orderRepo, cartRepo, productRepo, inventory, paymentGateway, paymentQueue, usecase.NewRetryer(paymentRetry, utils.NewSeededRand(2)), nil)
+	paymentQueue.Start(orderUseCase.ProcessPayment)
 
-	// New Relicクライアント（テスト用 - 環境変数なしで初期化）
-	nrClient, _ := monitoring.NewNewRelicClient()
+	// テレメトリ（テスト用 - 記録内容をメモリに保持）
+	telemetry := monitoring.NewMemoryTelemetry()
 
 	// ハンドラーの初期化
 	healthHandler := handler.NewHealthHandler()
-	productHandler := handler.NewProductHandler(productUseCase, nrClient)
-	cartHandler := handler.NewCartHandler(cartUseCase, nrClient)
-	orderHandler := handler.NewOrderHandler(orderUseCase, nrClient)
+	productHandler := handler.NewProductHandler(productUseCase, telemetry)
+	cartHandler := handler.NewCartHandler(cartUseCase, telemetry)
+	orderHandler := handler.NewOrderHandler(orderUseCase, telemetry)
 
 	// テスト用のシンプルなルーター設定
 	return setupTestRouter(healthHandler, productHandler, cartHandler, orderHandler)
@@ -56,6 +74,9 @@ func setupTestRouter(
 
 	// 最小限のミドルウェア
 	engine.Use(gin.Recovery())
+	// テストでは X-User-ID を認証プロキシが付与したものとして扱う
+	engine.Use(middleware.TrustedUserHeaderMiddleware())
+	engine.Use(middleware.IdempotencyMiddleware(middleware.NewIdempotencyStore(time.Hour), monitoring.NewMemoryTelemetry()))
 
 	// ヘルスチェックエンドポイント
 	engine.GET("/health", healthHandler.HealthCheck)
@@ -67,18 +88,31 @@ func setupTestRouter(
 		apiV1.GET("/products", productHandler.GetProducts)
 		apiV1.GET("/products/:id", productHandler.GetProduct)
 
+		// カートIDの決定（識別情報のないリクエストは共有カート）
+		cartID := middleware.CartIDMiddleware(middleware.CartFallbackShared, monitoring.NewMemoryTelemetry())
+
 		// カート関連エンドポイント
-		apiV1.GET("/cart", cartHandler.GetCart)
-		apiV1.POST("/cart/items", cartHandler.AddToCart)
-		apiV1.PUT("/cart/items/:id", cartHandler.UpdateCartItem)
+		apiV1.GET("/cart", cartID, cartHandler.GetCart)
+		apiV1.POST("/cart/items", cartID, cartHandler.AddToCart)
+		apiV1.PUT("/cart/items/:id", cartID, cartHandler.UpdateCartItem)
+		apiV1.DELETE("/cart/items/:id", cartID, cartHandler.RemoveCartItem)
+		apiV1.DELETE("/cart", cartID, cartHandler.ClearCart)
+		apiV1.POST("/cart/merge", cartID, cartHandler.MergeCart)
+		apiV1.POST("/cart/:action", cartID, cartHandler.CartAction)
 
 		// 注文関連エンドポイント
-		apiV1.POST("/orders", orderHandler.CreateOrder)
+		apiV1.POST("/orders", cartID, orderHandler.CreateOrder)
 		apiV1.GET("/orders/:id", orderHandler.GetOrder)
 		apiV1.GET("/orders", orderHandler.GetOrders)
+		apiV1.POST("/orders/:id/cancel", orderHandler.CancelOrder)
 
 		// SLMデモ用エンドポイント
 		apiV1.GET("/v1/error", productHandler.TriggerError)
+		apiV1.PUT("/admin/products/:id/stock", productHandler.UpdateStock)
+		apiV1.PATCH("/admin/products/stock", productHandler.AdjustStock)
+		apiV1.POST("/admin/orders/:id/status", orderHandler.UpdateOrderStatus)
+		apiV1.GET("/admin/payments/dead-letters", orderHandler.GetPaymentDeadLetters)
+		apiV1.POST("/admin/payments/dead-letters/:orderId/requeue", orderHandler.RequeuePayment)
 	}
 
 	return engine
@@ -125,6 +159,7 @@ func TestE2E_CompleteUserJourney(t *testing.T) {
 
 	// 3. 特定商品の詳細取得（商品IDを動的に取得）
 	var firstProductID, secondProductID string
+	var firstProductPrice, secondProductPrice float64
 	t.Run("GetProduct", func(t *testing.T) {
 		// まず商品一覧から商品のIDを取得
 		req, _ := http.NewRequest("GET", "/api/products", nil)
@@ -136,10 +171,12 @@ func TestE2E_CompleteUserJourney(t *testing.T) {
 		data := response["data"].([]interface{})
 		firstProduct := data[0].(map[string]interface{})
 		firstProductID = firstProduct["id"].(string)
+		firstProductPrice = firstProduct["price"].(float64)
 
 		if len(data) > 1 {
 			secondProduct := data[1].(map[string]interface{})
 			secondProductID = secondProduct["id"].(string)
+			secondProductPrice = secondProduct["price"].(float64)
 		}
 
 		// 取得したIDで商品詳細を取得
@@ -270,10 +307,11 @@ func TestE2E_CompleteUserJourney(t *testing.T) {
 			t.Errorf("注文作成: アイテム数 = %v, want 2", len(items))
 		}
 
-		// 合計金額の確認（product-1: 1000円×2個 + product-2: 2000円×1個 = 4000円）
+		// 合計金額の確認（1つ目の商品×2個 + 2つ目の商品×1個）
+		expectedTotal := firstProductPrice*2 + secondProductPrice
 		totalAmount := data["totalAmount"].(float64)
-		if totalAmount != 4000 {
-			t.Errorf("注文作成: 合計金額 = %v, want 4000", totalAmount)
+		if totalAmount != expectedTotal {
+			t.Errorf("注文作成: 合計金額 = %v, want %v", totalAmount, expectedTotal)
 		}
 	})
 
@@ -303,6 +341,12 @@ func TestE2E_CompleteUserJourney(t *testing.T) {
 		if data["status"] != "pending" {
 			t.Errorf("注文詳細取得: Status = %v, want pending", data["status"])
 		}
+
+		// 決済の状況は決済ゲートウェイの応答まで pending
+		payment, ok := data["payment"].(map[string]interface{})
+		if !ok || payment["status"] != "pending" {
+			t.Errorf("注文詳細取得: payment = %v, want status pending", data["payment"])
+		}
 	})
 
 	// 9. 全注文一覧を取得
@@ -492,3 +536,575 @@ func TestE2E_ConcurrentAccess(t *testing.T) {
 		}
 	})
 }
+
+// TestE2E_PerSessionCarts はセッションごとにカートが分離されることを確認する
+func TestE2E_PerSessionCarts(t *testing.T) {
+	app := setupTestApplication()
+
+	req, _ := http.NewRequest("GET", "/api/products", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var productsResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &productsResponse)
+	products := productsResponse["data"].([]interface{})
+
+	sessions := []struct {
+		sessionID string
+		productID string
+	}{
+		{sessionID: "journey-a", productID: products[0].(map[string]interface{})["id"].(string)},
+		{sessionID: "journey-b", productID: products[1].(map[string]interface{})["id"].(string)},
+	}
+
+	// 各セッションで別々の商品をカートに追加
+	for _, session := range sessions {
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"productId": session.productID,
+			"quantity":  1,
+		})
+		req, _ := http.NewRequest("POST", "/api/cart/items", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Session-ID", session.sessionID)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("カート追加失敗 (%s): ステータスコード = %v", session.sessionID, w.Code)
+		}
+		if cartID := w.Header().Get("X-Cart-ID"); cartID != "session-"+session.sessionID {
+			t.Errorf("X-Cart-ID = %v, want session-%s", cartID, session.sessionID)
+		}
+	}
+
+	// セッションAで注文してもセッションBのカートは残る
+	req, _ = http.NewRequest("POST", "/api/orders", nil)
+	req.Header.Set("X-Session-ID", sessions[0].sessionID)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("注文作成失敗: ステータスコード = %v", w.Code)
+	}
+
+	var orderResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &orderResponse)
+	orderItems := orderResponse["data"].(map[string]interface{})["items"].([]interface{})
+	if len(orderItems) != 1 || orderItems[0].(map[string]interface{})["productId"] != sessions[0].productID {
+		t.Errorf("注文にセッションA以外の商品が含まれる: %v", orderItems)
+	}
+
+	expectedItems := map[string]int{sessions[0].sessionID: 0, sessions[1].sessionID: 1}
+	for sessionID, expected := range expectedItems {
+		req, _ := http.NewRequest("GET", "/api/cart", nil)
+		req.Header.Set("X-Session-ID", sessionID)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		var cartResponse map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &cartResponse)
+		items := cartResponse["data"].(map[string]interface{})["items"].([]interface{})
+		if len(items) != expected {
+			t.Errorf("%s のカート: アイテム数 = %v, want %v", sessionID, len(items), expected)
+		}
+	}
+}
+
+// TestE2E_CartItemManagement はカートへの一括追加・商品削除・カートのクリアをテストする
+func TestE2E_CartItemManagement(t *testing.T) {
+	app := setupTestApplication()
+
+	req, _ := http.NewRequest("GET", "/api/products", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var productsResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &productsResponse)
+	products := productsResponse["data"].([]interface{})
+	firstProductID := products[0].(map[string]interface{})["id"].(string)
+	secondProductID := products[1].(map[string]interface{})["id"].(string)
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Session-ID", "cart-management")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	var firstItemID string
+	t.Run("BatchAddToCart", func(t *testing.T) {
+		w := doRequest("POST", "/api/cart/items:batch", map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"productId": firstProductID, "quantity": 2},
+				{"productId": secondProductID, "quantity": 1},
+			},
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("一括追加失敗: ステータスコード = %v, want %v", w.Code, http.StatusOK)
+		}
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		items := response["data"].(map[string]interface{})["items"].([]interface{})
+		if len(items) != 2 {
+			t.Fatalf("一括追加: アイテム数 = %v, want 2", len(items))
+		}
+		firstItemID = items[0].(map[string]interface{})["id"].(string)
+	})
+
+	t.Run("BatchAddToCart_UnknownProduct", func(t *testing.T) {
+		w := doRequest("POST", "/api/cart/items:batch", map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"productId": firstProductID, "quantity": 1},
+				{"productId": "non-existent-product", "quantity": 1},
+			},
+		})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("存在しない商品の一括追加: ステータスコード = %v, want %v", w.Code, http.StatusNotFound)
+		}
+
+		// 一部の商品だけが追加されていないこと
+		w = doRequest("GET", "/api/cart", nil)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		item := response["data"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})
+		if item["quantity"] != float64(2) {
+			t.Errorf("失敗した一括追加後の数量 = %v, want 2", item["quantity"])
+		}
+	})
+
+	t.Run("RemoveCartItem", func(t *testing.T) {
+		w := doRequest("DELETE", "/api/cart/items/"+firstItemID, nil)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("商品削除失敗: ステータスコード = %v, want %v", w.Code, http.StatusNoContent)
+		}
+
+		w = doRequest("DELETE", "/api/cart/items/"+firstItemID, nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("削除済み商品の削除: ステータスコード = %v, want %v", w.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("ClearCart", func(t *testing.T) {
+		w := doRequest("DELETE", "/api/cart", nil)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("カートのクリア失敗: ステータスコード = %v, want %v", w.Code, http.StatusNoContent)
+		}
+
+		w = doRequest("GET", "/api/cart", nil)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if items := response["data"].(map[string]interface{})["items"].([]interface{}); len(items) != 0 {
+			t.Errorf("クリア後のアイテム数 = %v, want 0", len(items))
+		}
+	})
+
+	t.Run("ClearCart_NoCart", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/api/cart", nil)
+		req.Header.Set("X-Session-ID", "cart-management-empty")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("カートがない場合のクリア: ステータスコード = %v, want %v", w.Code, http.StatusNotFound)
+		}
+	})
+}
+
+// TestE2E_MergeCartOnLogin はログイン時に匿名セッションのカートがユーザーのカートに統合されることを確認する
+func TestE2E_MergeCartOnLogin(t *testing.T) {
+	app := setupTestApplication()
+
+	req, _ := http.NewRequest("GET", "/api/products", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var productsResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &productsResponse)
+	productID := productsResponse["data"].([]interface{})[0].(map[string]interface{})["id"].(string)
+
+	addToCart := func(header, value string, quantity int) string {
+		jsonBody, _ := json.Marshal(map[string]interface{}{"productId": productID, "quantity": quantity})
+		req, _ := http.NewRequest("POST", "/api/cart/items", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("カート追加失敗: ステータスコード = %v", w.Code)
+		}
+		return w.Header().Get("X-Cart-ID")
+	}
+
+	// ログイン前の匿名セッションとログイン後のユーザーのカートにそれぞれ追加
+	addToCart("X-Session-ID", "before-login", 2)
+	addToCart("X-User-ID", "user-42", 1)
+
+	// ログイン後も同じセッションIDを送ると、そのセッションのカートが統合元になる
+	req, _ = http.NewRequest("POST", "/api/cart/merge", nil)
+	req.Header.Set("X-User-ID", "user-42")
+	req.Header.Set("X-Session-ID", "before-login")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("カート統合失敗: ステータスコード = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var mergeResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &mergeResponse)
+	items := mergeResponse["data"].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["quantity"] != float64(3) {
+		t.Errorf("統合後のカート: %v, want 数量3の商品1件", items)
+	}
+
+	// 統合元の匿名セッションのカートは空になる
+	req, _ = http.NewRequest("GET", "/api/cart", nil)
+	req.Header.Set("X-Session-ID", "before-login")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var cartResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &cartResponse)
+	if items := cartResponse["data"].(map[string]interface{})["items"].([]interface{}); len(items) != 0 {
+		t.Errorf("統合元のカート: アイテム数 = %v, want 0", len(items))
+	}
+}
+
+// TestE2E_OutOfStock は在庫管理モードで在庫切れになるシナリオをテストする
+func TestE2E_OutOfStock(t *testing.T) {
+	app := setupTestApplicationWithInventory(usecase.InventoryModeTracked)
+
+	req, _ := http.NewRequest("GET", "/api/products", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var productsResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &productsResponse)
+	productID := productsResponse["data"].([]interface{})[0].(map[string]interface{})["id"].(string)
+
+	// 在庫を2個にする
+	jsonBody, _ := json.Marshal(map[string]interface{}{"stock": 2})
+	req, _ = http.NewRequest("PUT", "/api/admin/products/"+productID+"/stock", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("在庫設定失敗: ステータスコード = %v", w.Code)
+	}
+
+	addToCart := func(sessionID string, quantity int) int {
+		jsonBody, _ := json.Marshal(map[string]interface{}{"productId": productID, "quantity": quantity})
+		req, _ := http.NewRequest("POST", "/api/cart/items", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Session-ID", sessionID)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+	createOrder := func(sessionID string) int {
+		req, _ := http.NewRequest("POST", "/api/orders", nil)
+		req.Header.Set("X-Session-ID", sessionID)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 在庫を超える数量はカートに追加できない
+	if code := addToCart("buyer-1", 3); code != http.StatusUnprocessableEntity {
+		t.Errorf("在庫超過のカート追加: ステータスコード = %v, want %v", code, http.StatusUnprocessableEntity)
+	}
+
+	// 2人が残りの在庫をカートに入れ、先に注文した方だけが購入できる
+	if code := addToCart("buyer-1", 2); code != http.StatusOK {
+		t.Fatalf("カート追加失敗: ステータスコード = %v", code)
+	}
+	if code := addToCart("buyer-2", 2); code != http.StatusOK {
+		t.Fatalf("カート追加失敗: ステータスコード = %v", code)
+	}
+	if code := createOrder("buyer-1"); code != http.StatusCreated {
+		t.Errorf("注文作成: ステータスコード = %v, want %v", code, http.StatusCreated)
+	}
+	if code := createOrder("buyer-2"); code != http.StatusUnprocessableEntity {
+		t.Errorf("在庫切れの注文作成: ステータスコード = %v, want %v", code, http.StatusUnprocessableEntity)
+	}
+
+	// 決済待ちの注文で引き当て済みになる
+	req, _ = http.NewRequest("GET", "/api/products/"+productID, nil)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var productResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &productResponse)
+	if reserved := productResponse["data"].(map[string]interface{})["reserved"]; reserved != float64(2) {
+		t.Errorf("reserved = %v, want 2", reserved)
+	}
+}
+
+// TestE2E_AdjustStock は複数商品の在庫をまとめて増減するシナリオをテストする
+func TestE2E_AdjustStock(t *testing.T) {
+	app := setupTestApplication()
+
+	req, _ := http.NewRequest("GET", "/api/products", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var productsResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &productsResponse)
+	products := productsResponse["data"].([]interface{})
+	firstID := products[0].(map[string]interface{})["id"].(string)
+	secondID := products[1].(map[string]interface{})["id"].(string)
+	firstStock := products[0].(map[string]interface{})["stock"].(float64)
+	secondStock := products[1].(map[string]interface{})["stock"].(float64)
+
+	adjustStock := func(changes []map[string]interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(map[string]interface{}{"changes": changes})
+		req, _ := http.NewRequest("PATCH", "/api/admin/products/stock", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+	getStock := func(productID string) float64 {
+		req, _ := http.NewRequest("GET", "/api/products/"+productID, nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response["data"].(map[string]interface{})["stock"].(float64)
+	}
+
+	// 1商品でも在庫を下回る場合は何も変更しない
+	w = adjustStock([]map[string]interface{}{
+		{"productId": firstID, "delta": 5},
+		{"productId": secondID, "delta": -(secondStock + 1)},
+	})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("在庫不足の在庫調整: ステータスコード = %v, want %v", w.Code, http.StatusUnprocessableEntity)
+	}
+	if stock := getStock(firstID); stock != firstStock {
+		t.Errorf("失敗した在庫調整後の在庫 = %v, want %v", stock, firstStock)
+	}
+
+	w = adjustStock([]map[string]interface{}{
+		{"productId": firstID, "delta": 5},
+		{"productId": secondID, "delta": -3},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("在庫調整: ステータスコード = %v, want %v", w.Code, http.StatusOK)
+	}
+	if stock := getStock(firstID); stock != firstStock+5 {
+		t.Errorf("在庫 = %v, want %v", stock, firstStock+5)
+	}
+	if stock := getStock(secondID); stock != secondStock-3 {
+		t.Errorf("在庫 = %v, want %v", stock, secondStock-3)
+	}
+}
+
+// TestE2E_CancelOrder は注文のキャンセルと在庫の戻し、不正なステータス遷移をテストする
+func TestE2E_CancelOrder(t *testing.T) {
+	app := setupTestApplicationWithInventory(usecase.InventoryModeTracked)
+
+	req, _ := http.NewRequest("GET", "/api/products", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var productsResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &productsResponse)
+	productID := productsResponse["data"].([]interface{})[0].(map[string]interface{})["id"].(string)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"productId": productID, "quantity": 2})
+	req, _ = http.NewRequest("POST", "/api/cart/items", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Session-ID", "canceler")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("カート追加失敗: ステータスコード = %v", w.Code)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/orders", nil)
+	req.Header.Set("X-Session-ID", "canceler")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("注文作成失敗: ステータスコード = %v", w.Code)
+	}
+	var orderResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &orderResponse)
+	orderID := orderResponse["data"].(map[string]interface{})["id"].(string)
+
+	cancelOrder := func(orderID string) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(map[string]interface{}{"reason": "changed my mind"})
+		req, _ := http.NewRequest("POST", "/api/orders/"+orderID+"/cancel", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w = cancelOrder(orderID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("キャンセル: ステータスコード = %v, want %v", w.Code, http.StatusOK)
+	}
+	json.Unmarshal(w.Body.Bytes(), &orderResponse)
+	order := orderResponse["data"].(map[string]interface{})
+	if order["status"] != "canceled" {
+		t.Errorf("status = %v, want canceled", order["status"])
+	}
+	history := order["statusHistory"].([]interface{})
+	if len(history) != 2 {
+		t.Fatalf("statusHistory の件数 = %v, want 2", len(history))
+	}
+	if last := history[1].(map[string]interface{}); last["from"] != "pending" || last["to"] != "canceled" || last["reason"] != "changed my mind" {
+		t.Errorf("statusHistory[1] = %v", last)
+	}
+
+	// 引き当てが解放されている
+	req, _ = http.NewRequest("GET", "/api/products/"+productID, nil)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	var productResponse map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &productResponse)
+	if reserved := productResponse["data"].(map[string]interface{})["reserved"]; reserved != float64(0) {
+		t.Errorf("reserved = %v, want 0", reserved)
+	}
+
+	// キャンセル済みの注文は再度キャンセル・出荷できない
+	if w := cancelOrder(orderID); w.Code != http.StatusConflict {
+		t.Errorf("二重キャンセル: ステータスコード = %v, want %v", w.Code, http.StatusConflict)
+	}
+	jsonBody, _ = json.Marshal(map[string]interface{}{"status": "shipped"})
+	req, _ = http.NewRequest("POST", "/api/admin/orders/"+orderID+"/status", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("キャンセル済みの出荷: ステータスコード = %v, want %v", w.Code, http.StatusConflict)
+	}
+
+	if w := cancelOrder("non-existent"); w.Code != http.StatusNotFound {
+		t.Errorf("存在しない注文のキャンセル: ステータスコード = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+// TestE2E_PaymentDeadLetters は決済のデッドレターの一覧と再実行のエラーをテストする
+func TestE2E_PaymentDeadLetters(t *testing.T) {
+	app := setupTestApplication()
+
+	req, _ := http.NewRequest("GET", "/api/admin/payments/dead-letters", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("デッドレター一覧: ステータスコード = %v, want %v", w.Code, http.StatusOK)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if deadLetters, ok := response["data"].([]interface{}); !ok || len(deadLetters) != 0 {
+		t.Errorf("data = %v, want []", response["data"])
+	}
+
+	// 決済待ちの注文を作成
+	req, _ = http.NewRequest("GET", "/api/products", nil)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	productID := response["data"].([]interface{})[0].(map[string]interface{})["id"].(string)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"productId": productID, "quantity": 1})
+	req, _ = http.NewRequest("POST", "/api/cart/items", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Session-ID", "dead-letters")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	req, _ = http.NewRequest("POST", "/api/orders", nil)
+	req.Header.Set("X-Session-ID", "dead-letters")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("注文作成失敗: ステータスコード = %v", w.Code)
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	orderID := response["data"].(map[string]interface{})["id"].(string)
+
+	tests := []struct {
+		name         string
+		orderID      string
+		expectedCode int
+	}{
+		{name: "存在しない注文", orderID: "non-existent", expectedCode: http.StatusNotFound},
+		{name: "デッドレターにない注文", orderID: orderID, expectedCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/admin/payments/dead-letters/"+tt.orderID+"/requeue", nil)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("再実行: ステータスコード = %v, want %v", w.Code, tt.expectedCode)
+			}
+		})
+	}
+}
+
+// TestE2E_IdempotentOrder は Idempotency-Key を指定した注文作成の再送で注文が重複しないことをテストする
+func TestE2E_IdempotentOrder(t *testing.T) {
+	app := setupTestApplication()
+
+	req, _ := http.NewRequest("GET", "/api/products", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	productID := response["data"].([]interface{})[0].(map[string]interface{})["id"].(string)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"productId": productID, "quantity": 1})
+	req, _ = http.NewRequest("POST", "/api/cart/items", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Session-ID", "idempotent")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("カート追加失敗: ステータスコード = %v", w.Code)
+	}
+
+	createOrder := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/orders", nil)
+		req.Header.Set("X-Session-ID", "idempotent")
+		req.Header.Set("Idempotency-Key", "checkout-1")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	first := createOrder()
+	if first.Code != http.StatusCreated {
+		t.Fatalf("注文作成: ステータスコード = %v, want %v", first.Code, http.StatusCreated)
+	}
+
+	// カートは空になっているが、再送には最初の注文を返す
+	second := createOrder()
+	if second.Code != http.StatusCreated {
+		t.Fatalf("再送: ステータスコード = %v, want %v", second.Code, http.StatusCreated)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Idempotent-Replayed = %q, want true", second.Header().Get("Idempotent-Replayed"))
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("再送のボディ = %s, want %s", second.Body.String(), first.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/orders", nil)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if orders := response["data"].([]interface{}); len(orders) != 1 {
+		t.Errorf("注文数 = %v, want 1", len(orders))
+	}
+}
@@ -91,6 +91,11 @@ export async function POST(
     if (newrelicBrowserHeader) {
       forwardHeaders['X-NewRelic-Browser'] = newrelicBrowserHeader
     }
+    // 重複リクエスト防止のキーを転送（同じキーの再送にはバックエンドが最初のレスポンスを返す）
+    const idempotencyKeyHeader = request.headers.get('Idempotency-Key')
+    if (idempotencyKeyHeader) {
+      forwardHeaders['Idempotency-Key'] = idempotencyKeyHeader
+    }
     
     const response = await fetch(`${INTERNAL_API_URL}/${apiPath}`, {
       method: 'POST',
@@ -148,6 +153,11 @@ export async function PUT(
     if (newrelicBrowserHeader) {
       forwardHeaders['X-NewRelic-Browser'] = newrelicBrowserHeader
     }
+    // 重複リクエスト防止のキーを転送（同じキーの再送にはバックエンドが最初のレスポンスを返す）
+    const idempotencyKeyHeader = request.headers.get('Idempotency-Key')
+    if (idempotencyKeyHeader) {
+      forwardHeaders['Idempotency-Key'] = idempotencyKeyHeader
+    }
     
     const response = await fetch(`${INTERNAL_API_URL}/${apiPath}`, {
       method: 'PUT',
@@ -162,6 +172,70 @@ export async function PUT(
       )
     }
 
+    const data = await response.json()
+    return NextResponse.json(data)
+  } catch (error) {
+    console.error('API proxy error:', error)
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    )
+  }
+}
+
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: { proxy: string[] } }
+) {
+  const apiPath = params.proxy.join('/')
+  
+  try {
+    // フロントエンドから送られたヘッダーをバックエンドに転送
+    const forwardHeaders: Record<string, string> = {
+      'Content-Type': 'application/json',
+    }
+    
+    // Distributed Tracing と Session ID のヘッダーを転送
+    const newrelicHeader = request.headers.get('newrelic')
+    const sessionIdHeader = request.headers.get('X-Session-ID')
+    const newrelicTraceHeader = request.headers.get('X-NewRelic-Trace')
+    const newrelicBrowserHeader = request.headers.get('X-NewRelic-Browser')
+    
+    if (newrelicHeader) {
+      forwardHeaders['newrelic'] = newrelicHeader
+    }
+    if (sessionIdHeader) {
+      forwardHeaders['X-Session-ID'] = sessionIdHeader
+    }
+    if (newrelicTraceHeader) {
+      forwardHeaders['X-NewRelic-Trace'] = newrelicTraceHeader
+    }
+    if (newrelicBrowserHeader) {
+      forwardHeaders['X-NewRelic-Browser'] = newrelicBrowserHeader
+    }
+    // 重複リクエスト防止のキーを転送（同じキーの再送にはバックエンドが最初のレスポンスを返す）
+    const idempotencyKeyHeader = request.headers.get('Idempotency-Key')
+    if (idempotencyKeyHeader) {
+      forwardHeaders['Idempotency-Key'] = idempotencyKeyHeader
+    }
+    
+    const response = await fetch(`${INTERNAL_API_URL}/${apiPath}`, {
+      method: 'DELETE',
+      headers: forwardHeaders,
+    })
+
+    if (!response.ok) {
+      return NextResponse.json(
+        { error: 'API request failed' },
+        { status: response.status }
+      )
+    }
+
+    // カートのクリアなどボディのないレスポンス
+    if (response.status === 204) {
+      return new NextResponse(null, { status: 204 })
+    }
+
     const data = await response.json()
     return NextResponse.json(data)
   } catch (error) {
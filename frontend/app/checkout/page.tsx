@@ -14,6 +14,8 @@ export default function CheckoutPage() {
   const [isProcessing, setIsProcessing] = useState(false)
   const [order, setOrder] = useState<Order | null>(null)
   const [error, setError] = useState<string | null>(null)
+  // 注文確定の再試行で二重注文にならないよう、決済ページごとに同じキーを使う
+  const [idempotencyKey] = useState(() => `checkout_${Date.now()}_${Math.random().toString(36).substr(2, 9)}`)
 
   useEffect(() => {
     fetchCart()
@@ -53,7 +55,7 @@ export default function CheckoutPage() {
       setError(null)
       
       // 注文を作成（バックエンドでカートも空になる）
-      const createdOrder = await orderApi.createOrder()
+      const createdOrder = await orderApi.createOrder(idempotencyKey)
       setOrder(createdOrder)
       
       // New Relic: 購入完了を記録
@@ -58,12 +58,12 @@ async function apiRequest<T>(
   }
   
   const config: RequestInit = {
+    ...options,
     headers: {
       'Content-Type': 'application/json',
       ...distributedTracingHeaders,
       ...options.headers,
     },
-    ...options,
   }
 
   try {
@@ -131,9 +131,11 @@ export const cartApi = {
 
 export const orderApi = {
   // 注文作成（カート内容から自動で注文作成）
-  createOrder: async (): Promise<Order> => {
+  // 同じ idempotencyKey で再送した場合は最初に作成した注文を返す（二重注文の防止）
+  createOrder: async (idempotencyKey?: string): Promise<Order> => {
     return apiRequest<Order>('/orders', {
       method: 'POST',
+      headers: idempotencyKey ? { 'Idempotency-Key': idempotencyKey } : {},
     })
   },
 
@@ -33,11 +33,52 @@ export interface OrderItem {
   createdAt: string
 }
 
+export type OrderStatus =
+  | 'pending'
+  | 'paid'
+  | 'shipped'
+  | 'delivered'
+  | 'completed'
+  | 'failed'
+  | 'canceled'
+  | 'refunded'
+
+export interface StatusChange {
+  from?: OrderStatus
+  to: OrderStatus
+  reason?: string
+  at: string
+}
+
+export type PaymentStatus =
+  | 'pending'
+  | 'authorized'
+  | 'captured'
+  | 'declined'
+  | 'failed'
+  | 'voided'
+  | 'refunded'
+
+export interface OrderPayment {
+  status: PaymentStatus
+  attempts: number
+  providerReference?: string
+  declineCode?: string
+  failureReason?: 'declined' | 'gateway_unavailable' | 'gateway_error'
+  lastAttemptAt?: string
+  authorizedAt?: string
+  capturedAt?: string
+  failedAt?: string
+  refundedAt?: string
+}
+
 export interface Order {
   id: string
   items: OrderItem[]
   totalAmount: number
-  status: 'pending' | 'processing' | 'completed' | 'cancelled'
+  status: OrderStatus
+  payment: OrderPayment
+  statusHistory: StatusChange[]
   createdAt: string
   updatedAt: string
 }
\ No newline at end of file